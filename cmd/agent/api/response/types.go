@@ -24,5 +24,6 @@ type TaggerListResponse struct {
 
 // TaggerListEntity holds the tagging info about an entity
 type TaggerListEntity struct {
-	Tags map[string][]string `json:"tags"`
+	Tags        map[string][]string `json:"tags"`
+	RemovedTags map[string][]string `json:"removed_tags,omitempty"`
 }
@@ -105,6 +105,10 @@ var taggerListCommand = &cobra.Command{
 				}
 
 				fmt.Fprintln(color.Output, "]")
+
+				if removed := tagItem.RemovedTags[source]; len(removed) > 0 {
+					fmt.Fprintln(color.Output, fmt.Sprintf("Removed tags: [%s]", color.RedString(strings.Join(removed, " "))))
+				}
 			}
 
 			fmt.Fprintln(color.Output, "===")
@@ -54,6 +54,8 @@ var (
 	breakPoint             string
 	fullSketches           bool
 	saveFlare              bool
+	baselinePath           string
+	saveBaselinePath       string
 	profileMemory          bool
 	profileMemoryDir       string
 	profileMemoryFrames    string
@@ -81,6 +83,8 @@ func setupCmd(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&profileMemory, "profile-memory", "m", false, "run the memory profiler (Python checks only)")
 	cmd.Flags().BoolVar(&fullSketches, "full-sketches", false, "output sketches with bins information")
 	cmd.Flags().BoolVarP(&saveFlare, "flare", "", false, "save check results to the log dir so it may be reported in a flare")
+	cmd.Flags().StringVar(&baselinePath, "baseline", "", "compare the series, sketches, service checks and events of the check with a baseline file, and fail on differences")
+	cmd.Flags().StringVar(&saveBaselinePath, "save-baseline", "", "save the series, sketches, service checks and events of the check to a baseline file")
 	cmd.Flags().UintVarP(&discoveryTimeout, "discovery-timeout", "", 5, "max retry duration until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&discoveryRetryInterval, "discovery-retry-interval", "", 1, "duration between retries until Autodiscovery resolves the check template (in seconds)")
 	config.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
@@ -306,8 +310,20 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				fmt.Println("Multiple check instances found, running each of them")
 			}
 
+			var expectedBaseline *checkBaseline
+			if baselinePath != "" {
+				if expectedBaseline, err = loadBaseline(baselinePath); err != nil {
+					fmt.Printf("Cannot load the baseline: %v\n", err)
+					return err
+				}
+			}
+
 			var checkFileOutput bytes.Buffer
 			var instancesData []interface{}
+			var results *checkBaseline
+			if baselinePath != "" || saveBaselinePath != "" {
+				results = &checkBaseline{}
+			}
 			for _, c := range cs {
 				s := runCheck(c, agg)
 
@@ -315,7 +331,9 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				time.Sleep(time.Duration(checkDelay) * time.Millisecond)
 
 				if formatJSON {
-					aggregatorData := getMetricsData(agg)
+					// the results of the check are added to the baseline too,
+					// as flushing the aggregator discards them
+					aggregatorData := getMetricsData(agg, results)
 					var collectorData map[string]interface{}
 
 					collectorJSON, _ := status.GetCheckStatusJSON(c, s)
@@ -339,6 +357,10 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 						"inventories": collectorData["inventories"],
 					}
 					instancesData = append(instancesData, instanceData)
+				} else if results != nil {
+					collectBaseline(agg, results)
+					checkStatus, _ := status.GetCheckStatus(c, s)
+					fmt.Println(string(checkStatus))
 				} else if profileMemory {
 					// Every instance will create its own directory
 					instanceID := strings.SplitN(string(c.ID()), ":", 2)[1]
@@ -414,7 +436,7 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 
 				fmt.Println(instanceJSONString)
 				checkFileOutput.WriteString(instanceJSONString + "\n")
-			} else if singleCheckRun() {
+			} else if results == nil && singleCheckRun() {
 				if profileMemory {
 					color.Yellow("Check has run only once, to collect diff data run the check multiple times with the -t/--check-times flag.")
 				} else {
@@ -422,6 +444,10 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				}
 			}
 
+			if results != nil {
+				return reportBaseline(expectedBaseline, results)
+			}
+
 			if warnings != nil && warnings.TraceMallocEnabledWithPy2 {
 				return errors.New("tracemalloc is enabled but unavailable with python version 2")
 			}
@@ -608,10 +634,17 @@ func toDebugEpEvents(events map[string][]*message.Message) map[string][]eventPla
 	return result
 }
 
-func getMetricsData(agg *aggregator.BufferedAggregator) map[string]interface{} {
+// getMetricsData flushes the aggregator and returns its data, which is also
+// added to the baseline if one is given
+func getMetricsData(agg *aggregator.BufferedAggregator, baseline *checkBaseline) map[string]interface{} {
 	aggData := make(map[string]interface{})
 
 	series, sketches := agg.GetSeriesAndSketches(time.Now())
+	serviceChecks := agg.GetServiceChecks()
+	events := agg.GetEvents()
+	if baseline != nil {
+		baseline.add(series, sketches, serviceChecks, events)
+	}
 	if len(series) != 0 {
 		// Workaround to get the raw sequence of metrics, see:
 		// https://github.com/DataDog/datadog-agent/blob/b2d9527ec0ec0eba1a7ae64585df443c5b761610/pkg/metrics/series.go#L109-L122
@@ -625,12 +658,10 @@ func getMetricsData(agg *aggregator.BufferedAggregator) map[string]interface{} {
 		aggData["sketches"] = sketches
 	}
 
-	serviceChecks := agg.GetServiceChecks()
 	if len(serviceChecks) != 0 {
 		aggData["service_checks"] = serviceChecks
 	}
 
-	events := agg.GetEvents()
 	if len(events) != 0 {
 		aggData["events"] = events
 	}
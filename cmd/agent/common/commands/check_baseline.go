@@ -0,0 +1,327 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// checkBaseline holds the results expected from a check, they are compared with
+// the series, sketches, service checks and events produced by the check command
+type checkBaseline struct {
+	// Default relative tolerance of the series values, 0.1 allows a 10% difference
+	Tolerance     float64                `json:"tolerance,omitempty"`
+	Series        []baselineSerie        `json:"series"`
+	Sketches      []baselineSketch       `json:"sketches,omitempty"`
+	ServiceChecks []baselineServiceCheck `json:"service_checks"`
+	Events        []baselineEvent        `json:"events"`
+}
+
+// baselineSerie is an expected serie, its value isn't compared if not set
+type baselineSerie struct {
+	Name      string                `json:"metric"`
+	Type      metrics.APIMetricType `json:"type"`
+	Tags      []string              `json:"tags"`
+	Value     *float64              `json:"value,omitempty"`
+	Tolerance *float64              `json:"tolerance,omitempty"`
+}
+
+// baselineSketch is an expected distribution, its count of values isn't
+// compared if not set
+type baselineSketch struct {
+	Name  string   `json:"metric"`
+	Tags  []string `json:"tags"`
+	Count *int64   `json:"count,omitempty"`
+}
+
+type baselineServiceCheck struct {
+	Name   string                     `json:"check"`
+	Status metrics.ServiceCheckStatus `json:"status"`
+	Tags   []string                   `json:"tags"`
+}
+
+type baselineEvent struct {
+	Title     string                 `json:"msg_title"`
+	AlertType metrics.EventAlertType `json:"alert_type,omitempty"`
+	Tags      []string               `json:"tags"`
+}
+
+// loadBaseline reads a baseline previously saved with --save-baseline
+func loadBaseline(path string) (*checkBaseline, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := &checkBaseline{}
+	if err := json.Unmarshal(content, baseline); err != nil {
+		return nil, fmt.Errorf("invalid baseline %s: %v", path, err)
+	}
+	return baseline, nil
+}
+
+// saveBaseline writes the results of the check as a baseline
+func saveBaseline(path string, baseline *checkBaseline) error {
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// reportBaseline saves the results of the check to the --save-baseline file,
+// and compares them with the expected baseline if one was given. It returns
+// an error if they differ.
+func reportBaseline(expected, results *checkBaseline) error {
+	if saveBaselinePath != "" {
+		if expected != nil {
+			// keep the tolerance of the baseline the results are compared with
+			results.Tolerance = expected.Tolerance
+		}
+		if err := saveBaseline(saveBaselinePath, results); err != nil {
+			fmt.Printf("Cannot save the baseline: %v\n", err)
+			return err
+		}
+		fmt.Println("Baseline written to:", saveBaselinePath)
+	}
+
+	if expected == nil {
+		return nil
+	}
+
+	diffs := diffBaseline(expected, results)
+	if len(diffs) == 0 {
+		fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.GreenString("Results match the baseline")))
+		return nil
+	}
+
+	fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.RedString("Differences with the baseline")))
+	for _, diff := range diffs {
+		fmt.Printf("* %s\n", diff)
+	}
+	return fmt.Errorf("%d differences with the baseline %s", len(diffs), baselinePath)
+}
+
+// collectBaseline flushes the series, sketches, service checks and events of
+// the aggregator, and appends them to the baseline
+func collectBaseline(agg *aggregator.BufferedAggregator, baseline *checkBaseline) {
+	series, sketches := agg.GetSeriesAndSketches(time.Now())
+	baseline.add(series, sketches, agg.GetServiceChecks(), agg.GetEvents())
+}
+
+// add appends series, sketches, service checks and events flushed from the
+// aggregator to the baseline
+func (b *checkBaseline) add(series metrics.Series, sketches metrics.SketchSeriesList, serviceChecks metrics.ServiceChecks, events metrics.Events) {
+	for _, serie := range series {
+		s := baselineSerie{
+			Name: serie.Name,
+			Type: serie.MType,
+			Tags: sortedTags(serie.Tags),
+		}
+		if len(serie.Points) > 0 {
+			value := serie.Points[len(serie.Points)-1].Value
+			s.Value = &value
+		}
+		b.Series = append(b.Series, s)
+	}
+
+	for _, sketch := range sketches {
+		var count int64
+		for _, p := range sketch.Points {
+			if p.Sketch != nil {
+				count += p.Sketch.Basic.Cnt
+			}
+		}
+		b.Sketches = append(b.Sketches, baselineSketch{
+			Name:  sketch.Name,
+			Tags:  sortedTags(sketch.Tags),
+			Count: &count,
+		})
+	}
+
+	for _, sc := range serviceChecks {
+		b.ServiceChecks = append(b.ServiceChecks, baselineServiceCheck{
+			Name:   sc.CheckName,
+			Status: sc.Status,
+			Tags:   sortedTags(sc.Tags),
+		})
+	}
+
+	for _, e := range events {
+		b.Events = append(b.Events, baselineEvent{
+			Title:     e.Title,
+			AlertType: e.AlertType,
+			Tags:      sortedTags(e.Tags),
+		})
+	}
+}
+
+// diffBaseline compares the results of the check with the expected ones, and
+// returns the differences. Series, sketches, service checks and events are
+// matched by name and tags, regardless of the order of the tags.
+func diffBaseline(expected, actual *checkBaseline) []string {
+	var diffs []string
+
+	actualSeries := make(map[string][]baselineSerie)
+	for _, s := range actual.Series {
+		key := baselineKey(s.Name, s.Tags)
+		actualSeries[key] = append(actualSeries[key], s)
+	}
+	for _, e := range expected.Series {
+		key := baselineKey(e.Name, e.Tags)
+		candidates := actualSeries[key]
+		if len(candidates) == 0 {
+			diffs = append(diffs, fmt.Sprintf("missing serie %s", key))
+			continue
+		}
+		a := candidates[0]
+		actualSeries[key] = candidates[1:]
+
+		if a.Type != e.Type {
+			diffs = append(diffs, fmt.Sprintf("serie %s: type is %s, expected %s", key, a.Type, e.Type))
+		}
+		if e.Value == nil {
+			continue
+		}
+		tolerance := expected.Tolerance
+		if e.Tolerance != nil {
+			tolerance = *e.Tolerance
+		}
+		if a.Value == nil {
+			diffs = append(diffs, fmt.Sprintf("serie %s: no value, expected %v", key, *e.Value))
+		} else if !withinTolerance(*a.Value, *e.Value, tolerance) {
+			diffs = append(diffs, fmt.Sprintf("serie %s: value is %v, expected %v (tolerance %v%%)", key, *a.Value, *e.Value, tolerance*100))
+		}
+	}
+	unmatchedSeries := make(map[string]int)
+	for key, left := range actualSeries {
+		unmatchedSeries[key] = len(left)
+	}
+	diffs = append(diffs, unexpected("serie", unmatchedSeries)...)
+
+	actualSketches := make(map[string][]baselineSketch)
+	for _, s := range actual.Sketches {
+		key := baselineKey(s.Name, s.Tags)
+		actualSketches[key] = append(actualSketches[key], s)
+	}
+	for _, e := range expected.Sketches {
+		key := baselineKey(e.Name, e.Tags)
+		candidates := actualSketches[key]
+		if len(candidates) == 0 {
+			diffs = append(diffs, fmt.Sprintf("missing sketch %s", key))
+			continue
+		}
+		a := candidates[0]
+		actualSketches[key] = candidates[1:]
+
+		if e.Count == nil {
+			continue
+		}
+		if a.Count == nil {
+			diffs = append(diffs, fmt.Sprintf("sketch %s: no count, expected %d", key, *e.Count))
+		} else if *a.Count != *e.Count {
+			diffs = append(diffs, fmt.Sprintf("sketch %s: count is %d, expected %d", key, *a.Count, *e.Count))
+		}
+	}
+	unmatchedSketches := make(map[string]int)
+	for key, left := range actualSketches {
+		unmatchedSketches[key] = len(left)
+	}
+	diffs = append(diffs, unexpected("sketch", unmatchedSketches)...)
+
+	actualServiceChecks := make(map[string][]baselineServiceCheck)
+	for _, sc := range actual.ServiceChecks {
+		key := baselineKey(sc.Name, sc.Tags)
+		actualServiceChecks[key] = append(actualServiceChecks[key], sc)
+	}
+	for _, e := range expected.ServiceChecks {
+		key := baselineKey(e.Name, e.Tags)
+		candidates := actualServiceChecks[key]
+		if len(candidates) == 0 {
+			diffs = append(diffs, fmt.Sprintf("missing service check %s", key))
+			continue
+		}
+		a := candidates[0]
+		actualServiceChecks[key] = candidates[1:]
+
+		if a.Status != e.Status {
+			diffs = append(diffs, fmt.Sprintf("service check %s: status is %s, expected %s", key, a.Status, e.Status))
+		}
+	}
+	unmatchedServiceChecks := make(map[string]int)
+	for key, left := range actualServiceChecks {
+		unmatchedServiceChecks[key] = len(left)
+	}
+	diffs = append(diffs, unexpected("service check", unmatchedServiceChecks)...)
+
+	actualEvents := make(map[string][]baselineEvent)
+	for _, ev := range actual.Events {
+		key := baselineKey(ev.Title, ev.Tags)
+		actualEvents[key] = append(actualEvents[key], ev)
+	}
+	for _, e := range expected.Events {
+		key := baselineKey(e.Title, e.Tags)
+		candidates := actualEvents[key]
+		if len(candidates) == 0 {
+			diffs = append(diffs, fmt.Sprintf("missing event %s", key))
+			continue
+		}
+		a := candidates[0]
+		actualEvents[key] = candidates[1:]
+
+		if a.AlertType != e.AlertType {
+			diffs = append(diffs, fmt.Sprintf("event %s: alert type is %q, expected %q", key, a.AlertType, e.AlertType))
+		}
+	}
+	unmatchedEvents := make(map[string]int)
+	for key, left := range actualEvents {
+		unmatchedEvents[key] = len(left)
+	}
+	diffs = append(diffs, unexpected("event", unmatchedEvents)...)
+
+	return diffs
+}
+
+// unexpected returns a difference for every result left unmatched, sorted to
+// get a stable output
+func unexpected(kind string, unmatched map[string]int) []string {
+	var diffs []string
+	for key, count := range unmatched {
+		for i := 0; i < count; i++ {
+			diffs = append(diffs, fmt.Sprintf("unexpected %s %s", kind, key))
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+// withinTolerance returns whether value is equal to expected, up to the given
+// relative tolerance
+func withinTolerance(value, expected, tolerance float64) bool {
+	return math.Abs(value-expected) <= math.Abs(expected)*tolerance
+}
+
+func baselineKey(name string, tags []string) string {
+	return fmt.Sprintf("%s{%s}", name, strings.Join(sortedTags(tags), ","))
+}
+
+func sortedTags(tags []string) []string {
+	sorted := make([]string, len(tags))
+	copy(sorted, tags)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestDiffBaseline(t *testing.T) {
+	actual := &checkBaseline{
+		Series: []baselineSerie{
+			{Name: "redis.net.clients", Type: metrics.APIGaugeType, Tags: []string{"role:master", "port:6379"}, Value: floatPtr(104)},
+			{Name: "redis.net.commands", Type: metrics.APIRateType, Tags: []string{"port:6379"}, Value: floatPtr(12)},
+			{Name: "redis.mem.used", Type: metrics.APIGaugeType, Tags: []string{"port:6379"}, Value: floatPtr(2048)},
+		},
+		ServiceChecks: []baselineServiceCheck{
+			{Name: "redis.can_connect", Status: metrics.ServiceCheckOK, Tags: []string{"port:6379"}},
+		},
+		Events: []baselineEvent{
+			{Title: "Redis restarted", AlertType: metrics.EventAlertTypeInfo, Tags: []string{"port:6379"}},
+		},
+	}
+
+	for _, tt := range []struct {
+		desc     string
+		expected *checkBaseline
+		diffs    []string
+	}{
+		{
+			desc: "match within tolerance",
+			expected: &checkBaseline{
+				Tolerance: 0.05,
+				Series: []baselineSerie{
+					{Name: "redis.net.clients", Type: metrics.APIGaugeType, Tags: []string{"port:6379", "role:master"}, Value: floatPtr(100)},
+					{Name: "redis.net.commands", Type: metrics.APIRateType, Tags: []string{"port:6379"}},
+					{Name: "redis.mem.used", Type: metrics.APIGaugeType, Tags: []string{"port:6379"}, Value: floatPtr(1000), Tolerance: floatPtr(2)},
+				},
+				ServiceChecks: []baselineServiceCheck{
+					{Name: "redis.can_connect", Status: metrics.ServiceCheckOK, Tags: []string{"port:6379"}},
+				},
+				Events: []baselineEvent{
+					{Title: "Redis restarted", AlertType: metrics.EventAlertTypeInfo, Tags: []string{"port:6379"}},
+				},
+			},
+		},
+		{
+			desc: "differences",
+			expected: &checkBaseline{
+				Series: []baselineSerie{
+					{Name: "redis.net.clients", Type: metrics.APIGaugeType, Tags: []string{"port:6379", "role:master"}, Value: floatPtr(100)},
+					{Name: "redis.net.commands", Type: metrics.APICountType, Tags: []string{"port:6379"}},
+					{Name: "redis.keys", Type: metrics.APIGaugeType, Tags: []string{"port:6379"}},
+				},
+				ServiceChecks: []baselineServiceCheck{
+					{Name: "redis.can_connect", Status: metrics.ServiceCheckCritical, Tags: []string{"port:6379"}},
+				},
+				Events: []baselineEvent{
+					{Title: "Redis restarted", AlertType: metrics.EventAlertTypeWarning, Tags: []string{"port:6379"}},
+				},
+			},
+			diffs: []string{
+				"serie redis.net.clients{port:6379,role:master}: value is 104, expected 100 (tolerance 0%)",
+				"serie redis.net.commands{port:6379}: type is rate, expected count",
+				"missing serie redis.keys{port:6379}",
+				"unexpected serie redis.mem.used{port:6379}",
+				"service check redis.can_connect{port:6379}: status is OK, expected CRITICAL",
+				`event Redis restarted{port:6379}: alert type is "info", expected "warning"`,
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.diffs, diffBaseline(tt.expected, actual))
+		})
+	}
+}
+
+func TestDiffBaselineDuplicates(t *testing.T) {
+	serie := baselineSerie{Name: "foo", Type: metrics.APIGaugeType, Tags: []string{"a:b"}}
+
+	assert.Empty(t, diffBaseline(
+		&checkBaseline{Series: []baselineSerie{serie, serie}},
+		&checkBaseline{Series: []baselineSerie{serie, serie}},
+	))
+	assert.Equal(t, []string{"missing serie foo{a:b}"}, diffBaseline(
+		&checkBaseline{Series: []baselineSerie{serie, serie}},
+		&checkBaseline{Series: []baselineSerie{serie}},
+	))
+	assert.Equal(t, []string{"unexpected serie foo{a:b}"}, diffBaseline(
+		&checkBaseline{Series: []baselineSerie{serie}},
+		&checkBaseline{Series: []baselineSerie{serie, serie}},
+	))
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestDiffBaselineSketches(t *testing.T) {
+	actual := &checkBaseline{
+		Sketches: []baselineSketch{
+			{Name: "redis.latency", Tags: []string{"port:6379"}, Count: int64Ptr(10)},
+			{Name: "redis.size", Tags: []string{"port:6379"}, Count: int64Ptr(3)},
+		},
+	}
+
+	assert.Empty(t, diffBaseline(&checkBaseline{
+		Sketches: []baselineSketch{
+			{Name: "redis.latency", Tags: []string{"port:6379"}, Count: int64Ptr(10)},
+			{Name: "redis.size", Tags: []string{"port:6379"}},
+		},
+	}, actual))
+
+	assert.Equal(t, []string{
+		"sketch redis.latency{port:6379}: count is 10, expected 12",
+		"missing sketch redis.hits{port:6379}",
+		"unexpected sketch redis.size{port:6379}",
+	}, diffBaseline(&checkBaseline{
+		Sketches: []baselineSketch{
+			{Name: "redis.latency", Tags: []string{"port:6379"}, Count: int64Ptr(12)},
+			{Name: "redis.hits", Tags: []string{"port:6379"}},
+		},
+	}, actual))
+}
+
+func TestAddBaselineSketches(t *testing.T) {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1, 2, 3)
+
+	baseline := &checkBaseline{}
+	baseline.add(nil, metrics.SketchSeriesList{
+		{Name: "foo", Tags: []string{"b:c", "a:b"}, Points: []metrics.SketchPoint{{Sketch: sketch}, {Sketch: sketch}}},
+	}, nil, nil)
+
+	assert.Equal(t, []baselineSketch{
+		{Name: "foo", Tags: []string{"a:b", "b:c"}, Count: int64Ptr(6)},
+	}, baseline.Sketches)
+}
+
+func TestSaveLoadBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check-baseline")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "baseline.json")
+
+	baseline := &checkBaseline{
+		Tolerance: 0.1,
+		Series: []baselineSerie{
+			{Name: "foo", Type: metrics.APICountType, Tags: []string{"a:b"}, Value: floatPtr(3)},
+		},
+		ServiceChecks: []baselineServiceCheck{
+			{Name: "foo.up", Status: metrics.ServiceCheckWarning, Tags: []string{}},
+		},
+	}
+	require.NoError(t, saveBaseline(path, baseline))
+
+	loaded, err := loadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, loaded)
+	assert.Empty(t, diffBaseline(loaded, baseline))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"series": {"metric": "foo"}}`), 0644))
+	_, err = loadBaseline(path)
+	assert.Error(t, err)
+}
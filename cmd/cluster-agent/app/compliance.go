@@ -9,6 +9,7 @@ package app
 
 import (
 	"context"
+	"path/filepath"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
@@ -80,6 +81,17 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient, isLead
 		return err
 	}
 
+	var driftReporter *event.DriftReporter
+	if coreconfig.Datadog.GetBool("compliance_config.drift.enabled") {
+		snapshotInterval := coreconfig.Datadog.GetDuration("compliance_config.drift.snapshot_interval")
+		driftReporter, err = event.NewDriftReporter(reporter, filepath.Join(runPath, event.DefaultDriftStateFilename), snapshotInterval)
+		if err != nil {
+			return err
+		}
+		driftReporter.Start()
+		reporter = driftReporter
+	}
+
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
@@ -116,6 +128,11 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient, isLead
 	}
 	stopper.Add(agent)
 
+	// the last results are saved once the checks are stopped
+	if driftReporter != nil {
+		stopper.Add(driftReporter)
+	}
+
 	log.Infof("Running compliance checks every %s", checkInterval.String())
 	return nil
 }
@@ -8,6 +8,7 @@ package app
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -115,6 +116,17 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 		return err
 	}
 
+	var driftReporter *event.DriftReporter
+	if coreconfig.Datadog.GetBool("compliance_config.drift.enabled") {
+		snapshotInterval := coreconfig.Datadog.GetDuration("compliance_config.drift.snapshot_interval")
+		driftReporter, err = event.NewDriftReporter(reporter, filepath.Join(runPath, event.DefaultDriftStateFilename), snapshotInterval)
+		if err != nil {
+			return err
+		}
+		driftReporter.Start()
+		reporter = driftReporter
+	}
+
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
@@ -160,6 +172,11 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 	}
 	stopper.Add(agent)
 
+	// the last results are saved once the checks are stopped
+	if driftReporter != nil {
+		stopper.Add(driftReporter)
+	}
+
 	log.Infof("Running compliance checks every %s", checkInterval.String())
 
 	// Send the compliance 'running' metrics periodically
@@ -10,6 +10,9 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -51,7 +54,21 @@ var (
 	}
 
 	checkPoliciesArgs = struct {
-		dir string
+		dirs []string
+	}{}
+
+	testPoliciesCmd = &cobra.Command{
+		Use:   "test-policies [fixture...]",
+		Short: "Evaluate event fixtures and run the rule tests of policies, without the probe",
+		Long: `Evaluate the events of the JSON fixtures, in the format of the events sent by the agent,
+against the rules of the policies, and run the tests defined by the rules. Report which rules
+match the events, with their expanded expressions and the values of their macros, and whether
+the events would be approved or discarded by the kernel filters.`,
+		RunE: testPolicies,
+	}
+
+	testPoliciesArgs = struct {
+		dirs []string
 	}{}
 
 	dumpCmd = &cobra.Command{
@@ -77,7 +94,10 @@ func init() {
 	runtimeCmd.AddCommand(dumpCmd)
 
 	runtimeCmd.AddCommand(checkPoliciesCmd)
-	checkPoliciesCmd.Flags().StringVar(&checkPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+	checkPoliciesCmd.Flags().StringSliceVar(&checkPoliciesArgs.dirs, "policies-dir", []string{coreconfig.DefaultRuntimePoliciesDir}, "Path to policies directory, repeat to load override directories in order")
+
+	runtimeCmd.AddCommand(testPoliciesCmd)
+	testPoliciesCmd.Flags().StringSliceVar(&testPoliciesArgs.dirs, "policies-dir", []string{coreconfig.DefaultRuntimePoliciesDir}, "Path to policies directory, repeat to load override directories in order")
 
 	runtimeCmd.AddCommand(selfTestCmd)
 }
@@ -100,12 +120,17 @@ func dumpProcessCache(cmd *cobra.Command, args []string) error {
 }
 
 func checkPolicies(cmd *cobra.Command, args []string) error {
+	if len(checkPoliciesArgs.dirs) == 0 {
+		return errors.New("no policies directory")
+	}
+
 	cfg := &secconfig.Config{
-		PoliciesDir:         checkPoliciesArgs.dir,
-		EnableKernelFilters: true,
-		EnableApprovers:     true,
-		EnableDiscarders:    true,
-		PIDCacheSize:        1,
+		PoliciesDir:          checkPoliciesArgs.dirs[0],
+		PoliciesOverrideDirs: checkPoliciesArgs.dirs[1:],
+		EnableKernelFilters:  true,
+		EnableApprovers:      true,
+		EnableDiscarders:     true,
+		PIDCacheSize:         1,
 	}
 
 	// enabled all the rules
@@ -115,7 +140,7 @@ func checkPolicies(cmd *cobra.Command, args []string) error {
 	model := &model.Model{}
 	ruleSet := rules.NewRuleSet(model, model.NewEvent, opts)
 
-	if err := rules.LoadPolicies(cfg.PoliciesDir, ruleSet); err.ErrorOrNil() != nil {
+	if err := rules.LoadPoliciesFromDirs(cfg.GetPoliciesDirs(), ruleSet); err.ErrorOrNil() != nil {
 		return err
 	}
 
@@ -131,9 +156,95 @@ func checkPolicies(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	content, _ := json.MarshalIndent(struct {
+		*sprobe.Report
+		Rules []*ruleOrigin
+	}{
+		Report: report,
+		Rules:  getRuleOrigins(ruleSet),
+	}, "", "\t")
+	fmt.Printf("%s\n", string(content))
+
+	return nil
+}
+
+// ruleOrigin describes an effective rule, once the policies merged, and the
+// policies that defined it
+type ruleOrigin struct {
+	ID           string   `json:"id"`
+	Expression   string   `json:"expression"`
+	Policy       string   `json:"policy"`
+	OverriddenBy []string `json:"overridden_by,omitempty"`
+}
+
+func getRuleOrigins(ruleSet *rules.RuleSet) []*ruleOrigin {
+	policyPath := func(policy *rules.Policy) string {
+		return filepath.Join(policy.Source, policy.Name)
+	}
+
+	var origins []*ruleOrigin
+	for _, rule := range ruleSet.GetRules() {
+		origin := &ruleOrigin{
+			ID:         rule.ID,
+			Expression: rule.Expression,
+			Policy:     policyPath(rule.Definition.Policy),
+		}
+		for _, policy := range rule.Definition.OverriddenBy {
+			origin.OverriddenBy = append(origin.OverriddenBy, policyPath(policy))
+		}
+		origins = append(origins, origin)
+	}
+	sort.Slice(origins, func(i, j int) bool { return origins[i].ID < origins[j].ID })
+
+	return origins
+}
+
+func testPolicies(cmd *cobra.Command, args []string) error {
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	opts := rules.NewOptsWithParams(model.SECLConstants, sprobe.SupportedDiscarders, enabled, sprobe.AllCustomRuleIDs(), model.SECLLegacyAttributes, &securityLogger.PatternLogger{})
+	tester, err := sprobe.NewPolicyTester(testPoliciesArgs.dirs, opts)
+	if err != nil {
+		return err
+	}
+
+	report := struct {
+		Events []*sprobe.EventReport    `json:"events,omitempty"`
+		Tests  []*sprobe.RuleTestResult `json:"tests,omitempty"`
+	}{}
+
+	for _, fixture := range args {
+		data, err := ioutil.ReadFile(fixture)
+		if err != nil {
+			return err
+		}
+
+		events, err := sprobe.UnmarshalEventFixtures(data)
+		if err != nil {
+			return errors.Wrapf(err, "invalid fixture %s", fixture)
+		}
+
+		for _, event := range events {
+			report.Events = append(report.Events, tester.Evaluate(event))
+		}
+	}
+
+	report.Tests = tester.RunRuleTests()
+
 	content, _ := json.MarshalIndent(report, "", "\t")
 	fmt.Printf("%s\n", string(content))
 
+	var failed int
+	for _, result := range report.Tests {
+		if !result.Passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rule tests failed", failed, len(report.Tests))
+	}
+
 	return nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -30,9 +31,11 @@ import (
 
 var (
 	checkArgs = struct {
-		framework string
-		file      string
-		verbose   bool
+		framework    string
+		file         string
+		verbose      bool
+		reportFormat string
+		reportFile   string
 	}{}
 )
 
@@ -40,6 +43,8 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.framework, "framework", "", "", "Framework to run the checks from")
 	cmd.Flags().StringVarP(&checkArgs.file, "file", "f", "", "Compliance suite file to read rules from")
 	cmd.Flags().BoolVarP(&checkArgs.verbose, "verbose", "v", false, "Include verbose details")
+	cmd.Flags().StringVarP(&checkArgs.reportFormat, "report-format", "", "", "Format of the report of the results: junit, sarif or csv")
+	cmd.Flags().StringVarP(&checkArgs.reportFile, "report-file", "", "", "File to write the report of the results to, `-` for the standard output")
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -57,6 +62,18 @@ func CheckCmd(confPathArray []string) *cobra.Command {
 }
 
 func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
+	var reportFormat event.ReportFormat
+	if checkArgs.reportFormat != "" || checkArgs.reportFile != "" {
+		if checkArgs.reportFormat == "" || checkArgs.reportFile == "" {
+			return errors.New("both a report format and a report file are required")
+		}
+
+		var err error
+		if reportFormat, err = event.ParseReportFormat(checkArgs.reportFormat); err != nil {
+			return err
+		}
+	}
+
 	err := configureLogger()
 	if err != nil {
 		return err
@@ -115,6 +132,11 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 	options = append(options, checks.WithHostname(hostname))
 
 	reporter := &runCheckReporter{}
+	if reportFormat != "" {
+		reporter.recorder = &event.Recorder{}
+		// the events are only written to the report on the standard output
+		reporter.quiet = checkArgs.reportFile == "-"
+	}
 
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
@@ -137,6 +159,40 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		log.Errorf("Failed to run checks: %v", err)
 		return err
 	}
+
+	if reporter.recorder != nil {
+		if err := writeCheckReport(reportFormat, checkArgs.reportFile, reporter.recorder.Events()); err != nil {
+			return err
+		}
+	}
+
+	// exit with an error when a check didn't pass, so that the command can gate a pipeline
+	if reporter.failures > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d of %d checks didn't pass", reporter.failures, reporter.total)
+	}
+	return nil
+}
+
+func writeCheckReport(format event.ReportFormat, path string, events []*event.Event) error {
+	if path == "-" {
+		return event.WriteReport(os.Stdout, format, events)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := event.WriteReport(f, format, events); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("Report of %d results written to %s", len(events), path)
 	return nil
 }
 
@@ -150,7 +206,12 @@ func configureLogger() error {
 		logFormat = fmt.Sprintf("%%Date(%s) | %%LEVEL | (%%ShortFilePath:%%Line in %%FuncShort) | %%Msg%%n", logDateFormat)
 		logLevel = "trace"
 	}
-	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(os.Stdout, seelog.DebugLvl, logFormat)
+	// keep the standard output for the report
+	output := os.Stdout
+	if checkArgs.reportFile == "-" {
+		output = os.Stderr
+	}
+	logger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(output, seelog.DebugLvl, logFormat)
 	if err != nil {
 		return err
 	}
@@ -160,10 +221,26 @@ func configureLogger() error {
 }
 
 type runCheckReporter struct {
+	recorder *event.Recorder
+	quiet    bool
+	total    int
+	failures int
 }
 
-func (r *runCheckReporter) Report(event *event.Event) {
-	data, err := json.Marshal(event)
+func (r *runCheckReporter) Report(e *event.Event) {
+	r.total++
+	if e.Result == event.Failed || e.Result == event.Error {
+		r.failures++
+	}
+
+	if r.recorder != nil {
+		r.recorder.Report(e)
+	}
+	if r.quiet {
+		return
+	}
+
+	data, err := json.Marshal(e)
 	if err != nil {
 		log.Errorf("Failed to marshal rule event: %v", err)
 		return
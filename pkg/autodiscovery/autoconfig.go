@@ -7,6 +7,7 @@ package autodiscovery
 
 import (
 	"context"
+	"errors"
 	"expvar"
 	"fmt"
 	"sync"
@@ -542,6 +543,11 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 // decrypts secrets and stores the resolved config and service mapping if successful
 func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
 	config, tagsHash, err := configresolver.Resolve(tpl, svc)
+	if errors.Is(err, configresolver.ErrNoMatchingInstance) {
+		// the instances are deliberately excluded for this service, it's not a misconfiguration
+		log.Debugf("Not scheduling template %s for service %s: %v", tpl.Name, svc.GetEntity(), err)
+		return tpl, err
+	}
 	if err != nil {
 		newErr := fmt.Errorf("error resolving template %s for service %s: %v", tpl.Name, svc.GetEntity(), err)
 		errorStats.setResolveWarning(tpl.Name, newErr.Error())
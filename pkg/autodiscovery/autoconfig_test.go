@@ -399,6 +399,25 @@ func TestCheckOverride(t *testing.T) {
 	assert.Len(t, ac.resolveTemplate(tpl), 1)
 }
 
+func TestResolveTemplateExcludedInstances(t *testing.T) {
+	ctx := context.Background()
+
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	tpl := integration.Config{
+		Name:          "excluded",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("ad_include_if: team:*")},
+	}
+	ac.processNewService(ctx, &dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+	})
+
+	// the service is skipped without a resolve warning
+	assert.Len(t, ac.resolveTemplate(tpl), 0)
+	assert.NotContains(t, errorStats.getResolveWarnings(), "excluded")
+}
+
 type MockSecretDecrypt struct {
 	t         *testing.T
 	scenarios []struct {
@@ -11,26 +11,42 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	yaml "gopkg.in/yaml.v2"
 )
 
 type variableGetter func(ctx context.Context, key []byte, svc listeners.Service) ([]byte, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
 }
 
+// Instance options holding the conditions on the service tags for an instance to be scheduled
+const (
+	includeIfKey = "ad_include_if"
+	excludeIfKey = "ad_exclude_if"
+)
+
+// ErrNoMatchingInstance is returned by Resolve when the ad_include_if and ad_exclude_if
+// conditions exclude every instance of a template for a service
+var ErrNoMatchingInstance = errors.New("no instance matches the tags of the service")
+
 // SubstituteTemplateVariables replaces %%VARIABLES%% using the variableGetters passed in
 func SubstituteTemplateVariables(ctx context.Context, config *integration.Config, getters map[string]variableGetter, svc listeners.Service) error {
 	for i := 0; i < len(config.Instances); i++ {
@@ -39,7 +55,11 @@ func SubstituteTemplateVariables(ctx context.Context, config *integration.Config
 			if f, found := getters[string(v.Name)]; found {
 				resolvedVar, err := f(ctx, v.Key, svc)
 				if err != nil {
-					return err
+					if v.Default == nil {
+						return err
+					}
+					log.Debugf("Using the default value of %s: %s", v.Raw, err)
+					resolvedVar = v.Default
 				}
 				// init config vars are replaced by the first found
 				config.InitConfig = bytes.Replace(config.InitConfig, v.Raw, resolvedVar, -1)
@@ -58,6 +78,9 @@ func SubstituteTemplateEnvVars(config *integration.Config) error {
 		for _, v := range vars {
 			if "env" == string(v.Name) {
 				resolvedVar, err := getEnvvar(v.Key)
+				if err != nil && v.Default != nil {
+					resolvedVar, err = v.Default, nil
+				}
 				if err != nil {
 					log.Warnf("variable not replaced: %s", err)
 					if retErr == nil {
@@ -138,6 +161,10 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 		return resolvedConfig, "", fmt.Errorf("couldn't get tags for service '%s', err: %w", svc.GetEntity(), err)
 	}
 
+	if err := filterInstances(&resolvedConfig, tags); err != nil {
+		return resolvedConfig, "", fmt.Errorf("%w, skipping service %s", err, svc.GetEntity())
+	}
+
 	if !tpl.IgnoreAutodiscoveryTags {
 		if err := addServiceTags(&resolvedConfig, tags); err != nil {
 			return resolvedConfig, "", fmt.Errorf("unable to add tags for service '%s', err: %w", svc.GetEntity(), err)
@@ -147,6 +174,116 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	return resolvedConfig, tagsHash, nil
 }
 
+// filterInstances removes the instances whose ad_include_if and ad_exclude_if conditions
+// don't match the tags of the service. An instance is kept if at least one of the tag
+// patterns of ad_include_if, if set, and none of those of ad_exclude_if match a tag.
+// Patterns may contain * wildcards, e.g. kube_namespace:prod-*.
+func filterInstances(resolvedConfig *integration.Config, tags []string) error {
+	if len(resolvedConfig.Instances) == 0 {
+		return nil
+	}
+	kept := resolvedConfig.Instances[:0]
+	for _, instance := range resolvedConfig.Instances {
+		if !bytes.Contains(instance, []byte(includeIfKey)) && !bytes.Contains(instance, []byte(excludeIfKey)) {
+			kept = append(kept, instance)
+			continue
+		}
+		rawConfig := integration.RawMap{}
+		if err := yaml.Unmarshal(instance, &rawConfig); err != nil {
+			return err
+		}
+		include, err := tagPatterns(rawConfig, includeIfKey)
+		if err != nil {
+			return err
+		}
+		exclude, err := tagPatterns(rawConfig, excludeIfKey)
+		if err != nil {
+			return err
+		}
+		if (include != nil && !matchAnyTag(include, tags)) || matchAnyTag(exclude, tags) {
+			continue
+		}
+		if _, found := rawConfig[includeIfKey]; !found {
+			if _, found := rawConfig[excludeIfKey]; !found {
+				// the keys only appeared in values, keep the instance as is
+				kept = append(kept, instance)
+				continue
+			}
+		}
+		out, err := removeInstanceKeys(instance, includeIfKey, excludeIfKey)
+		if err != nil {
+			return err
+		}
+		kept = append(kept, out)
+	}
+	if len(kept) == 0 {
+		return fmt.Errorf("%w for %s", ErrNoMatchingInstance, resolvedConfig.Name)
+	}
+	resolvedConfig.Instances = kept
+	return nil
+}
+
+// removeInstanceKeys returns the instance without the given top-level keys, preserving the
+// order of the other keys.
+func removeInstanceKeys(instance integration.Data, keys ...string) (integration.Data, error) {
+	var items yaml.MapSlice
+	if err := yaml.Unmarshal(instance, &items); err != nil {
+		return nil, err
+	}
+	kept := items[:0]
+ITEM:
+	for _, item := range items {
+		for _, key := range keys {
+			if item.Key == key {
+				continue ITEM
+			}
+		}
+		kept = append(kept, item)
+	}
+	out, err := yaml.Marshal(kept)
+	if err != nil {
+		return nil, err
+	}
+	return integration.Data(out), nil
+}
+
+// tagPatterns returns the tag patterns of an instance condition, which may be set as a
+// single string or a list of strings. It returns nil if the condition isn't set.
+func tagPatterns(rawConfig integration.RawMap, key string) ([]*regexp.Regexp, error) {
+	var values []interface{}
+	switch v := rawConfig[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	default:
+		return nil, fmt.Errorf("%s must be a tag pattern or a list of tag patterns", key)
+	}
+	patterns := make([]*regexp.Regexp, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a tag pattern or a list of tag patterns", key)
+		}
+		expr := strings.ReplaceAll(regexp.QuoteMeta(s), `\*`, ".*")
+		patterns = append(patterns, regexp.MustCompile("^"+expr+"$"))
+	}
+	return patterns, nil
+}
+
+func matchAnyTag(patterns []*regexp.Regexp, tags []string) bool {
+	for _, p := range patterns {
+		for _, tag := range tags {
+			if p.MatchString(tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func addServiceTags(resolvedConfig *integration.Config, tags []string) error {
 	for i := 0; i < len(resolvedConfig.Instances); i++ {
 		if err := resolvedConfig.Instances[i].MergeAdditionalTags(tags); err != nil {
@@ -215,20 +352,70 @@ func getPort(ctx context.Context, tplVar []byte, svc listeners.Service) ([]byte,
 
 	idx, err := strconv.Atoi(string(tplVar))
 	if err != nil {
-		// The template variable is not an index so try to lookup port by name.
+		// The template variable is not an index so try to lookup port by name,
+		// then by range of indexes (e.g. 1-3), then by protocol.
 		for _, port := range ports {
 			if port.Name == string(tplVar) {
 				return []byte(strconv.Itoa(port.Port)), nil
 			}
 		}
+		if low, high, ok := parseIndexRange(string(tplVar), len(ports)); ok {
+			if low > high {
+				return nil, fmt.Errorf("no port found at the indexes %s, skipping container %s", string(tplVar), svc.GetEntity())
+			}
+			return []byte(strconv.Itoa(ports[low].Port)), nil
+		}
+		for _, port := range ports {
+			if port.Protocol != "" && strings.EqualFold(port.Protocol, string(tplVar)) {
+				return []byte(strconv.Itoa(port.Port)), nil
+			}
+		}
 		return nil, fmt.Errorf("port %s not found, skipping container %s", string(tplVar), svc.GetEntity())
 	}
+	if idx < 0 {
+		// negative indexes start from the last port
+		idx += len(ports)
+		if idx < 0 {
+			return nil, fmt.Errorf("index given for the port template var is too small, skipping container %s", svc.GetEntity())
+		}
+	}
 	if len(ports) <= idx {
 		return nil, fmt.Errorf("index given for the port template var is too big, skipping container %s", svc.GetEntity())
 	}
 	return []byte(strconv.Itoa(ports[idx].Port)), nil
 }
 
+// parseIndexRange parses a range of indexes of the form 1-3 into the list of n ports, and
+// returns the bounds of the range restricted to the list, low being greater than high when
+// the range is outside of it. Negative indexes start from the last port, e.g. -2--1.
+func parseIndexRange(s string, n int) (low, high int, ok bool) {
+	sep := strings.Index(s[1:], "-") + 1
+	if sep == 0 {
+		return 0, 0, false
+	}
+	low, err := strconv.Atoi(s[:sep])
+	if err != nil {
+		return 0, 0, false
+	}
+	high, err = strconv.Atoi(s[sep+1:])
+	if err != nil {
+		return 0, 0, false
+	}
+	if low < 0 {
+		low += n
+	}
+	if high < 0 {
+		high += n
+	}
+	if low < 0 {
+		low = 0
+	}
+	if high >= n {
+		high = n - 1
+	}
+	return low, high, true
+}
+
 // getPid returns the process identifier of the service
 func getPid(ctx context.Context, _ []byte, svc listeners.Service) ([]byte, error) {
 	pid, err := svc.GetPid(ctx)
@@ -261,6 +448,24 @@ func getAdditionalTplVariables(_ context.Context, tplVar []byte, svc listeners.S
 	return value, nil
 }
 
+// getLabel returns the value of a label of the service
+func getLabel(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	value, err := svc.GetExtraConfig(append([]byte("label_"), tplVar...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get label %s for service %s, skipping config - %s", tplVar, svc.GetEntity(), err)
+	}
+	return value, nil
+}
+
+// getAnnotation returns the value of an annotation of the service
+func getAnnotation(_ context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	value, err := svc.GetExtraConfig(append([]byte("annotation_"), tplVar...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotation %s for service %s, skipping config - %s", tplVar, svc.GetEntity(), err)
+	}
+	return value, nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(envVar []byte) ([]byte, error) {
 	if len(envVar) == 0 {
@@ -7,6 +7,7 @@ package configresolver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -32,6 +33,7 @@ type dummyService struct {
 	CreationTime  integration.CreationTime
 	CheckNames    []string
 	ExtraConfig   map[string]string
+	Tags          []string
 }
 
 // GetEntity returns the service entity name
@@ -59,8 +61,11 @@ func (s *dummyService) GetPorts(context.Context) ([]listeners.ContainerPort, err
 	return s.Ports, nil
 }
 
-// GetTags returns static tags
+// GetTags returns static tags, unless tags are set
 func (s *dummyService) GetTags() ([]string, string, error) {
+	if s.Tags != nil {
+		return s.Tags, "hash", nil
+	}
 	return []string{"foo:bar"}, "hash", nil
 }
 
@@ -96,7 +101,11 @@ func (s *dummyService) HasFilter(filter containers.FilterType) bool {
 
 // GetExtraConfig returns extra configuration
 func (s *dummyService) GetExtraConfig(key []byte) ([]byte, error) {
-	return []byte(s.ExtraConfig[string(key)]), nil
+	value, found := s.ExtraConfig[string(key)]
+	if !found {
+		return nil, fmt.Errorf("extra config %q is not supported", key)
+	}
+	return []byte(value), nil
 }
 
 func TestGetFallbackHost(t *testing.T) {
@@ -619,6 +628,163 @@ func TestResolve(t *testing.T) {
 				Entity:        "a5901276aed1",
 			},
 		},
+		//// expressions
+		{
+			testName: "default values",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig:   map[string]string{"label_app": "cache"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("port: %%port|6379%%\nuser: %%env_test_envvar_not_set|guest%%\nkey: %%env_test_envvar_key|default%%\napp: %%label_app|none%%\nteam: %%label_team|none%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: cache\nkey: test_value\nport: 6379\ntags:\n- foo:bar\nteam: none\nuser: guest\n")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "labels and annotations",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig:   map[string]string{"label_app.kubernetes.io/name": "redis", "annotation_redis.io/db": "3"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("name: %%label_app.kubernetes.io/name%%\ndb: %%annotation_redis.io/db%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("db: 3\nname: redis\ntags:\n- foo:bar\n")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "missing label, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%")},
+			},
+			errorString: `failed to get label app for service a5901276aed1, skipping config - extra config "label_app" is not supported`,
+		},
+		{
+			testName: "port selection by protocol, index range and negative index",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Ports: []listeners.ContainerPort{
+					{Port: 53, Name: "dns", Protocol: "udp"},
+					{Port: 8080, Name: "http", Protocol: "tcp"},
+					{Port: 9090, Name: "metrics", Protocol: "tcp"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("tcp: %%port_tcp%%\nudp: %%port_UDP%%\nrange: %%port_1-2%%\ntail: %%port_-2--1%%\nlast: %%port_-1%%\nfirst: %%port_-3%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("first: 53\nlast: 9090\nrange: 8080\ntags:\n- foo:bar\ntail: 8080\ntcp: 8080\nudp: 53\n")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "%%port_-4%% too small, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Ports:         newFakeContainerPorts(),
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("port: %%port_-4%%")},
+			},
+			errorString: "index given for the port template var is too small, skipping container a5901276aed1",
+		},
+		{
+			testName: "%%port_5-9%% out of the port list, error",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Ports:         newFakeContainerPorts(),
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("port: %%port_5-9%%")},
+			},
+			errorString: "no port found at the indexes 5-9, skipping container a5901276aed1",
+		},
+		{
+			testName: "conditional instances",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Tags:          []string{"kube_namespace:prod-eu", "team:cache"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances: []integration.Data{
+					integration.Data("name: prod\nad_include_if: kube_namespace:prod-*\nport: 6379"),
+					integration.Data("name: staging\nad_include_if:\n- kube_namespace:staging\n- env:staging"),
+					integration.Data("name: not-cache\nad_exclude_if:\n- team:cache"),
+					integration.Data("name: all"),
+				},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances: []integration.Data{
+					integration.Data("name: prod\nport: 6379\ntags:\n- kube_namespace:prod-eu\n- team:cache\n"),
+					integration.Data("name: all\ntags:\n- kube_namespace:prod-eu\n- team:cache\n"),
+				},
+				InitConfig: integration.Data{},
+				Entity:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "conditional instances, none left",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("ad_exclude_if: [foo:*]")},
+			},
+			errorString: "no instance matches the tags of the service for redis, skipping service a5901276aed1",
+		},
+		{
+			testName: "conditional instances, invalid condition",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("ad_include_if: {foo: bar}")},
+			},
+			errorString: "ad_include_if must be a tag pattern or a list of tag patterns, skipping service a5901276aed1",
+		},
 	}
 	validTemplates := 0
 
@@ -648,3 +814,49 @@ func newFakeContainerPorts() []listeners.ContainerPort {
 		{Port: 3, Name: "baz"},
 	}
 }
+
+func TestFilterInstances(t *testing.T) {
+	config := integration.Config{
+		Name: "redis",
+		Instances: []integration.Data{
+			integration.Data("port: 6379\nname: prod\nad_include_if: kube_namespace:prod-*\ntimeout: 5\n"),
+			integration.Data("port: 6379\nname: 'ad_include_if: in a value'\n"),
+			integration.Data("port: 6379\nname: staging\nad_include_if: kube_namespace:staging\n"),
+		},
+	}
+	require.NoError(t, filterInstances(&config, []string{"kube_namespace:prod-eu"}))
+	assert.Equal(t, []integration.Data{
+		integration.Data("port: 6379\nname: prod\ntimeout: 5\n"),
+		integration.Data("port: 6379\nname: 'ad_include_if: in a value'\n"),
+	}, config.Instances)
+
+	// excluding every instance is not a resolution error
+	config.Instances = []integration.Data{integration.Data("port: 6379\nad_exclude_if: kube_namespace:prod-*\n")}
+	err := filterInstances(&config, []string{"kube_namespace:prod-eu"})
+	assert.True(t, errors.Is(err, ErrNoMatchingInstance))
+}
+
+func TestParseIndexRange(t *testing.T) {
+	for _, tt := range []struct {
+		s         string
+		low, high int
+		ok        bool
+	}{
+		{"0-1", 0, 1, true},
+		{"1-5", 1, 2, true},
+		{"-2--1", 1, 2, true},
+		{"-5-0", 0, 0, true},
+		{"4-5", 4, 2, true},
+		{"8000", 0, 0, false},
+		{"-1", 0, 0, false},
+		{"http-alt", 0, 0, false},
+		{"1-", 0, 0, false},
+	} {
+		low, high, ok := parseIndexRange(tt.s, 3)
+		assert.Equal(t, tt.ok, ok, tt.s)
+		if tt.ok {
+			assert.Equal(t, tt.low, low, tt.s)
+			assert.Equal(t, tt.high, high, tt.s)
+		}
+	}
+}
@@ -237,11 +237,13 @@ func (c *Data) MergeAdditionalTags(tags []string) error {
 	for _, t := range tagList {
 		tagSet[t] = struct{}{}
 	}
-	// override config tags
-	rawConfig["tags"] = []string{}
+	// override config tags, sorted so that the resulting config is stable
+	mergedTags := make([]string, 0, len(tagSet))
 	for k := range tagSet {
-		rawConfig["tags"] = append(rawConfig["tags"].([]string), k)
+		mergedTags = append(mergedTags, k)
 	}
+	sort.Strings(mergedTags)
+	rawConfig["tags"] = mergedTags
 	// modify original config
 	out, err := yaml.Marshal(&rawConfig)
 	if err != nil {
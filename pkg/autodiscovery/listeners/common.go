@@ -25,6 +25,9 @@ const (
 	newIdentifierLabel         = "com.datadoghq.ad.check.id"
 	legacyIdentifierLabel      = "com.datadoghq.sd.check.id"
 	dockerADTemplateCheckNames = "com.datadoghq.ad.check_names"
+	// Prefixes of the extra config keys holding labels and annotations
+	labelExtraConfigPrefix      = "label_"
+	annotationExtraConfigPrefix = "annotation_"
 	// Keys of standard tags
 	tagKeyEnv     = "env"
 	tagKeyVersion = "version"
@@ -113,6 +116,18 @@ func standardTagsDigest(labels map[string]string) string {
 	return strconv.FormatUint(h.Sum64(), 16)
 }
 
+// addLabelsToExtraConfig exposes labels and annotations as extra config values, under
+// keys prefixed with label_ and annotation_, to resolve the %%label_<name>%% and
+// %%annotation_<name>%% template variables.
+func addLabelsToExtraConfig(extraConfig, labels, annotations map[string]string) {
+	for k, v := range labels {
+		extraConfig[labelExtraConfigPrefix+k] = v
+	}
+	for k, v := range annotations {
+		extraConfig[annotationExtraConfigPrefix+k] = v
+	}
+}
+
 // isServiceAnnotated returns true if the Service has an annotation with a given key
 func isServiceAnnotated(ksvc *v1.Service, annotationKey string) bool {
 	if ksvc != nil {
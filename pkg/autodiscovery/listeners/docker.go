@@ -54,6 +54,7 @@ type DockerService struct {
 	hostname        string
 	creationTime    integration.CreationTime
 	checkNames      []string
+	labels          map[string]string
 	metricsExcluded bool
 	logsExcluded    bool
 }
@@ -181,6 +182,7 @@ func (l *DockerListener) init() {
 					cID:           co.ID,
 					adIdentifiers: l.getConfigIDFromPs(ctx, co),
 					checkNames:    checkNames,
+					labels:        co.Labels,
 					// Host and Ports will be looked up when needed
 				},
 			}
@@ -192,6 +194,7 @@ func (l *DockerListener) init() {
 				ports:           l.getPortsFromPs(co),
 				creationTime:    integration.Before,
 				checkNames:      checkNames,
+				labels:          co.Labels,
 				metricsExcluded: metricsExcluded,
 				logsExcluded:    logsExcluded,
 			}
@@ -273,6 +276,7 @@ func (l *DockerListener) createService(ctx context.Context, cID string) {
 			DockerService: DockerService{
 				cID:        cID,
 				checkNames: checkNames,
+				labels:     cInspect.Config.Labels,
 			},
 		}
 	} else {
@@ -280,6 +284,7 @@ func (l *DockerListener) createService(ctx context.Context, cID string) {
 			cID:             cID,
 			creationTime:    integration.After,
 			checkNames:      checkNames,
+			labels:          cInspect.Config.Labels,
 			metricsExcluded: l.filters.IsExcluded(containers.MetricsFilter, containerName, containerImage, ""),
 			logsExcluded:    l.filters.IsExcluded(containers.LogsFilter, containerName, containerImage, ""),
 		}
@@ -384,7 +389,7 @@ func (l *DockerListener) getPortsFromPs(co types.Container) []ContainerPort {
 	var ports []ContainerPort
 
 	for _, p := range co.Ports {
-		ports = append(ports, ContainerPort{int(p.PrivatePort), ""})
+		ports = append(ports, ContainerPort{Port: int(p.PrivatePort), Protocol: p.Type})
 	}
 	sort.Slice(ports, func(i, j int) bool {
 		return ports[i].Port < ports[j].Port
@@ -544,7 +549,7 @@ func parseDockerPort(port nat.Port) ([]ContainerPort, error) {
 	first, last, err := port.Range()
 	if err == nil && last > first {
 		for p := first; p <= last; p++ {
-			output = append(output, ContainerPort{p, ""})
+			output = append(output, ContainerPort{Port: p, Protocol: port.Proto()})
 		}
 		return output, nil
 	}
@@ -552,7 +557,7 @@ func parseDockerPort(port nat.Port) ([]ContainerPort, error) {
 	// Try to parse a single port (most common case)
 	p := port.Int()
 	if p > 0 {
-		output = append(output, ContainerPort{p, ""})
+		output = append(output, ContainerPort{Port: p, Protocol: port.Proto()})
 		return output, nil
 	}
 
@@ -660,7 +665,13 @@ func (s *DockerService) HasFilter(filter containers.FilterType) bool {
 	return false
 }
 
-// GetExtraConfig isn't supported
+// GetExtraConfig resolves the labels of the container
 func (s *DockerService) GetExtraConfig(key []byte) ([]byte, error) {
+	if name := string(key); strings.HasPrefix(name, labelExtraConfigPrefix) {
+		if value, found := s.labels[strings.TrimPrefix(name, labelExtraConfigPrefix)]; found {
+			return []byte(value), nil
+		}
+		return []byte{}, fmt.Errorf("label %q not found", strings.TrimPrefix(name, labelExtraConfigPrefix))
+	}
 	return []byte{}, ErrNotSupported
 }
@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
@@ -89,7 +90,7 @@ func (s *DockerKubeletService) GetPorts(ctx context.Context) ([]ContainerPort, e
 	for _, container := range pod.Spec.Containers {
 		if container.Name == searchedContainerName {
 			for _, port := range container.Ports {
-				ports = append(ports, ContainerPort{port.ContainerPort, port.Name})
+				ports = append(ports, ContainerPort{Port: port.ContainerPort, Name: port.Name, Protocol: strings.ToLower(port.Protocol)})
 			}
 		}
 	}
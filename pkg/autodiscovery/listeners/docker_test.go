@@ -167,7 +167,21 @@ func TestGetPortsFromPs(t *testing.T) {
 	ports := dl.getPortsFromPs(co)
 
 	// Make sure the order is OK too
-	assert.Equal(t, []ContainerPort{{1234, ""}, {4321, ""}}, ports)
+	assert.Equal(t, []ContainerPort{{Port: 1234}, {Port: 4321}}, ports)
+}
+
+func TestDockerServiceGetExtraConfig(t *testing.T) {
+	s := DockerService{cID: "deadbeef", labels: map[string]string{"com.example.team": "cache"}}
+
+	team, err := s.GetExtraConfig([]byte("label_com.example.team"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cache"), team)
+
+	_, err = s.GetExtraConfig([]byte("label_com.example.app"))
+	assert.EqualError(t, err, `label "com.example.app" not found`)
+
+	_, err = s.GetExtraConfig([]byte("pod_name"))
+	assert.Equal(t, ErrNotSupported, err)
 }
 
 func TestGetADIdentifiers(t *testing.T) {
@@ -413,10 +427,10 @@ func TestGetPorts(t *testing.T) {
 
 	pts, _ := svc.GetPorts(ctx)
 	assert.Equal(t, 4, len(pts))
-	assert.Contains(t, pts, ContainerPort{42, ""})
-	assert.Contains(t, pts, ContainerPort{43, ""})
-	assert.Contains(t, pts, ContainerPort{44, ""})
-	assert.Contains(t, pts, ContainerPort{45, ""})
+	assert.Contains(t, pts, ContainerPort{Port: 42, Protocol: "tcp"})
+	assert.Contains(t, pts, ContainerPort{Port: 43, Protocol: "tcp"})
+	assert.Contains(t, pts, ContainerPort{Port: 44, Protocol: "tcp"})
+	assert.Contains(t, pts, ContainerPort{Port: 45, Protocol: "tcp"})
 
 	// Both binding ports and exposed ports, only firsts should be picked up
 	id = "test"
@@ -453,7 +467,7 @@ func TestGetPorts(t *testing.T) {
 	}
 
 	pts, _ = svc.GetPorts(ctx)
-	assert.Equal(t, []ContainerPort{{1234, ""}, {4321, ""}}, pts)
+	assert.Equal(t, []ContainerPort{{Port: 1234, Protocol: "tcp"}, {Port: 4321, Protocol: "tcp"}}, pts)
 }
 
 func TestGetPid(t *testing.T) {
@@ -487,13 +501,13 @@ func TestParseDockerPort(t *testing.T) {
 		{
 			proto:         "tcp",
 			port:          "42",
-			expectedPorts: []ContainerPort{{42, ""}},
+			expectedPorts: []ContainerPort{{Port: 42, Protocol: "tcp"}},
 			expectedError: nil,
 		},
 		{
 			proto:         "udp",
 			port:          "500-503",
-			expectedPorts: []ContainerPort{{500, ""}, {501, ""}, {502, ""}, {503, ""}},
+			expectedPorts: []ContainerPort{{Port: 500, Protocol: "udp"}, {Port: 501, Protocol: "udp"}, {Port: 502, Protocol: "udp"}, {Port: 503, Protocol: "udp"}},
 			expectedError: nil,
 		},
 		{
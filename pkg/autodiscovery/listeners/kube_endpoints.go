@@ -11,6 +11,7 @@ package listeners
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
@@ -298,7 +299,7 @@ func processEndpoints(kep *v1.Endpoints, alreadyExistingService bool, tags []str
 		ports := []ContainerPort{}
 		// Ports
 		for _, port := range kep.Subsets[i].Ports {
-			ports = append(ports, ContainerPort{int(port.Port), port.Name})
+			ports = append(ports, ContainerPort{Port: int(port.Port), Name: port.Name, Protocol: strings.ToLower(string(port.Protocol))})
 		}
 		// Hosts
 		for _, host := range kep.Subsets[i].Addresses {
@@ -74,7 +74,7 @@ func TestProcessEndpoints(t *testing.T) {
 
 	ports, err := eps[0].GetPorts(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, []ContainerPort{{123, "port123"}, {126, "port126"}}, ports)
+	assert.Equal(t, []ContainerPort{{Port: 123, Name: "port123"}, {Port: 126, Name: "port126"}}, ports)
 
 	tags, _, err := eps[0].GetTags()
 	assert.NoError(t, err)
@@ -93,7 +93,7 @@ func TestProcessEndpoints(t *testing.T) {
 
 	ports, err = eps[1].GetPorts(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, []ContainerPort{{123, "port123"}, {126, "port126"}}, ports)
+	assert.Equal(t, []ContainerPort{{Port: 123, Name: "port123"}, {Port: 126, Name: "port126"}}, ports)
 
 	tags, _, err = eps[1].GetTags()
 	assert.NoError(t, err)
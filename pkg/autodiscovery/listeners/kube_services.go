@@ -12,6 +12,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
@@ -48,6 +49,7 @@ type KubeServiceService struct {
 	hosts        map[string]string
 	ports        []ContainerPort
 	creationTime integration.CreationTime
+	extraConfig  map[string]string
 }
 
 // Make sure KubeServiceService implements the Service interface
@@ -199,6 +201,7 @@ func processService(ksvc *v1.Service, firstRun bool) *KubeServiceService {
 	svc := &KubeServiceService{
 		entity:       apiserver.EntityForService(ksvc),
 		creationTime: integration.After,
+		extraConfig:  map[string]string{},
 	}
 	if firstRun {
 		svc.creationTime = integration.Before
@@ -213,13 +216,15 @@ func processService(ksvc *v1.Service, firstRun bool) *KubeServiceService {
 	// Standard tags from the service's labels
 	svc.tags = append(svc.tags, getStandardTags(ksvc.GetLabels())...)
 
+	addLabelsToExtraConfig(svc.extraConfig, ksvc.GetLabels(), ksvc.GetAnnotations())
+
 	// Hosts, only use internal ClusterIP for now
 	svc.hosts = map[string]string{"cluster": ksvc.Spec.ClusterIP}
 
 	// Ports
 	var ports []ContainerPort
 	for _, port := range ksvc.Spec.Ports {
-		ports = append(ports, ContainerPort{int(port.Port), port.Name})
+		ports = append(ports, ContainerPort{Port: int(port.Port), Name: port.Name, Protocol: strings.ToLower(string(port.Protocol))})
 	}
 	sort.Slice(ports, func(i, j int) bool {
 		return ports[i].Port < ports[j].Port
@@ -315,7 +320,12 @@ func (s *KubeServiceService) HasFilter(filter containers.FilterType) bool {
 	return false
 }
 
-// GetExtraConfig isn't supported
+// GetExtraConfig resolves the labels and annotations of the service
 func (s *KubeServiceService) GetExtraConfig(key []byte) ([]byte, error) {
-	return []byte{}, ErrNotSupported
+	result, found := s.extraConfig[string(key)]
+	if !found {
+		return []byte{}, fmt.Errorf("extra config %q is not supported", key)
+	}
+
+	return []byte(result), nil
 }
@@ -63,7 +63,7 @@ func TestProcessService(t *testing.T) {
 
 	ports, err := svc.GetPorts(ctx)
 	assert.NoError(t, err)
-	assert.Equal(t, []ContainerPort{{123, "test1"}, {126, "test2"}}, ports)
+	assert.Equal(t, []ContainerPort{{Port: 123, Name: "test1"}, {Port: 126, Name: "test2"}}, ports)
 
 	tags, _, err := svc.GetTags()
 	assert.NoError(t, err)
@@ -78,6 +78,15 @@ func TestProcessService(t *testing.T) {
 	sort.Strings(tags)
 	assert.Equal(t, expectedTags, tags)
 
+	env, err := svc.GetExtraConfig([]byte("label_tags.datadoghq.com/env"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("dev"), env)
+	instances, err := svc.GetExtraConfig([]byte("annotation_ad.datadoghq.com/service.instances"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`[{"name": "My service", "url": "http://%%host%%", "timeout": 1}]`), instances)
+	_, err = svc.GetExtraConfig([]byte("label_foo"))
+	assert.Error(t, err)
+
 	svc = processService(ksvc, false)
 	assert.Equal(t, integration.After, svc.GetCreationTime())
 }
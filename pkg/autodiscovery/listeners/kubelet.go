@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -182,7 +183,7 @@ func (l *KubeletListener) createPodService(pod *kubelet.Pod, firstRun bool) {
 	var ports []ContainerPort
 	for _, container := range pod.Spec.Containers {
 		for _, port := range container.Ports {
-			ports = append(ports, ContainerPort{port.ContainerPort, port.Name})
+			ports = append(ports, ContainerPort{Port: port.ContainerPort, Name: port.Name, Protocol: strings.ToLower(port.Protocol)})
 		}
 	}
 	sort.Slice(ports, func(i, j int) bool {
@@ -226,6 +227,7 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 			"pod_uid":   pod.Metadata.UID,
 		},
 	}
+	addLabelsToExtraConfig(svc.extraConfig, pod.Metadata.Labels, pod.Metadata.Annotations)
 	podName := pod.Metadata.Name
 
 	// AD Identifiers
@@ -304,7 +306,7 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 	for _, container := range pod.Spec.Containers {
 		if container.Name == containerName {
 			for _, port := range container.Ports {
-				ports = append(ports, ContainerPort{port.ContainerPort, port.Name})
+				ports = append(ports, ContainerPort{Port: port.ContainerPort, Name: port.Name, Protocol: strings.ToLower(port.Protocol)})
 			}
 			break
 		}
@@ -270,7 +270,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1337, "footcpport"}, {1339, "fooudpport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1337, Name: "footcpport", Protocol: "tcp"}, {Port: 1339, Name: "fooudpport", Protocol: "udp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -283,6 +283,11 @@ func TestProcessNewPod(t *testing.T) {
 		podNamespace, err := service.GetExtraConfig([]byte("namespace"))
 		assert.Nil(t, err)
 		assert.Equal(t, []byte("mock-pod-namespace"), podNamespace)
+		checkID, err := service.GetExtraConfig([]byte("annotation_ad.datadoghq.com/custom.check.id"))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("custom-check-id"), checkID)
+		_, err = service.GetExtraConfig([]byte("label_app"))
+		assert.NotNil(t, err)
 	default:
 		assert.FailNow(t, "first service not in channel")
 	}
@@ -299,7 +304,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -330,7 +335,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Equal(t, []string{"baz_check"}, service.GetCheckNames(ctx))
@@ -361,7 +366,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -395,7 +400,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -426,7 +431,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -458,7 +463,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -489,7 +494,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -552,7 +557,7 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Equal(t, map[string]string{"pod": "127.0.0.1"}, hosts)
 		ports, err := service.GetPorts(ctx)
 		assert.Nil(t, err)
-		assert.Equal(t, []ContainerPort{{1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1122, "barport"}, {1337, "footcpport"}, {1339, "fooudpport"}}, ports)
+		assert.Equal(t, []ContainerPort{{Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1122, Name: "barport", Protocol: "tcp"}, {Port: 1337, Name: "footcpport", Protocol: "tcp"}, {Port: 1339, Name: "fooudpport", Protocol: "udp"}}, ports)
 		_, err = service.GetPid(ctx)
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(ctx), 0)
@@ -0,0 +1,497 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processEntityPrefix = "process://"
+	processHostNetwork  = "host"
+
+	// socket states, as found in /proc/net/{tcp,udp}
+	tcpListen      = "0A"
+	udpUnconnected = "07"
+)
+
+// nativeEndian is the byte order used by the kernel to print addresses in procfs
+var nativeEndian binary.ByteOrder = binary.BigEndian
+
+func init() {
+	Register("process", NewProcessListener)
+
+	i := int32(0x01020304)
+	if *(*byte)(unsafe.Pointer(&i)) == 0x04 {
+		nativeEndian = binary.LittleEndian
+	}
+}
+
+// processLister lists the processes running on the host
+type processLister interface {
+	ProcessesByPID(now time.Time) (map[int32]*procutil.Process, error)
+}
+
+// ProcessListener discovers the services running as plain processes on the host, i.e.
+// outside of any container, based on the sockets they listen on.
+type ProcessListener struct {
+	sync.RWMutex
+	newService    chan<- Service
+	delService    chan<- Service
+	services      map[string]*ProcessService // maps entity names to services
+	stop          chan bool
+	refreshTicker *time.Ticker
+	procRoot      string
+	probe         processLister
+}
+
+// ProcessService represents a process listening on at least one port
+type ProcessService struct {
+	pid           int
+	entity        string
+	adIdentifiers []string
+	hosts         map[string]string
+	ports         []ContainerPort
+	creationTime  integration.CreationTime
+}
+
+// Make sure ProcessService implements the Service interface
+var _ Service = &ProcessService{}
+
+// listeningSocket is a socket in the listening state, as reported by procfs
+type listeningSocket struct {
+	proto string
+	ip    net.IP
+	port  int
+}
+
+// NewProcessListener creates a ProcessListener
+func NewProcessListener() (ServiceListener, error) {
+	interval := config.Datadog.GetDuration("process_listener.refresh_interval") * time.Second
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid process_listener.refresh_interval: %s", interval)
+	}
+	return &ProcessListener{
+		services:      map[string]*ProcessService{},
+		stop:          make(chan bool),
+		refreshTicker: time.NewTicker(interval),
+		procRoot:      util.HostProc(),
+		probe:         procutil.NewProcessProbe(),
+	}, nil
+}
+
+// Listen periodically scans the processes of the host
+func (l *ProcessListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go func() {
+		l.refreshServices(true)
+		for {
+			select {
+			case <-l.stop:
+				l.refreshTicker.Stop()
+				if c, ok := l.probe.(interface{ Close() }); ok {
+					c.Close()
+				}
+				return
+			case <-l.refreshTicker.C:
+				l.refreshServices(false)
+			}
+		}
+	}()
+}
+
+// Stop stops the ProcessListener
+func (l *ProcessListener) Stop() {
+	l.stop <- true
+}
+
+// refreshServices creates services for the processes newly listening on a port and
+// removes the services of the processes that are gone or stopped listening.
+func (l *ProcessListener) refreshServices(firstRun bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	procs, err := l.probe.ProcessesByPID(time.Now())
+	if err != nil {
+		log.Warnf("Could not list processes: %s", err)
+		return
+	}
+	sockets := l.listeningSockets()
+	socketsByPID := l.socketsByPID(procs, sockets)
+
+	notSeen := make(map[string]struct{}, len(l.services))
+	for entity := range l.services {
+		notSeen[entity] = struct{}{}
+	}
+
+	for pid, socks := range socketsByPID {
+		svc := l.createService(procs[pid], socks, firstRun)
+		if svc == nil {
+			continue
+		}
+		if old, found := l.services[svc.entity]; found {
+			delete(notSeen, svc.entity)
+			if sameServicePorts(old.ports, svc.ports) {
+				continue
+			}
+			// the process now listens on different ports: reschedule its checks
+			l.delService <- old
+			svc.creationTime = integration.After
+		}
+		log.Debugf("Process %d (%s) listening on %v", pid, svc.adIdentifiers[0], svc.ports)
+		l.services[svc.entity] = svc
+		l.newService <- svc
+	}
+
+	for entity := range notSeen {
+		l.delService <- l.services[entity]
+		delete(l.services, entity)
+	}
+}
+
+// createService returns the service of a process, or nil if it should not be discovered.
+func (l *ProcessListener) createService(proc *procutil.Process, sockets []listeningSocket, firstRun bool) *ProcessService {
+	if proc == nil || len(sockets) == 0 {
+		return nil
+	}
+	// processes living in another PID namespace run in a container, which is
+	// already covered by the container listeners
+	if proc.NsPid != 0 && proc.NsPid != proc.Pid {
+		return nil
+	}
+	ids := processADIdentifiers(proc)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	crTime := integration.After
+	if firstRun {
+		crTime = integration.Before
+	}
+
+	svc := &ProcessService{
+		pid:           int(proc.Pid),
+		entity:        fmt.Sprintf("%s%d", processEntityPrefix, proc.Pid),
+		adIdentifiers: ids,
+		hosts:         map[string]string{},
+		creationTime:  crTime,
+	}
+	// sort the sockets so that the host doesn't depend on the order they were
+	// found in, preferring TCP as most checks connect over TCP, then IPv4
+	sockets = sortedSockets(sockets)
+	svc.hosts[processHostNetwork] = socketHost(sockets[0].ip)
+	seen := make(map[ContainerPort]struct{}, len(sockets))
+	for _, s := range sockets {
+		p := ContainerPort{Port: s.port, Protocol: s.proto}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		svc.ports = append(svc.ports, p)
+	}
+	sort.Slice(svc.ports, func(i, j int) bool {
+		if svc.ports[i].Port != svc.ports[j].Port {
+			return svc.ports[i].Port < svc.ports[j].Port
+		}
+		return svc.ports[i].Protocol < svc.ports[j].Protocol
+	})
+	return svc
+}
+
+// sortedSockets returns a copy of sockets sorted with TCP before UDP, IPv4 before
+// IPv6, then by port
+func sortedSockets(sockets []listeningSocket) []listeningSocket {
+	sorted := make([]listeningSocket, len(sockets))
+	copy(sorted, sockets)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.proto != b.proto {
+			return a.proto == "tcp"
+		}
+		if aV4, bV4 := a.ip.To4() != nil, b.ip.To4() != nil; aV4 != bV4 {
+			return aV4
+		}
+		return a.port < b.port
+	})
+	return sorted
+}
+
+// processADIdentifiers returns the AD identifiers of a process, derived from the name
+// of its executable.
+func processADIdentifiers(proc *procutil.Process) []string {
+	var ids []string
+	add := func(name string) {
+		name = filepath.Base(strings.TrimSuffix(name, " (deleted)"))
+		if name == "" || name == "." || name == "/" {
+			return
+		}
+		for _, id := range ids {
+			if id == name {
+				return
+			}
+		}
+		ids = append(ids, name)
+	}
+	if proc.Exe != "" {
+		add(proc.Exe)
+	}
+	if len(proc.Cmdline) > 0 {
+		// some programs rewrite their command line, e.g. "redis-server *:6379"
+		// or "nginx: master process"
+		if fields := strings.Fields(proc.Cmdline[0]); len(fields) > 0 {
+			add(strings.TrimSuffix(fields[0], ":"))
+		}
+	}
+	add(proc.Name)
+	return ids
+}
+
+// socketsByPID associates the listening sockets to the processes owning them. When a
+// socket is shared by several processes, e.g. pre-forking servers, it is only
+// associated to the topmost one.
+func (l *ProcessListener) socketsByPID(procs map[int32]*procutil.Process, sockets map[uint64]listeningSocket) map[int32][]listeningSocket {
+	if len(sockets) == 0 {
+		return nil
+	}
+	owners := make(map[uint64][]int32)
+	for pid := range procs {
+		for _, inode := range l.socketInodes(pid) {
+			if _, ok := sockets[inode]; ok {
+				owners[inode] = append(owners[inode], pid)
+			}
+		}
+	}
+
+	byPID := make(map[int32][]listeningSocket)
+	for inode, pids := range owners {
+		isOwner := make(map[int32]bool, len(pids))
+		for _, pid := range pids {
+			isOwner[pid] = true
+		}
+		for _, pid := range pids {
+			if isOwner[procs[pid].Ppid] {
+				continue
+			}
+			byPID[pid] = append(byPID[pid], sockets[inode])
+		}
+	}
+	return byPID
+}
+
+// socketInodes returns the inodes of the sockets opened by a process. Reading the file
+// descriptors of processes owned by other users requires elevated permissions.
+func (l *ProcessListener) socketInodes(pid int32) []uint64 {
+	fdDir := filepath.Join(l.procRoot, strconv.Itoa(int(pid)), "fd")
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		return nil
+	}
+	var inodes []uint64
+	for _, fd := range fds {
+		if fd.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") || !strings.HasSuffix(target, "]") {
+			continue
+		}
+		inode, err := strconv.ParseUint(target[len("socket:["):len(target)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		inodes = append(inodes, inode)
+	}
+	return inodes
+}
+
+// listeningSockets returns the listening TCP and bound UDP sockets of the host, indexed by inode.
+// They are read from the network namespace of the init process, as /proc/net is the one of the
+// agent, which differs from the host's when the agent runs in a container.
+func (l *ProcessListener) listeningSockets() map[uint64]listeningSocket {
+	sockets := make(map[uint64]listeningSocket)
+	for _, f := range []struct {
+		file, proto, state string
+	}{
+		{"tcp", "tcp", tcpListen},
+		{"tcp6", "tcp", tcpListen},
+		{"udp", "udp", udpUnconnected},
+		{"udp6", "udp", udpUnconnected},
+	} {
+		path := filepath.Join(l.procRoot, "1", "net", f.file)
+		if err := readProcNetFile(path, f.proto, f.state, sockets); err != nil && !os.IsNotExist(err) {
+			log.Debugf("Could not read %s: %s", path, err)
+		}
+	}
+	return sockets
+}
+
+// readProcNetFile adds the sockets of the given state found in a /proc/net/{tcp,udp}[6] file.
+func readProcNetFile(path, proto, state string, sockets map[uint64]listeningSocket) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip the header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		ip, port, err := parseProcNetAddr(fields[1])
+		if err != nil {
+			log.Debugf("Invalid address %q in %s: %s", fields[1], path, err)
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		sockets[inode] = listeningSocket{proto: proto, ip: ip, port: port}
+	}
+	return scanner.Err()
+}
+
+// parseProcNetAddr parses an address of the form 0100007F:1F90, where the IP address
+// is written as 32-bit words in host byte order.
+func parseProcNetAddr(s string) (net.IP, int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("missing port")
+	}
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid ip")
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port: %s", err)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		nativeEndian.PutUint32(ip[i:i+4], binary.BigEndian.Uint32(raw[i:i+4]))
+	}
+	return ip, int(port), nil
+}
+
+// socketHost returns the address a check should use to reach a socket bound to ip
+func socketHost(ip net.IP) string {
+	if ip.IsUnspecified() {
+		if ip.To4() != nil {
+			return "127.0.0.1"
+		}
+		return "::1"
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.String()
+	}
+	return ip.String()
+}
+
+func sameServicePorts(a, b []ContainerPort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetEntity returns the unique entity name linked to that service
+func (s *ProcessService) GetEntity() string {
+	return s.entity
+}
+
+// GetTaggerEntity returns the tagger entity, processes have none
+func (s *ProcessService) GetTaggerEntity() string {
+	return ""
+}
+
+// GetADIdentifiers returns the names of the executable of the process
+func (s *ProcessService) GetADIdentifiers(context.Context) ([]string, error) {
+	return s.adIdentifiers, nil
+}
+
+// GetHosts returns the address the process listens on
+func (s *ProcessService) GetHosts(context.Context) (map[string]string, error) {
+	return s.hosts, nil
+}
+
+// GetPorts returns the ports the process listens on
+func (s *ProcessService) GetPorts(context.Context) ([]ContainerPort, error) {
+	return s.ports, nil
+}
+
+// GetTags returns no tags, host tags are added by the checks
+func (s *ProcessService) GetTags() ([]string, string, error) {
+	return nil, "", nil
+}
+
+// GetPid returns the process identifier
+func (s *ProcessService) GetPid(context.Context) (int, error) {
+	return s.pid, nil
+}
+
+// GetHostname is not supported
+func (s *ProcessService) GetHostname(context.Context) (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetCreationTime returns the creation time of the Service
+func (s *ProcessService) GetCreationTime() integration.CreationTime {
+	return s.creationTime
+}
+
+// IsReady returns true as the process already listens on its ports
+func (s *ProcessService) IsReady(context.Context) bool {
+	return true
+}
+
+// GetCheckNames is not supported
+func (s *ProcessService) GetCheckNames(context.Context) []string {
+	return nil
+}
+
+// HasFilter is not supported
+func (s *ProcessService) HasFilter(filter containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig is not supported
+func (s *ProcessService) GetExtraConfig(key []byte) ([]byte, error) {
+	return []byte{}, ErrNotSupported
+}
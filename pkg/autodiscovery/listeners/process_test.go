@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+type fakeProcessLister map[int32]*procutil.Process
+
+func (f fakeProcessLister) ProcessesByPID(time.Time) (map[int32]*procutil.Process, error) {
+	return f, nil
+}
+
+const procNetHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+// writeFakeProc creates a procfs tree holding the given /proc/1/net files and, for
+// each pid, file descriptors pointing to the given socket inodes.
+func writeFakeProc(t *testing.T, net map[string]string, fds map[int32][]int) string {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "1", "net"), 0755))
+	for name, content := range net {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(root, "1", "net", name), []byte(procNetHeader+content), 0644))
+	}
+	for pid, inodes := range fds {
+		dir := filepath.Join(root, strconv.Itoa(int(pid)), "fd")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, "0")))
+		for i, inode := range inodes {
+			require.NoError(t, os.Symlink("socket:["+strconv.Itoa(inode)+"]", filepath.Join(dir, strconv.Itoa(i+3))))
+		}
+	}
+	return root
+}
+
+// procNetAddr formats an address the way the kernel does in /proc/net files
+func procNetAddr(ip net.IP, port int) string {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	var s string
+	for i := 0; i < len(ip); i += 4 {
+		s += fmt.Sprintf("%08X", nativeEndian.Uint32(ip[i:i+4]))
+	}
+	return fmt.Sprintf("%s:%04X", s, port)
+}
+
+func procNetLine(local, state string, inode int) string {
+	return "   0: " + local + " 00000000:0000 " + state + " 00000000:00000000 00:00000000 00000000     0        0 " + strconv.Itoa(inode) + " 1 0000000000000000 100 0 0 10 0\n"
+}
+
+func TestParseProcNetAddr(t *testing.T) {
+	for _, tt := range []struct {
+		ip   string
+		port int
+	}{
+		{"127.0.0.1", 6379},
+		{"0.0.0.0", 80},
+		{"10.1.2.3", 65535},
+		{"::", 443},
+		{"fe80::1", 8080},
+	} {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip, port, err := parseProcNetAddr(procNetAddr(net.ParseIP(tt.ip), tt.port))
+			require.NoError(t, err)
+			assert.True(t, ip.Equal(net.ParseIP(tt.ip)), "got %s", ip)
+			assert.Equal(t, tt.port, port)
+		})
+	}
+
+	for _, invalid := range []string{"0100007F", "0100007F:XYZ", "01007F:1F90", "0100007F:1FFFFF"} {
+		_, _, err := parseProcNetAddr(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestProcessADIdentifiers(t *testing.T) {
+	assert.Equal(t, []string{"redis-server"}, processADIdentifiers(&procutil.Process{
+		Exe:     "/usr/bin/redis-server",
+		Cmdline: []string{"/usr/bin/redis-server *:6379"},
+		Name:    "redis-server",
+	}))
+	assert.Equal(t, []string{"postgres", "postmaster"}, processADIdentifiers(&procutil.Process{
+		Exe:     "/usr/lib/postgresql/13/bin/postgres (deleted)",
+		Cmdline: []string{"/usr/lib/postgresql/13/bin/postmaster", "-D", "/var/lib/postgresql"},
+		Name:    "postgres",
+	}))
+	// the executable is not readable without elevated permissions
+	assert.Equal(t, []string{"nginx"}, processADIdentifiers(&procutil.Process{
+		Cmdline: []string{"nginx: master process /usr/sbin/nginx"},
+		Name:    "nginx",
+	}))
+	assert.Empty(t, processADIdentifiers(&procutil.Process{Cmdline: []string{" "}}))
+}
+
+func TestProcessListener(t *testing.T) {
+	procs := fakeProcessLister{
+		1:   {Pid: 1, NsPid: 1, Exe: "/sbin/init", Name: "systemd"},
+		100: {Pid: 100, Ppid: 1, NsPid: 100, Exe: "/usr/bin/redis-server", Name: "redis-server"},
+		200: {Pid: 200, Ppid: 1, NsPid: 200, Exe: "/usr/sbin/nginx", Name: "nginx"},
+		201: {Pid: 201, Ppid: 200, NsPid: 201, Exe: "/usr/sbin/nginx", Name: "nginx"},
+		300: {Pid: 300, Ppid: 1, NsPid: 1, Exe: "/usr/bin/mongod", Name: "mongod"}, // containerized
+		400: {Pid: 400, Ppid: 1, NsPid: 400, Exe: "/usr/bin/sleep", Name: "sleep"},
+	}
+	root := writeFakeProc(t, map[string]string{
+		"tcp": procNetLine(procNetAddr(net.ParseIP("127.0.0.1"), 6379), tcpListen, 1001) +
+			procNetLine(procNetAddr(net.ParseIP("0.0.0.0"), 80), tcpListen, 2001) +
+			procNetLine(procNetAddr(net.ParseIP("10.0.0.1"), 44444), "01", 2002) + // established
+			procNetLine(procNetAddr(net.ParseIP("0.0.0.0"), 27017), tcpListen, 3001),
+		"tcp6": procNetLine(procNetAddr(net.ParseIP("::"), 443), tcpListen, 2003),
+		"udp":  procNetLine(procNetAddr(net.ParseIP("0.0.0.0"), 514), udpUnconnected, 2004),
+	}, map[int32][]int{
+		100: {1001},
+		200: {2001, 2003, 2004},
+		201: {2001, 2002, 2003}, // sockets inherited from the master process
+		300: {3001},
+		400: {1001}, // unknown socket
+	})
+	defer os.RemoveAll(root)
+
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l := &ProcessListener{
+		newService: newSvc,
+		delService: delSvc,
+		services:   map[string]*ProcessService{},
+		procRoot:   root,
+		probe:      procs,
+	}
+	delete(procs, 400) // the process exited before it was scanned
+
+	l.refreshServices(true)
+	require.Len(t, newSvc, 2)
+	services := map[string]Service{}
+	for len(newSvc) > 0 {
+		svc := <-newSvc
+		services[svc.GetEntity()] = svc
+	}
+	ctx := context.Background()
+
+	redis := services["process://100"]
+	require.NotNil(t, redis)
+	ids, err := redis.GetADIdentifiers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"redis-server"}, ids)
+	ports, err := redis.GetPorts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 6379, Protocol: "tcp"}}, ports)
+	hosts, err := redis.GetHosts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+	pid, err := redis.GetPid(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, pid)
+	assert.Equal(t, integration.Before, redis.GetCreationTime())
+
+	nginx := services["process://200"]
+	require.NotNil(t, nginx)
+	ports, err = nginx.GetPorts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 80, Protocol: "tcp"}, {Port: 443, Protocol: "tcp"}, {Port: 514, Protocol: "udp"}}, ports)
+	hosts, err = nginx.GetHosts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "127.0.0.1"}, hosts)
+
+	// nothing changed
+	l.refreshServices(false)
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+
+	// redis exited and nginx stopped listening on 443
+	delete(procs, 100)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "1", "net", "tcp6"), []byte(procNetHeader), 0644))
+	l.refreshServices(false)
+	require.Len(t, delSvc, 2)
+	deleted := map[string]bool{}
+	for len(delSvc) > 0 {
+		deleted[(<-delSvc).GetEntity()] = true
+	}
+	assert.Equal(t, map[string]bool{"process://100": true, "process://200": true}, deleted)
+	require.Len(t, newSvc, 1)
+	svc := <-newSvc
+	assert.Equal(t, "process://200", svc.GetEntity())
+	assert.Equal(t, integration.After, svc.GetCreationTime())
+	ports, err = svc.GetPorts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContainerPort{{Port: 80, Protocol: "tcp"}, {Port: 514, Protocol: "udp"}}, ports)
+}
+
+func TestSortedSockets(t *testing.T) {
+	sockets := []listeningSocket{
+		{proto: "udp", ip: net.ParseIP("0.0.0.0"), port: 53},
+		{proto: "tcp", ip: net.ParseIP("::"), port: 80},
+		{proto: "tcp", ip: net.ParseIP("0.0.0.0"), port: 8080},
+		{proto: "tcp", ip: net.ParseIP("127.0.0.1"), port: 443},
+	}
+	assert.Equal(t, []listeningSocket{sockets[3], sockets[2], sockets[1], sockets[0]}, sortedSockets(sockets))
+	assert.Equal(t, "udp", sockets[0].proto, "the sockets are not sorted in place")
+}
@@ -347,7 +347,7 @@ func (s *SNMPService) GetHosts(context.Context) (map[string]string, error) {
 // GetPorts returns the device port
 func (s *SNMPService) GetPorts(context.Context) ([]ContainerPort, error) {
 	port := int(s.config.Port)
-	return []ContainerPort{{port, fmt.Sprintf("p%d", port)}}, nil
+	return []ContainerPort{{Port: port, Name: fmt.Sprintf("p%d", port), Protocol: "udp"}}, nil
 }
 
 // GetTags returns the list of container tags - currently always empty
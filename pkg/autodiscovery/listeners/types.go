@@ -18,6 +18,9 @@ import (
 type ContainerPort struct {
 	Port int
 	Name string
+	// Protocol is the lowercase transport protocol of the port (e.g. tcp or udp),
+	// empty when unknown.
+	Protocol string
 }
 
 // Service represents an application we can run a check against.
@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+// +build kubelet
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var gvrDatadogCheck = schema.GroupVersionResource{
+	Group:    "datadoghq.com",
+	Version:  "v1alpha1",
+	Resource: "datadogchecks",
+}
+
+// datadogCheck is the representation of a DatadogCheck custom resource
+type datadogCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec datadogCheckSpec `json:"spec"`
+}
+
+// datadogCheckSpec describes a check to schedule on the pods matching a label selector.
+// Only pods living in the namespace of the DatadogCheck are considered.
+type datadogCheckSpec struct {
+	Check                   string                   `json:"check"`
+	Selector                *metav1.LabelSelector    `json:"selector,omitempty"`
+	ContainerNames          []string                 `json:"containerNames,omitempty"`
+	InitConfig              map[string]interface{}   `json:"initConfig,omitempty"`
+	Instances               []map[string]interface{} `json:"instances"`
+	IgnoreAutodiscoveryTags bool                     `json:"ignoreAutodiscoveryTags,omitempty"`
+}
+
+// DatadogCheckConfigProvider implements the ConfigProvider interface for the
+// DatadogCheck custom resources. Checks are scheduled on the containers of
+// the local pods matching the resource selector.
+type DatadogCheckConfigProvider struct {
+	kubelet      kubelet.KubeUtilInterface
+	informer     cache.SharedIndexInformer
+	lister       cache.GenericLister
+	configErrors map[string]ErrorMsgSet
+	sync.Mutex
+}
+
+// NewDatadogCheckConfigProvider returns a new ConfigProvider watching the DatadogCheck
+// resources through the apiserver. Connectivity to the kubelet is not checked at this
+// stage to allow for retries, Collect will do it.
+func NewDatadogCheckConfigProvider(config config.ConfigurationProviders) (ConfigProvider, error) {
+	// Using GetAPIClient() (no retry)
+	if _, err := apiserver.GetAPIClient(); err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	informerFactory, err := apiserver.GetDDInformerFactory()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get datadoghq informer factory: %s", err)
+	}
+
+	datadogChecksInformer := informerFactory.ForResource(gvrDatadogCheck)
+	p := &DatadogCheckConfigProvider{
+		informer:     datadogChecksInformer.Informer(),
+		lister:       datadogChecksInformer.Lister(),
+		configErrors: make(map[string]ErrorMsgSet),
+	}
+
+	// The provider lives as long as the agent, the informer is never stopped
+	informerFactory.Start(make(chan struct{}))
+
+	return p, nil
+}
+
+// String returns a string representation of the DatadogCheckConfigProvider
+func (d *DatadogCheckConfigProvider) String() string {
+	return names.DatadogChecks
+}
+
+// Collect matches the DatadogCheck resources against the kubelet's podlist, builds Config objects and returns them
+func (d *DatadogCheckConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	if !d.informer.HasSynced() {
+		return []integration.Config{}, errors.New("DatadogCheck informer is not synced yet")
+	}
+
+	var err error
+	if d.kubelet == nil {
+		d.kubelet, err = kubelet.GetKubeUtil()
+		if err != nil {
+			return []integration.Config{}, err
+		}
+	}
+
+	pods, err := d.kubelet.GetLocalPodList(ctx)
+	if err != nil {
+		return []integration.Config{}, err
+	}
+
+	objs, err := d.lister.List(labels.Everything())
+	if err != nil {
+		return []integration.Config{}, err
+	}
+
+	checks := make([]*datadogCheck, 0, len(objs))
+	for _, obj := range objs {
+		check, err := unstructuredIntoDatadogCheck(obj)
+		if err != nil {
+			log.Warnf("Cannot parse DatadogCheck: %s", err)
+			continue
+		}
+		checks = append(checks, check)
+	}
+
+	configs, configErrors := parseDatadogChecks(checks, pods)
+
+	d.Lock()
+	d.configErrors = configErrors
+	d.Unlock()
+
+	return configs, nil
+}
+
+// IsUpToDate always returns false, pods matching the DatadogCheck selectors can change at any time
+func (d *DatadogCheckConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return false, nil
+}
+
+// GetConfigErrors returns a map of configuration errors for each DatadogCheck
+func (d *DatadogCheckConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	d.Lock()
+	defer d.Unlock()
+
+	return d.configErrors
+}
+
+func unstructuredIntoDatadogCheck(obj runtime.Object) (*datadogCheck, error) {
+	unstrObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("could not cast Unstructured object: %v", obj)
+	}
+
+	check := &datadogCheck{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstrObj.UnstructuredContent(), check); err != nil {
+		return nil, fmt.Errorf("%s/%s: %s", unstrObj.GetNamespace(), unstrObj.GetName(), err)
+	}
+
+	return check, nil
+}
+
+// parseDatadogChecks returns the configs of the DatadogCheck resources for the
+// containers of the matching pods, and the errors found for each resource.
+func parseDatadogChecks(checks []*datadogCheck, pods []*kubelet.Pod) ([]integration.Config, map[string]ErrorMsgSet) {
+	var configs []integration.Config
+	configErrors := make(map[string]ErrorMsgSet)
+
+	for _, check := range checks {
+		namespacedName := check.Namespace + "/" + check.Name
+
+		selector, initConfig, instances, err := buildDatadogCheck(check)
+		if err != nil {
+			log.Errorf("Can't parse DatadogCheck %s: %s", namespacedName, err)
+			configErrors[namespacedName] = ErrorMsgSet{err.Error(): struct{}{}}
+			continue
+		}
+
+		for _, pod := range pods {
+			if pod.Metadata.Namespace != check.Namespace || !selector.Matches(labels.Set(pod.Metadata.Labels)) {
+				continue
+			}
+
+			for _, container := range pod.Status.Containers {
+				if container.ID == "" || !matchContainerName(check.Spec.ContainerNames, container.Name) {
+					continue
+				}
+
+				configs = append(configs, integration.Config{
+					Name:                    check.Spec.Check,
+					InitConfig:              initConfig,
+					Instances:               instances,
+					ADIdentifiers:           []string{container.ID},
+					Source:                  "datadog_check:" + namespacedName,
+					IgnoreAutodiscoveryTags: check.Spec.IgnoreAutodiscoveryTags,
+				})
+			}
+		}
+	}
+
+	return configs, configErrors
+}
+
+// buildDatadogCheck validates a DatadogCheck spec and converts it
+func buildDatadogCheck(check *datadogCheck) (labels.Selector, integration.Data, []integration.Data, error) {
+	spec := check.Spec
+	if spec.Check == "" {
+		return nil, nil, nil, errors.New("missing check name")
+	}
+	if spec.Selector == nil {
+		return nil, nil, nil, errors.New("missing selector")
+	}
+	if len(spec.Instances) == 0 {
+		return nil, nil, nil, errors.New("missing instances")
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid selector: %s", err)
+	}
+
+	initConfig := integration.Data("{}")
+	if spec.InitConfig != nil {
+		if initConfig, err = json.Marshal(spec.InitConfig); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid init config: %s", err)
+		}
+	}
+
+	instances := make([]integration.Data, 0, len(spec.Instances))
+	for _, instance := range spec.Instances {
+		data, err := json.Marshal(instance)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid instance: %s", err)
+		}
+		instances = append(instances, data)
+	}
+
+	return selector, initConfig, instances, nil
+}
+
+// matchContainerName returns whether a container is targeted by a DatadogCheck,
+// all the containers of the matching pods are if no name is given.
+func matchContainerName(containerNames []string, name string) bool {
+	if len(containerNames) == 0 {
+		return true
+	}
+	for _, containerName := range containerNames {
+		if containerName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RegisterProvider("datadog_checks", NewDatadogCheckConfigProvider)
+}
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+// +build kubelet
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+)
+
+func newFakeDatadogCheck(namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "datadoghq.com/v1alpha1",
+			"kind":       "DatadogCheck",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func newFakePod(namespace, name string, podLabels map[string]string, containers ...kubelet.ContainerStatus) *kubelet.Pod {
+	return &kubelet.Pod{
+		Metadata: kubelet.PodMetadata{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    podLabels,
+		},
+		Status: kubelet.Status{
+			Containers:    containers,
+			AllContainers: containers,
+		},
+	}
+}
+
+func TestParseDatadogChecks(t *testing.T) {
+	pods := []*kubelet.Pod{
+		newFakePod("cache", "redis-0", map[string]string{"app": "redis"},
+			kubelet.ContainerStatus{Name: "redis", ID: "docker://redis-0"},
+			kubelet.ContainerStatus{Name: "sidecar", ID: "docker://sidecar-0"},
+		),
+		newFakePod("cache", "redis-1", map[string]string{"app": "redis"},
+			kubelet.ContainerStatus{Name: "redis", ID: ""}, // not started yet
+		),
+		newFakePod("default", "redis", map[string]string{"app": "redis"},
+			kubelet.ContainerStatus{Name: "redis", ID: "docker://redis-default"},
+		),
+		newFakePod("cache", "memcached", map[string]string{"app": "memcached"},
+			kubelet.ContainerStatus{Name: "memcached", ID: "docker://memcached"},
+		),
+	}
+
+	for _, tc := range []struct {
+		desc            string
+		check           *unstructured.Unstructured
+		expectedConfigs []integration.Config
+		expectedErrors  map[string]ErrorMsgSet
+	}{
+		{
+			desc: "match labels and container name",
+			check: newFakeDatadogCheck("cache", "redis", map[string]interface{}{
+				"check":          "redisdb",
+				"selector":       map[string]interface{}{"matchLabels": map[string]interface{}{"app": "redis"}},
+				"containerNames": []interface{}{"redis"},
+				"instances": []interface{}{
+					map[string]interface{}{"host": "%%host%%", "port": int64(6379)},
+				},
+			}),
+			expectedConfigs: []integration.Config{
+				{
+					Name:          "redisdb",
+					InitConfig:    integration.Data("{}"),
+					Instances:     []integration.Data{integration.Data(`{"host":"%%host%%","port":6379}`)},
+					ADIdentifiers: []string{"docker://redis-0"},
+					Source:        "datadog_check:cache/redis",
+				},
+			},
+			expectedErrors: map[string]ErrorMsgSet{},
+		},
+		{
+			desc: "all containers, match expressions",
+			check: newFakeDatadogCheck("cache", "http", map[string]interface{}{
+				"check": "http_check",
+				"selector": map[string]interface{}{
+					"matchExpressions": []interface{}{
+						map[string]interface{}{"key": "app", "operator": "In", "values": []interface{}{"redis", "memcached"}},
+					},
+				},
+				"initConfig": map[string]interface{}{"timeout": int64(5)},
+				"instances": []interface{}{
+					map[string]interface{}{"url": "http://%%host%%"},
+					map[string]interface{}{"url": "https://%%host%%"},
+				},
+				"ignoreAutodiscoveryTags": true,
+			}),
+			expectedConfigs: []integration.Config{
+				{
+					Name:                    "http_check",
+					InitConfig:              integration.Data(`{"timeout":5}`),
+					Instances:               []integration.Data{integration.Data(`{"url":"http://%%host%%"}`), integration.Data(`{"url":"https://%%host%%"}`)},
+					ADIdentifiers:           []string{"docker://redis-0"},
+					Source:                  "datadog_check:cache/http",
+					IgnoreAutodiscoveryTags: true,
+				},
+				{
+					Name:                    "http_check",
+					InitConfig:              integration.Data(`{"timeout":5}`),
+					Instances:               []integration.Data{integration.Data(`{"url":"http://%%host%%"}`), integration.Data(`{"url":"https://%%host%%"}`)},
+					ADIdentifiers:           []string{"docker://sidecar-0"},
+					Source:                  "datadog_check:cache/http",
+					IgnoreAutodiscoveryTags: true,
+				},
+				{
+					Name:                    "http_check",
+					InitConfig:              integration.Data(`{"timeout":5}`),
+					Instances:               []integration.Data{integration.Data(`{"url":"http://%%host%%"}`), integration.Data(`{"url":"https://%%host%%"}`)},
+					ADIdentifiers:           []string{"docker://memcached"},
+					Source:                  "datadog_check:cache/http",
+					IgnoreAutodiscoveryTags: true,
+				},
+			},
+			expectedErrors: map[string]ErrorMsgSet{},
+		},
+		{
+			desc: "no matching pod",
+			check: newFakeDatadogCheck("cache", "mongo", map[string]interface{}{
+				"check":     "mongo",
+				"selector":  map[string]interface{}{"matchLabels": map[string]interface{}{"app": "mongo"}},
+				"instances": []interface{}{map[string]interface{}{}},
+			}),
+			expectedConfigs: nil,
+			expectedErrors:  map[string]ErrorMsgSet{},
+		},
+		{
+			desc: "missing selector",
+			check: newFakeDatadogCheck("cache", "redis", map[string]interface{}{
+				"check":     "redisdb",
+				"instances": []interface{}{map[string]interface{}{}},
+			}),
+			expectedConfigs: nil,
+			expectedErrors: map[string]ErrorMsgSet{
+				"cache/redis": {"missing selector": struct{}{}},
+			},
+		},
+		{
+			desc: "missing instances",
+			check: newFakeDatadogCheck("cache", "redis", map[string]interface{}{
+				"check":    "redisdb",
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "redis"}},
+			}),
+			expectedConfigs: nil,
+			expectedErrors: map[string]ErrorMsgSet{
+				"cache/redis": {"missing instances": struct{}{}},
+			},
+		},
+		{
+			desc: "invalid selector",
+			check: newFakeDatadogCheck("cache", "redis", map[string]interface{}{
+				"check": "redisdb",
+				"selector": map[string]interface{}{
+					"matchExpressions": []interface{}{
+						map[string]interface{}{"key": "app", "operator": "Unknown"},
+					},
+				},
+				"instances": []interface{}{map[string]interface{}{}},
+			}),
+			expectedConfigs: nil,
+			expectedErrors: map[string]ErrorMsgSet{
+				"cache/redis": {`invalid selector: "Unknown" is not a valid pod selector operator`: struct{}{}},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			check, err := unstructuredIntoDatadogCheck(tc.check)
+			require.NoError(t, err)
+
+			configs, errs := parseDatadogChecks([]*datadogCheck{check}, pods)
+			assert.Equal(t, tc.expectedConfigs, configs)
+			assert.Equal(t, tc.expectedErrors, errs)
+		})
+	}
+}
+
+func TestUnstructuredIntoDatadogCheck(t *testing.T) {
+	_, err := unstructuredIntoDatadogCheck(newFakeDatadogCheck("cache", "redis", map[string]interface{}{
+		"check": []interface{}{"redisdb"},
+	}))
+	assert.Error(t, err)
+}
@@ -10,6 +10,7 @@ const (
 	Consul             = "consul"
 	CloudFoundryBBS    = "cloudfoundry-bbs"
 	ClusterChecks      = "cluster-checks"
+	DatadogChecks      = "datadog-checks"
 	Docker             = "docker"
 	ECS                = "ecs"
 	EndpointsChecks    = "endpoints-checks"
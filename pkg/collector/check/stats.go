@@ -6,6 +6,7 @@
 package check
 
 import (
+	"errors"
 	"sync"
 	"time"
 
@@ -32,6 +33,8 @@ var (
 		[]string{"check_name", "state"}, "Check runs")
 	tlmWarnings = telemetry.NewCounter("checks", "warnings",
 		[]string{"check_name"}, "Check warnings")
+	tlmTimeouts = telemetry.NewCounter("checks", "timeouts",
+		[]string{"check_name"}, "Check runs that timed out")
 	tlmMetricsSamples = telemetry.NewCounter("checks", "metrics_samples",
 		[]string{"check_name"}, "Metrics count")
 	tlmEvents = telemetry.NewCounter("checks", "events",
@@ -77,6 +80,7 @@ type Stats struct {
 	CheckID                  ID
 	TotalRuns                uint64
 	TotalErrors              uint64
+	TotalTimeouts            uint64
 	TotalWarnings            uint64
 	MetricSamples            int64
 	Events                   int64
@@ -147,6 +151,12 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 			tlmRuns.Inc(cs.CheckName, runCheckFailureTag)
 		}
 		cs.LastError = err.Error()
+		if errors.As(err, &TimeoutError{}) {
+			cs.TotalTimeouts++
+			if cs.telemetry {
+				tlmTimeouts.Inc(cs.CheckName)
+			}
+		}
 	} else {
 		if cs.telemetry {
 			tlmRuns.Inc(cs.CheckName, runCheckSuccessTag)
@@ -197,6 +207,15 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 	}
 }
 
+// ExecutionStats returns the total number of runs and the average execution
+// time in milliseconds of the check, safe to call while the check runs
+func (cs *Stats) ExecutionStats() (uint64, int64) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	return cs.TotalRuns, cs.AverageExecutionTime
+}
+
 type aggStats struct {
 	EventPlatformEvents       map[string]interface{}
 	EventPlatformEventsErrors map[string]interface{}
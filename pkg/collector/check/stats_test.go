@@ -6,9 +6,11 @@
 package check
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -104,6 +106,23 @@ func TestNewStatsStateTelemetryInitializedWhenGloballyEnabled(t *testing.T) {
 	)
 }
 
+func TestStatsAddTimeout(t *testing.T) {
+	stats := NewStats(newMockCheck())
+
+	stats.Add(time.Second, errors.New("failure"), nil, NewSenderStats())
+	assert.Equal(t, uint64(1), stats.TotalErrors)
+	assert.Equal(t, uint64(0), stats.TotalTimeouts)
+
+	stats.Add(30*time.Second, TimeoutError{Timeout: 30 * time.Second}, nil, NewSenderStats())
+	assert.Equal(t, uint64(2), stats.TotalErrors)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+	assert.Equal(t, "check run timed out after 30s", stats.LastError)
+
+	stats.Add(time.Second, nil, nil, NewSenderStats())
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+	assert.Empty(t, stats.LastError)
+}
+
 func TestTranslateEventPlatformEventTypes(t *testing.T) {
 	original := map[string]interface{}{
 		"EventPlatformEvents": map[string]interface{}{
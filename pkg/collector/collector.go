@@ -90,6 +90,12 @@ func (c *Collector) Stop() {
 
 // RunCheck sends a Check in the execution queue
 func (c *Collector) RunCheck(ch check.Check) (check.ID, error) {
+	return c.RunCheckWithSchedule(ch, nil)
+}
+
+// RunCheckWithSchedule sends a Check in the execution queue, applying the
+// given scheduling options if they're not nil
+func (c *Collector) RunCheckWithSchedule(ch check.Check, schedule *scheduler.Schedule) (check.ID, error) {
 	c.m.Lock()
 	defer c.m.Unlock()
 
@@ -103,7 +109,7 @@ func (c *Collector) RunCheck(ch check.Check) (check.ID, error) {
 		return emptyID, fmt.Errorf("a check with ID %s is already running", ch.ID())
 	}
 
-	err := c.scheduler.Enter(ch)
+	err := c.scheduler.EnterWithSchedule(ch, schedule)
 	if err != nil {
 		return emptyID, fmt.Errorf("unable to schedule the check: %s", err)
 	}
@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package external
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Maximum duration of the configuration of an external check
+const configureTimeout = 30 * time.Second
+
+// Maximum duration of a run of an external check. The runner stops the check
+// earlier when its check_timeout is shorter.
+var runTimeout = 10 * time.Minute
+
+// ExternalCheck runs a check as an external executable. The process is started
+// on the first run of the check, and kept running between its runs.
+type ExternalCheck struct {
+	corechecks.CheckBase
+	path       string
+	args       []string
+	version    string
+	instance   integration.Data
+	initConfig integration.Data
+	process    *process
+	m          sync.Mutex // To protect process and version
+}
+
+func newExternalCheck(name, path string, args []string) *ExternalCheck {
+	return &ExternalCheck{
+		CheckBase: corechecks.NewCheckBase(name),
+		path:      path,
+		args:      args,
+	}
+}
+
+// Configure validates the configuration of the instance. The check process is
+// only started on the first run, so that the checks the collector rejects, for
+// instance duplicates, don't leave it running.
+func (c *ExternalCheck) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	c.BuildID(data, initConfig)
+	if err := c.CheckBase.Configure(data, initConfig, source); err != nil {
+		return err
+	}
+
+	if _, err := toJSONMap(data); err != nil {
+		return fmt.Errorf("invalid instance: %s", err)
+	}
+	if _, err := toJSONMap(initConfig); err != nil {
+		return fmt.Errorf("invalid init_config: %s", err)
+	}
+
+	c.instance = data
+	c.initConfig = initConfig
+	return nil
+}
+
+// start starts and configures the check process, it must be called with c.m locked
+func (c *ExternalCheck) start() error {
+	instance, err := toJSONMap(c.instance)
+	if err != nil {
+		return fmt.Errorf("invalid instance: %s", err)
+	}
+	initConfig, err := toJSONMap(c.initConfig)
+	if err != nil {
+		return fmt.Errorf("invalid init_config: %s", err)
+	}
+
+	p, err := startProcess(c.String(), c.path, c.args)
+	if err != nil {
+		return fmt.Errorf("could not start %s: %s", c.path, err)
+	}
+
+	err = p.send(request{
+		Type:            requestConfigure,
+		ProtocolVersion: ProtocolVersion,
+		CheckID:         string(c.ID()),
+		Name:            c.String(),
+		Instance:        instance,
+		InitConfig:      initConfig,
+	})
+	if err != nil {
+		p.stop()
+		return err
+	}
+
+	deadline := time.Now().Add(configureTimeout)
+	for {
+		m, err := p.receive(deadline)
+		if err != nil {
+			p.stop()
+			return fmt.Errorf("could not configure the check: %s", err)
+		}
+
+		switch m.Type {
+		case messageWarning:
+			c.Warn(m.Message) //nolint:errcheck
+		case messageDone:
+			if m.Error != "" {
+				p.stop()
+				return errors.New(m.Error)
+			}
+			c.version = m.Version
+			c.process = p
+			return nil
+		default:
+			log.Debugf("Ignoring %s message of external check %s during its configuration", m.Type, c.ID())
+		}
+	}
+}
+
+// Run asks the check process to run the check, and submits the metrics,
+// service checks and events it sends. The process is started if it isn't
+// running yet, and discarded if it doesn't complete the run in time or the
+// check is stopped. What was sent before a failure is submitted too.
+func (c *ExternalCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	deadline := time.Now().Add(runTimeout)
+
+	c.m.Lock()
+	if c.process == nil {
+		if err := c.start(); err != nil {
+			c.m.Unlock()
+			return err
+		}
+	}
+	p := c.process
+	c.m.Unlock()
+
+	if err := p.send(request{Type: requestRun}); err != nil {
+		c.discardProcess(p)
+		return err
+	}
+
+	for {
+		m, err := p.receive(deadline)
+		if err == errNoAnswer {
+			c.discardProcess(p)
+			return check.TimeoutError{Timeout: runTimeout}
+		} else if err != nil {
+			c.discardProcess(p)
+			return err
+		}
+
+		switch m.Type {
+		case messageWarning:
+			c.Warn(m.Message) //nolint:errcheck
+		case messageDone:
+			if m.Error != "" {
+				return errors.New(m.Error)
+			}
+			return nil
+		default:
+			if err := m.submit(sender); err != nil {
+				c.Warnf("Invalid %s message: %s", m.Type, err) //nolint:errcheck
+			}
+		}
+	}
+}
+
+// discardProcess stops the process after a failure, it's restarted on the next run
+func (c *ExternalCheck) discardProcess(p *process) {
+	p.stop()
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.process == p {
+		c.process = nil
+	}
+}
+
+// Stop stops the check process, which interrupts the current run if any. The
+// process is started again on the next run.
+func (c *ExternalCheck) Stop() {
+	c.m.Lock()
+	p := c.process
+	c.process = nil
+	c.m.Unlock()
+
+	if p != nil {
+		p.stop()
+	}
+}
+
+// Cancel stops the check process
+func (c *ExternalCheck) Cancel() {
+	c.Stop()
+	c.CommonCancel()
+}
+
+// Version returns the version sent by the check when it was configured
+func (c *ExternalCheck) Version() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.version
+}
+
+// toJSONMap converts a YAML configuration to a JSON serializable map
+func toJSONMap(data integration.Data) (interface{}, error) {
+	raw := integration.RawMap{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return util.GetJSONSerializableMap(raw), nil
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package external
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// helperArgs runs the test binary as an external check, see TestHelperProcess
+func helperArgs(mode string) []string {
+	return []string{"-test.run=TestHelperProcess", "--", mode}
+}
+
+// TestHelperProcess isn't a real test, it's the external check run by the
+// other tests. It behaves depending on the mode following the `--` argument.
+func TestHelperProcess(t *testing.T) {
+	if len(os.Args) < 2 || os.Args[len(os.Args)-2] != "--" {
+		return
+	}
+	mode := os.Args[len(os.Args)-1]
+
+	out := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	var instance map[string]interface{}
+	for scanner.Scan() {
+		req := request{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		switch req.Type {
+		case requestConfigure:
+			if mode == "fail_configure" {
+				out.Encode(map[string]interface{}{"type": "done", "error": "missing url"}) //nolint:errcheck
+				continue
+			}
+			instance = req.Instance.(map[string]interface{})
+			out.Encode(map[string]interface{}{"type": "warning", "message": "deprecated option"}) //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "done", "version": "1.2.0"})                //nolint:errcheck
+		case requestRun:
+			if mode == "crash" {
+				os.Exit(1)
+			}
+			if mode == "hang" {
+				select {}
+			}
+			fmt.Fprintln(os.Stderr, "running")
+			out.Encode(map[string]interface{}{"type": "metric", "metric_type": "gauge", "name": "app.users", "value": 12, "tags": []string{"url:" + instance["url"].(string)}}) //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "metric", "metric_type": "monotonic_count", "name": "app.requests", "value": 120, "flush_first_value": true})             //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "metric", "metric_type": "unknown", "name": "app.invalid", "value": 1})                                                   //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "service_check", "name": "app.can_connect", "status": 0, "tags": []string{"port:80"}, "message": "ok"})                   //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "event", "title": "App restarted", "text": "restarted", "alert_type": "warning", "tags": []string{"port:80"}})            //nolint:errcheck
+			out.Encode(map[string]interface{}{"type": "done"})                                                                                                                  //nolint:errcheck
+		}
+	}
+	os.Exit(0)
+}
+
+// newTestCheck returns an external check running the test binary, and its sender
+func newTestCheck(mode string, instance, initConfig integration.Data) (*ExternalCheck, *mocksender.MockSender) {
+	sender := mocksender.NewMockSender(check.BuildID("app", instance, initConfig))
+	sender.SetupAcceptAll()
+	return newExternalCheck("app", os.Args[0], helperArgs(mode)), sender
+}
+
+func TestExternalCheck(t *testing.T) {
+	instance, initConfig := integration.Data("url: http://localhost\n"), integration.Data("timeout: 5\n")
+	c, sender := newTestCheck("ok", instance, initConfig)
+	defer c.Cancel()
+
+	require.NoError(t, c.Configure(instance, initConfig, "test"))
+	// the process is started on the first run
+	assert.Nil(t, c.process)
+	assert.Equal(t, "", c.Version())
+
+	require.NoError(t, c.Run())
+	assert.Equal(t, "1.2.0", c.Version())
+	warnings := c.GetWarnings()
+	require.Len(t, warnings, 2)
+	assert.Equal(t, fmt.Errorf("deprecated option"), warnings[0])
+	assert.Contains(t, warnings[1].Error(), `unknown metric type "unknown"`)
+
+	require.NoError(t, c.Run())
+	warnings = c.GetWarnings()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Error(), `unknown metric type "unknown"`)
+
+	sender.AssertNumberOfCalls(t, "Gauge", 2)
+	sender.AssertMetric(t, "Gauge", "app.users", 12, "", []string{"url:http://localhost"})
+	sender.AssertMonotonicCount(t, "MonotonicCountWithFlushFirstValue", "app.requests", 120, "", nil, true)
+	sender.AssertServiceCheck(t, "app.can_connect", metrics.ServiceCheckOK, "", []string{"port:80"}, "ok")
+	sender.AssertEvent(t, metrics.Event{
+		Title:     "App restarted",
+		Text:      "restarted",
+		AlertType: metrics.EventAlertTypeWarning,
+		Tags:      []string{"port:80"},
+	}, 0)
+	sender.AssertNumberOfCalls(t, "Commit", 2)
+}
+
+func TestExternalCheckConfigureError(t *testing.T) {
+	c, _ := newTestCheck("fail_configure", integration.Data("{}"), integration.Data("{}"))
+	defer c.Cancel()
+
+	require.NoError(t, c.Configure(integration.Data("{}"), integration.Data("{}"), "test"))
+	assert.EqualError(t, c.Run(), "missing url")
+	assert.Nil(t, c.process)
+
+	assert.Error(t, c.Configure(integration.Data("{"), integration.Data("{}"), "test"))
+}
+
+func TestExternalCheckRestart(t *testing.T) {
+	instance, initConfig := integration.Data("url: http://localhost\n"), integration.Data("{}")
+	c, sender := newTestCheck("crash", instance, initConfig)
+	defer c.Cancel()
+
+	require.NoError(t, c.Configure(instance, initConfig, "test"))
+
+	assert.Equal(t, errProcessExited, c.Run())
+	assert.Nil(t, c.process)
+
+	// the process is started and configured again
+	assert.Equal(t, errProcessExited, c.Run())
+	sender.AssertNumberOfCalls(t, "Commit", 2)
+}
+
+func TestExternalCheckTimeout(t *testing.T) {
+	defer func(old time.Duration) { runTimeout = old }(runTimeout)
+	runTimeout = time.Second
+
+	instance, initConfig := integration.Data("url: http://localhost\n"), integration.Data("{}")
+	c, sender := newTestCheck("hang", instance, initConfig)
+	defer c.Cancel()
+
+	require.NoError(t, c.Configure(instance, initConfig, "test"))
+
+	assert.Equal(t, check.TimeoutError{Timeout: time.Second}, c.Run())
+	assert.Nil(t, c.process)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestExternalCheckStop(t *testing.T) {
+	instance, initConfig := integration.Data("url: http://localhost\n"), integration.Data("{}")
+	c, sender := newTestCheck("hang", instance, initConfig)
+	defer c.Cancel()
+
+	require.NoError(t, c.Configure(instance, initConfig, "test"))
+
+	// the runner stops the checks that time out
+	done := make(chan error)
+	go func() {
+		done <- c.Run()
+	}()
+	assert.Eventually(t, func() bool {
+		c.m.Lock()
+		defer c.m.Unlock()
+		return c.process != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	c.Stop()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "the run wasn't interrupted")
+	}
+	assert.Nil(t, c.process)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
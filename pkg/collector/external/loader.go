@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package external
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ExternalCheckLoader is a specific loader for checks running as executables
+// in the external_checksd directory
+type ExternalCheckLoader struct {
+	dir string
+}
+
+// NewExternalCheckLoader creates a loader for external checks
+func NewExternalCheckLoader() (*ExternalCheckLoader, error) {
+	dir := config.Datadog.GetString("external_checksd")
+	if dir == "" {
+		return nil, errors.New("external checks are disabled, external_checksd is not set")
+	}
+	return &ExternalCheckLoader{dir: dir}, nil
+}
+
+// Name returns the external loader name
+func (el *ExternalCheckLoader) Name() string {
+	return "external"
+}
+
+// Load returns an external check
+func (el *ExternalCheckLoader) Load(config integration.Config, instance integration.Data) (check.Check, error) {
+	var c check.Check
+
+	path, err := el.findExecutable(config.Name)
+	if err != nil {
+		return c, err
+	}
+
+	ec := newExternalCheck(config.Name, path, nil)
+	if err := ec.Configure(instance, config.InitConfig, config.Source); err != nil {
+		log.Errorf("external.loader: could not configure check %s: %s", ec, err)
+		return c, fmt.Errorf("Could not configure check %s: %s", ec, err)
+	}
+
+	return ec, nil
+}
+
+// findExecutable returns the path of the executable of the check
+func (el *ExternalCheckLoader) findExecutable(name string) (string, error) {
+	// the check name mustn't point outside of the directory
+	if name == "" || filepath.Base(name) != name || name == ".." {
+		return "", fmt.Errorf("invalid check name %q", name)
+	}
+
+	path := filepath.Join(el.dir, name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("no executable for check %s in %s", name, el.dir)
+	}
+	if !info.Mode().IsRegular() || (runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0) {
+		return "", fmt.Errorf("%s is not an executable file", path)
+	}
+	return path, nil
+}
+
+func (el *ExternalCheckLoader) String() string {
+	return "External Check Loader"
+}
+
+func init() {
+	factory := func() (check.Loader, error) {
+		return NewExternalCheckLoader()
+	}
+
+	// after the core checks loader, so that an executable can't replace a core check
+	loaders.RegisterLoader(40, factory)
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !windows
+
+package external
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindExecutable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "external-checks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "not_executable"), []byte("#!/bin/sh\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "directory"), 0755))
+
+	loader := &ExternalCheckLoader{dir: dir}
+
+	path, err := loader.findExecutable("app")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "app"), path)
+
+	for _, name := range []string{"missing", "not_executable", "directory", "../app", "", ".."} {
+		_, err := loader.findExecutable(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package external
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Maximum length of a line sent by an external check
+const maxLineSize = 1024 * 1024
+
+var (
+	errProcessExited = errors.New("the check process exited")
+	errNoAnswer      = errors.New("no answer from the check in time")
+)
+
+// process is a running external check executable. It reads the requests of the
+// agent on its standard input and answers with messages on its standard output,
+// one JSON object per line. Its standard error is logged.
+type process struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	done   chan struct{}
+	closer sync.Once
+}
+
+// startProcess starts the executable of an external check
+func startProcess(name, path string, args []string) (*process, error) {
+	cmd := exec.Command(path, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{
+		name:  name,
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan []byte),
+		done:  make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		p.readStdout(stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		p.readStderr(stderr)
+	}()
+	go func() {
+		// the pipes must be read entirely before waiting for the process
+		wg.Wait()
+		if err := cmd.Wait(); err != nil {
+			log.Debugf("External check %s exited: %s", p.name, err)
+		}
+	}()
+
+	return p, nil
+}
+
+func (p *process) readStdout(stdout io.Reader) {
+	defer close(p.lines)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := make([]byte, len(scanner.Bytes()))
+		copy(line, scanner.Bytes())
+		select {
+		case p.lines <- line:
+		case <-p.done:
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Warnf("Error reading the output of external check %s: %s", p.name, err)
+	}
+}
+
+func (p *process) readStderr(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		log.Debugf("External check %s: %s", p.name, scanner.Text())
+	}
+}
+
+// send writes a request on the standard input of the process
+func (p *process) send(req request) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := p.stdin.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("could not send the %s request: %s", req.Type, err)
+	}
+	return nil
+}
+
+// receive returns the next message sent by the process, or errNoAnswer if it
+// doesn't send one before the deadline
+func (p *process) receive(deadline time.Time) (*message, error) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return nil, errProcessExited
+		}
+		m := &message{}
+		if err := json.Unmarshal(line, m); err != nil {
+			return nil, fmt.Errorf("invalid message %q: %s", line, err)
+		}
+		return m, nil
+	case <-timer.C:
+		return nil, errNoAnswer
+	}
+}
+
+// stop closes the standard input of the process and kills it
+func (p *process) stop() {
+	p.closer.Do(func() {
+		close(p.done)
+		p.stdin.Close() //nolint:errcheck
+		if p.cmd.Process != nil {
+			p.cmd.Process.Kill() //nolint:errcheck
+		}
+	})
+}
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package external
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// ProtocolVersion is the version of the protocol spoken with the external
+// checks, sent in the configure request
+const ProtocolVersion = 1
+
+// Types of the requests sent by the agent to the check
+const (
+	requestConfigure = "configure"
+	requestRun       = "run"
+)
+
+// Types of the messages sent by the check to the agent
+const (
+	messageMetric       = "metric"
+	messageServiceCheck = "service_check"
+	messageEvent        = "event"
+	messageWarning      = "warning"
+	messageDone         = "done"
+)
+
+// request is a line sent by the agent on the standard input of the check
+type request struct {
+	Type            string      `json:"type"`
+	ProtocolVersion int         `json:"protocol_version,omitempty"`
+	CheckID         string      `json:"check_id,omitempty"`
+	Name            string      `json:"name,omitempty"`
+	Instance        interface{} `json:"instance,omitempty"`
+	InitConfig      interface{} `json:"init_config,omitempty"`
+}
+
+// message is a line sent by the check on its standard output. The fields
+// used depend on its type.
+type message struct {
+	Type string `json:"type"`
+
+	// metric
+	MetricType      string  `json:"metric_type"`
+	Name            string  `json:"name"`
+	Value           float64 `json:"value"`
+	FlushFirstValue bool    `json:"flush_first_value"`
+
+	// service check
+	Status int `json:"status"`
+
+	// event
+	Title          string `json:"title"`
+	Text           string `json:"text"`
+	Timestamp      int64  `json:"timestamp"`
+	Priority       string `json:"priority"`
+	AlertType      string `json:"alert_type"`
+	AggregationKey string `json:"aggregation_key"`
+	SourceTypeName string `json:"source_type_name"`
+
+	// metric, service check and event
+	Hostname string   `json:"hostname"`
+	Tags     []string `json:"tags"`
+
+	// service check and warning
+	Message string `json:"message"`
+
+	// done
+	Error   string `json:"error"`
+	Version string `json:"version"`
+}
+
+// submit sends a metric, service check or event message to the sender
+func (m *message) submit(sender aggregator.Sender) error {
+	switch m.Type {
+	case messageMetric:
+		return m.submitMetric(sender)
+	case messageServiceCheck:
+		status, err := metrics.GetServiceCheckStatus(m.Status)
+		if err != nil {
+			return err
+		}
+		sender.ServiceCheck(m.Name, status, m.Hostname, m.Tags, m.Message)
+	case messageEvent:
+		e := metrics.Event{
+			Title:          m.Title,
+			Text:           m.Text,
+			Ts:             m.Timestamp,
+			Host:           m.Hostname,
+			Tags:           m.Tags,
+			AggregationKey: m.AggregationKey,
+			SourceTypeName: m.SourceTypeName,
+		}
+		if m.Priority != "" {
+			priority, err := metrics.GetEventPriorityFromString(m.Priority)
+			if err != nil {
+				return err
+			}
+			e.Priority = priority
+		}
+		if m.AlertType != "" {
+			alertType, err := metrics.GetAlertTypeFromString(m.AlertType)
+			if err != nil {
+				return err
+			}
+			e.AlertType = alertType
+		}
+		sender.Event(e)
+	default:
+		return fmt.Errorf("unknown message type %q", m.Type)
+	}
+	return nil
+}
+
+func (m *message) submitMetric(sender aggregator.Sender) error {
+	if m.Name == "" {
+		return fmt.Errorf("missing metric name")
+	}
+
+	switch m.MetricType {
+	case "gauge":
+		sender.Gauge(m.Name, m.Value, m.Hostname, m.Tags)
+	case "rate":
+		sender.Rate(m.Name, m.Value, m.Hostname, m.Tags)
+	case "count":
+		sender.Count(m.Name, m.Value, m.Hostname, m.Tags)
+	case "monotonic_count":
+		sender.MonotonicCountWithFlushFirstValue(m.Name, m.Value, m.Hostname, m.Tags, m.FlushFirstValue)
+	case "counter":
+		sender.Counter(m.Name, m.Value, m.Hostname, m.Tags)
+	case "histogram":
+		sender.Histogram(m.Name, m.Value, m.Hostname, m.Tags)
+	case "historate":
+		sender.Historate(m.Name, m.Value, m.Hostname, m.Tags)
+	default:
+		return fmt.Errorf("unknown metric type %q for metric %s", m.MetricType, m.Name)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package runner
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// Ratio between the workers and the estimated load, so that checks don't wait for each other
+	loadHeadroom = 1.25
+	// How often the number of checks waiting for a worker is sampled
+	queueDepthSamplingInterval = time.Second
+	// Average number of checks waiting for a worker above which workers are added
+	queueDepthThreshold = 0.5
+
+	decisionScaleUp   = "scale_up"
+	decisionScaleDown = "scale_down"
+	decisionNone      = "none"
+)
+
+var (
+	tlmDesiredWorkers = telemetry.NewGauge("runner", "desired_workers",
+		nil, "Number of check workers the autoscaler aims for")
+	tlmEstimatedLoad = telemetry.NewGauge("runner", "estimated_load",
+		nil, "Average number of checks running concurrently, estimated from the check stats")
+	tlmScalingDecisions = telemetry.NewCounter("runner", "scaling_decisions",
+		[]string{"decision"}, "Decisions taken by the check workers autoscaler")
+)
+
+// autoscalerStatus is the last decision of the autoscaler, exposed in the runner expvars
+type autoscalerStatus struct {
+	MinWorkers        int
+	MaxWorkers        int
+	Workers           int
+	DesiredWorkers    int
+	EstimatedLoad     float64
+	AverageQueueDepth float64
+	LastDecision      string
+	LastDecisionDate  int64
+}
+
+// autoscaler sizes the worker pool of a Runner between min and max workers,
+// from the load of the checks and the number of checks waiting for a worker.
+type autoscaler struct {
+	runner     *Runner
+	minWorkers int
+	maxWorkers int
+	interval   time.Duration
+	stop       chan struct{}
+
+	lastEvaluation    time.Time
+	lastRuns          map[check.ID]uint64
+	queueDepthSum     int64
+	queueDepthSamples int64
+
+	status autoscalerStatus
+	m      sync.RWMutex // To protect status
+}
+
+func newAutoscaler(r *Runner) *autoscaler {
+	minWorkers := config.Datadog.GetInt("check_runners_autoscaling.min_workers")
+	maxWorkers := config.Datadog.GetInt("check_runners_autoscaling.max_workers")
+	if minWorkers < 1 {
+		log.Warnf("Invalid check_runners_autoscaling.min_workers %d, using 1", minWorkers)
+		minWorkers = 1
+	}
+	if maxWorkers < minWorkers {
+		log.Warnf("Invalid check_runners_autoscaling.max_workers %d, using %d", maxWorkers, minWorkers)
+		maxWorkers = minWorkers
+	}
+
+	interval := time.Duration(config.Datadog.GetInt("check_runners_autoscaling.interval")) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	return &autoscaler{
+		runner:     r,
+		minWorkers: minWorkers,
+		maxWorkers: maxWorkers,
+		interval:   interval,
+		stop:       make(chan struct{}),
+		lastRuns:   make(map[check.ID]uint64),
+		status: autoscalerStatus{
+			MinWorkers:     minWorkers,
+			MaxWorkers:     maxWorkers,
+			Workers:        minWorkers,
+			DesiredWorkers: minWorkers,
+			LastDecision:   decisionNone,
+		},
+	}
+}
+
+// run samples the queue depth and evaluates the number of workers until stopped.
+// Not blocking, runs in a new goroutine.
+func (a *autoscaler) run() {
+	a.lastEvaluation = time.Now()
+
+	go func() {
+		sampleTicker := time.NewTicker(queueDepthSamplingInterval)
+		defer sampleTicker.Stop()
+		evalTicker := time.NewTicker(a.interval)
+		defer evalTicker.Stop()
+
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-sampleTicker.C:
+				a.sampleQueueDepth()
+			case t := <-evalTicker.C:
+				a.evaluate(t)
+			}
+		}
+	}()
+}
+
+func (a *autoscaler) sampleQueueDepth() {
+	a.runner.m.Lock()
+	s := a.runner.scheduler
+	a.runner.m.Unlock()
+
+	if s == nil {
+		return
+	}
+	a.queueDepthSum += s.QueueDepth()
+	a.queueDepthSamples++
+}
+
+// evaluate estimates the load since the previous evaluation and adds or removes workers
+func (a *autoscaler) evaluate(now time.Time) {
+	load := a.estimateLoad(now)
+
+	var queueDepth float64
+	if a.queueDepthSamples > 0 {
+		queueDepth = float64(a.queueDepthSum) / float64(a.queueDepthSamples)
+	}
+	a.queueDepthSum, a.queueDepthSamples = 0, 0
+
+	// long running checks hold their worker, they're not part of the pool
+	current := a.runner.numWorkers() - a.runner.numLongRunningChecks()
+	desired := desiredWorkers(current, load, queueDepth, a.minWorkers, a.maxWorkers)
+
+	decision := decisionNone
+	workers := current
+	switch {
+	case desired > current:
+		decision = decisionScaleUp
+		for ; workers < desired; workers++ {
+			a.runner.AddWorker()
+		}
+		log.Infof("Check load is %.2f with %.2f checks waiting on average, added %d workers to runner", load, queueDepth, desired-current)
+	case desired < current:
+		// only idle workers can be removed
+		for i := desired; i < current; i++ {
+			if a.runner.removeWorker() {
+				workers--
+			}
+		}
+		if workers < current {
+			decision = decisionScaleDown
+			log.Infof("Check load is %.2f, removed %d workers from runner", load, current-workers)
+		}
+	}
+
+	tlmDesiredWorkers.Set(float64(desired))
+	tlmEstimatedLoad.Set(load)
+	tlmScalingDecisions.Inc(decision)
+
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.status.Workers = workers
+	a.status.DesiredWorkers = desired
+	a.status.EstimatedLoad = load
+	a.status.AverageQueueDepth = queueDepth
+	if decision != decisionNone {
+		a.status.LastDecision = decision
+		a.status.LastDecisionDate = now.Unix()
+	}
+}
+
+// estimateLoad returns the average number of checks that ran concurrently since
+// the previous evaluation, from the number of runs and average execution time
+// of every check
+func (a *autoscaler) estimateLoad(now time.Time) float64 {
+	window := now.Sub(a.lastEvaluation)
+	a.lastEvaluation = now
+
+	runs := make(map[check.ID]uint64)
+	var busy time.Duration
+	for _, instances := range GetCheckStats() {
+		for id, stats := range instances {
+			totalRuns, averageExecutionTime := stats.ExecutionStats()
+			runs[id] = totalRuns
+			if newRuns := totalRuns - a.lastRuns[id]; totalRuns >= a.lastRuns[id] && newRuns > 0 {
+				busy += time.Duration(newRuns) * time.Duration(averageExecutionTime) * time.Millisecond
+			}
+		}
+	}
+	a.lastRuns = runs
+
+	if window <= 0 {
+		return 0
+	}
+	return float64(busy) / float64(window)
+}
+
+// getStatus returns the last decision of the autoscaler
+func (a *autoscaler) getStatus() autoscalerStatus {
+	a.m.RLock()
+	defer a.m.RUnlock()
+
+	return a.status
+}
+
+// desiredWorkers returns the number of workers needed for the given load,
+// between min and max workers. It scales up at once when checks are waiting for
+// a worker, but only scales down one worker at a time and when no check waits.
+func desiredWorkers(current int, load, queueDepth float64, minWorkers, maxWorkers int) int {
+	desired := int(math.Ceil(load * loadHeadroom))
+
+	switch {
+	case queueDepth >= queueDepthThreshold:
+		// checks are waiting for a worker
+		if waiting := current + int(math.Ceil(queueDepth)); waiting > desired {
+			desired = waiting
+		}
+	case desired < current && queueDepth == 0:
+		desired = current - 1
+	case desired < current:
+		desired = current
+	}
+
+	if desired < minWorkers {
+		desired = minWorkers
+	}
+	if desired > maxWorkers {
+		desired = maxWorkers
+	}
+	return desired
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestDesiredWorkers(t *testing.T) {
+	for _, tt := range []struct {
+		desc       string
+		current    int
+		load       float64
+		queueDepth float64
+		expected   int
+	}{
+		{"idle", 4, 0, 0, 4},
+		{"load within the pool", 8, 6, 0, 8},
+		{"load above the pool", 4, 6, 0, 8},
+		{"checks waiting", 6, 4, 3.2, 10},
+		{"few checks waiting", 6, 4, 0.2, 6},
+		{"load higher than waiting checks", 4, 10, 1, 13},
+		{"scale down one at a time", 12, 2, 0, 11},
+		{"no scale down while checks wait", 12, 2, 0.2, 12},
+		{"max workers", 20, 30, 0, 25},
+		{"min workers", 4, 0, 0, 4},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, desiredWorkers(tt.current, tt.load, tt.queueDepth, 4, 25))
+		})
+	}
+}
+
+func TestAutoscalerEstimateLoad(t *testing.T) {
+	s1 := addTestStat("LoadCheck:1")
+	defer RemoveCheckStats("LoadCheck:1")
+
+	a := newAutoscaler(&Runner{})
+	now := time.Now()
+	a.lastEvaluation = now
+
+	s1.TotalRuns = 10
+	s1.AverageExecutionTime = 3000 // 30s of work
+	assert.InDelta(t, 0.5, a.estimateLoad(now.Add(time.Minute)), 0.01)
+
+	// only the new runs count
+	s1.TotalRuns = 12
+	assert.InDelta(t, 0.1, a.estimateLoad(now.Add(2*time.Minute)), 0.01)
+
+	// the stats were reset
+	s1.TotalRuns = 1
+	assert.InDelta(t, 0, a.estimateLoad(now.Add(3*time.Minute)), 0.01)
+}
+
+func TestAutoscalerEvaluate(t *testing.T) {
+	r := NewRunner()
+	defer r.Stop()
+	workers := r.numWorkers()
+
+	config.Datadog.Set("check_runners_autoscaling.min_workers", workers)
+	config.Datadog.Set("check_runners_autoscaling.max_workers", workers+10)
+	defer config.Datadog.Set("check_runners_autoscaling.min_workers", config.DefaultNumWorkers)
+	defer config.Datadog.Set("check_runners_autoscaling.max_workers", config.MaxNumWorkers)
+
+	a := newAutoscaler(r)
+	now := time.Now()
+	a.lastEvaluation = now
+
+	// checks were waiting for a worker
+	a.queueDepthSum, a.queueDepthSamples = 6, 3
+	a.evaluate(now.Add(time.Minute))
+	assert.Equal(t, workers+2, r.numWorkers())
+	status := a.getStatus()
+	assert.Equal(t, decisionScaleUp, status.LastDecision)
+	assert.Equal(t, workers+2, status.Workers)
+	assert.Equal(t, 2.0, status.AverageQueueDepth)
+
+	// idle, one worker is removed once the new workers wait for a check
+	time.Sleep(100 * time.Millisecond)
+	a.evaluate(now.Add(2 * time.Minute))
+	require.Eventually(t, func() bool { return r.numWorkers() == workers+1 }, time.Second, 10*time.Millisecond)
+	status = a.getStatus()
+	assert.Equal(t, decisionScaleDown, status.LastDecision)
+	assert.Equal(t, workers+1, status.DesiredWorkers)
+}
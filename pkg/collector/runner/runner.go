@@ -68,10 +68,13 @@ type Runner struct {
 	// important for 32 bit compiles.
 	// see https://github.com/golang/go/issues/599#issuecomment-419909701 for more information
 	running          uint32                   // Flag to see if the Runner is, well, running
+	workers          int32                    // Number of workers of this runner, the expvar counts the workers of all runners
 	staticNumWorkers bool                     // Flag indicating if numWorkers is dynamically updated
 	pending          chan check.Check         // The channel where checks come from
 	runningChecks    map[check.ID]check.Check // The list of checks running
 	scheduler        *scheduler.Scheduler     // Scheduler runner operates on
+	stopWorker       chan struct{}            // The channel used to stop an idle worker
+	autoscaler       *autoscaler              // Sizes the worker pool when the autoscaling is enabled, nil otherwise
 	m                sync.Mutex               // To control races on runningChecks
 
 }
@@ -84,11 +87,19 @@ func NewRunner() *Runner {
 		// initialize the channel
 		pending:          make(chan check.Check),
 		runningChecks:    make(map[check.ID]check.Check),
+		stopWorker:       make(chan struct{}),
 		running:          1,
 		staticNumWorkers: numWorkers != 0,
 	}
 
-	if !r.staticNumWorkers {
+	if config.Datadog.GetBool("check_runners_autoscaling.enabled") {
+		r.autoscaler = newAutoscaler(r)
+		numWorkers = r.autoscaler.minWorkers
+		runnerStats.Set("Autoscaling", expvar.Func(func() interface{} {
+			return r.autoscaler.getStatus()
+		}))
+		r.autoscaler.run()
+	} else if !r.staticNumWorkers {
 		numWorkers = config.DefaultNumWorkers
 	}
 
@@ -104,15 +115,46 @@ func NewRunner() *Runner {
 // AddWorker adds a new worker to the worker pull
 func (r *Runner) AddWorker() {
 	runnerStats.Add("Workers", 1)
+	atomic.AddInt32(&r.workers, 1)
 	TestWg.Add(1)
 	go r.work()
 }
 
+// removeWorker stops a worker if one is idle, and returns whether it did
+func (r *Runner) removeWorker() bool {
+	select {
+	case r.stopWorker <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// numWorkers returns the current number of workers
+func (r *Runner) numWorkers() int {
+	return int(atomic.LoadInt32(&r.workers))
+}
+
+// numLongRunningChecks returns the number of long running checks holding a worker
+func (r *Runner) numLongRunningChecks() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	n := 0
+	for _, c := range r.runningChecks {
+		if c.Interval() == 0 {
+			n++
+		}
+	}
+	return n
+}
+
 // UpdateNumWorkers checks if the current number of workers is reasonable, and adds more if needed
 func (r *Runner) UpdateNumWorkers(numChecks int64) {
 	numWorkers, _ := strconv.Atoi(runnerStats.Get("Workers").String())
 
-	if r.staticNumWorkers {
+	// the number of workers is either static or managed by the autoscaler
+	if r.staticNumWorkers || r.autoscaler != nil {
 		return
 	}
 
@@ -156,6 +198,9 @@ func (r *Runner) Stop() {
 
 	log.Info("Runner is shutting down...")
 
+	if r.autoscaler != nil {
+		close(r.autoscaler.stop)
+	}
 	close(r.pending)
 	atomic.StoreUint32(&r.running, 0)
 
@@ -249,8 +294,21 @@ func (r *Runner) work() {
 	log.Debug("Ready to process checks...")
 	defer TestWg.Done()
 	defer runnerStats.Add("Workers", -1)
+	defer atomic.AddInt32(&r.workers, -1)
+
+	for {
+		var check check.Check
+		var ok bool
+		select {
+		case check, ok = <-r.pending:
+		case <-r.stopWorker:
+			log.Debug("Worker removed from the pool.")
+			return
+		}
+		if !ok {
+			break
+		}
 
-	for check := range r.pending {
 		// see if the check is already running
 		r.m.Lock()
 		if _, isRunning := r.runningChecks[check.ID()]; isRunning {
@@ -272,12 +330,13 @@ func (r *Runner) work() {
 		}
 
 		// run the check
-		var err error
 		t0 := time.Now()
 
 		runningChecksStats.Set(string(check.ID()), timeVar(t0))
-		err = check.Run()
-		runningChecksStats.Delete(string(check.ID()))
+		completed, err := r.runCheck(check)
+		if completed {
+			runningChecksStats.Delete(string(check.ID()))
+		}
 		longRunning := check.Interval() == 0
 
 		warnings := check.GetWarnings()
@@ -305,17 +364,24 @@ func (r *Runner) work() {
 		}
 
 		if sender != nil && !longRunning {
-			sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
-			sender.Commit()
+			if completed {
+				sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
+				sender.Commit()
+			} else {
+				reportTimeout(sender, hostname, serviceCheckTags, err)
+			}
 		}
 
-		// remove the check from the running list
-		r.m.Lock()
-		delete(r.runningChecks, check.ID())
-		r.m.Unlock()
+		// remove the check from the running list, unless it timed out, in which
+		// case it's removed once its run actually completes
+		if completed {
+			r.m.Lock()
+			delete(r.runningChecks, check.ID())
+			r.m.Unlock()
+			runnerStats.Add("RunningChecks", -1)
+		}
 
 		// publish statistics about this run
-		runnerStats.Add("RunningChecks", -1)
 		runnerStats.Add("Runs", 1)
 
 		r.m.Lock()
@@ -348,6 +414,92 @@ func (r *Runner) work() {
 	log.Debug("Finished processing checks.")
 }
 
+// reportTimeout submits the status service check of a check whose run timed out.
+// The sender isn't committed: service checks don't need it, and the run of the
+// check is still going on.
+func reportTimeout(sender aggregator.Sender, hostname string, tags []string, err error) {
+	sender.ServiceCheck("datadog.agent.check_status", metrics.ServiceCheckCritical, hostname, tags, err.Error())
+}
+
+// runCheck runs a check and returns whether its run completed, along with its
+// error. If the run doesn't complete within the check timeout, runCheck asks the
+// check to stop and returns a TimeoutError so that the worker can process other
+// checks. As Go and Python checks can't be interrupted, the check stays in the
+// running list and is suspended in the scheduler until its run actually
+// completes, so that it's neither run concurrently nor queued in the meantime.
+func (r *Runner) runCheck(c check.Check) (bool, error) {
+	timeout := getCheckTimeout(c)
+	if timeout == 0 {
+		return true, c.Run()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return true, err
+	case <-timer.C:
+	}
+
+	log.Errorf("Check %s did not complete within %v, freeing its worker", c, timeout)
+	runnerStats.Add("Timeouts", 1)
+
+	r.m.Lock()
+	s := r.scheduler
+	r.m.Unlock()
+	if s != nil {
+		s.SuspendCheck(c.ID())
+	}
+	go c.Stop()
+
+	go func() {
+		t0 := time.Now()
+		err := <-done
+		log.Warnf("Check %s completed %v after timing out, error: %v", c, time.Since(t0)+timeout, err)
+
+		r.m.Lock()
+		delete(r.runningChecks, c.ID())
+		r.m.Unlock()
+		runningChecksStats.Delete(string(c.ID()))
+		runnerStats.Add("RunningChecks", -1)
+		if s != nil {
+			s.ResumeCheck(c.ID())
+		}
+	}()
+
+	return false, check.TimeoutError{Timeout: timeout}
+}
+
+// getCheckTimeout returns the timeout of the runs of a check, 0 if they have none.
+// Long running checks never time out.
+func getCheckTimeout(c check.Check) time.Duration {
+	if c.Interval() == 0 {
+		return 0
+	}
+
+	timeout := config.Datadog.GetInt("check_timeout")
+	// the configuration keys are case insensitive
+	if t, found := config.Datadog.GetStringMapString("check_timeouts")[strings.ToLower(c.String())]; found {
+		override, err := strconv.Atoi(t)
+		if err != nil {
+			log.Warnf("Invalid timeout %q for check %s, using the default one: %s", t, c, err)
+		} else {
+			timeout = override
+		}
+	}
+
+	if timeout <= 0 {
+		return 0
+	}
+	return time.Duration(timeout) * time.Second
+}
+
 func shouldLog(id check.ID) (doLog bool, lastLog bool) {
 	checkStats.M.RLock()
 	defer checkStats.M.RUnlock()
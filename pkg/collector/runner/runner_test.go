@@ -18,9 +18,15 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+func init() {
+	// the runner resolves the hostname for the status service check
+	config.SetDetectedFeatures(config.FeatureMap{})
+}
+
 // FIXTURE
 type TestCheck struct {
 	check.StubCheck
@@ -202,3 +208,98 @@ func TestGetCheckStats(t *testing.T) {
 	require.True(t, m["StatsCheck"] != nil, "should be a StatsCheck map")
 	require.True(t, m["StatsCheck"]["StatsCheck:99"] != nil, "should be a StatsCheck:99 check")
 }
+
+type HangingCheck struct {
+	TestCheck
+	release    chan struct{}
+	stopCalled bool
+}
+
+func (hc *HangingCheck) Run() error {
+	<-hc.release
+	return hc.TestCheck.Run()
+}
+func (hc *HangingCheck) Stop() {
+	hc.TestCheck.Lock()
+	defer hc.TestCheck.Unlock()
+	hc.stopCalled = true
+}
+func (hc *HangingCheck) stopped() bool {
+	hc.TestCheck.Lock()
+	defer hc.TestCheck.Unlock()
+	return hc.stopCalled
+}
+func (hc *HangingCheck) String() string { return "HangingCheck" }
+func (hc *HangingCheck) ID() check.ID   { return check.ID("HangingCheck:" + hc.id) }
+
+func TestGetCheckTimeout(t *testing.T) {
+	defer config.Datadog.SetDefault("check_timeout", 0)
+	defer config.Datadog.SetDefault("check_timeouts", map[string]string{})
+
+	c := newTestCheck(false, "1")
+	assert.Equal(t, time.Duration(0), getCheckTimeout(c))
+
+	config.Datadog.SetDefault("check_timeout", 30)
+	assert.Equal(t, 30*time.Second, getCheckTimeout(c))
+
+	config.Datadog.SetDefault("check_timeouts", map[string]interface{}{"TestCheck": 5, "other": 60})
+	assert.Equal(t, 5*time.Second, getCheckTimeout(c))
+
+	config.Datadog.SetDefault("check_timeouts", map[string]interface{}{"TestCheck": 0})
+	assert.Equal(t, time.Duration(0), getCheckTimeout(c))
+}
+
+func TestWorkTimeout(t *testing.T) {
+	config.Datadog.SetDefault("check_timeouts", map[string]interface{}{"HangingCheck": 1})
+	defer config.Datadog.SetDefault("check_timeouts", map[string]string{})
+	// a single worker, so that the other check can only run once it's freed
+	config.Datadog.Set("check_runners", 1)
+	defer config.Datadog.Set("check_runners", int64(4))
+
+	r := NewRunner()
+	defer r.Stop()
+	s := scheduler.NewScheduler(r.GetChan())
+	r.SetScheduler(s)
+
+	hanging := &HangingCheck{TestCheck: *newTestCheck(false, "1"), release: make(chan struct{})}
+	defer RemoveCheckStats(hanging.ID())
+	// the check is entered so that the runner publishes its stats, its queue
+	// doesn't send it again while it's running or suspended
+	require.NoError(t, s.Enter(hanging))
+	r.pending <- hanging
+
+	// the worker is freed once the check times out
+	c := newTestCheck(false, "2")
+	r.pending <- c
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "Check hasn't run 2 seconds after being scheduled")
+	}
+
+	stats := GetCheckStats()["HangingCheck"][hanging.ID()]
+	require.NotNil(t, stats)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+	assert.Equal(t, uint64(1), stats.TotalErrors)
+	assert.Equal(t, "check run timed out after 1s", stats.LastError)
+
+	// the check isn't run again nor queued while its previous run is pending
+	r.m.Lock()
+	_, running := r.runningChecks[hanging.ID()]
+	r.m.Unlock()
+	assert.True(t, running)
+	require.Eventually(t, hanging.stopped, time.Second, 10*time.Millisecond)
+	assert.True(t, s.IsCheckSuspended(hanging.ID()))
+
+	// once the run completes, the check can be scheduled again
+	require.NoError(t, s.Cancel(hanging.ID()))
+	close(hanging.release)
+	require.Eventually(t, func() bool {
+		r.m.Lock()
+		defer r.m.Unlock()
+		_, running := r.runningChecks[hanging.ID()]
+		return !running
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, hanging.HasRun())
+	assert.False(t, s.IsCheckSuspended(hanging.ID()))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
+	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -46,7 +47,8 @@ func init() {
 
 // CheckScheduler is the check scheduler
 type CheckScheduler struct {
-	configToChecks map[string][]check.ID // cache the ID of checks we load for each config
+	configToChecks map[string][]check.ID            // cache the ID of checks we load for each config
+	schedules      map[check.ID]*scheduler.Schedule // scheduling options of the checks that have some
 	loaders        []check.Loader
 	collector      *Collector
 	m              sync.RWMutex
@@ -57,6 +59,7 @@ func InitCheckScheduler(collector *Collector) *CheckScheduler {
 	checkScheduler = &CheckScheduler{
 		collector:      collector,
 		configToChecks: make(map[string][]check.ID),
+		schedules:      make(map[check.ID]*scheduler.Schedule),
 		loaders:        make([]check.Loader, 0, len(loaders.LoaderCatalog())),
 	}
 	// add the check loaders
@@ -69,14 +72,17 @@ func InitCheckScheduler(collector *Collector) *CheckScheduler {
 
 // Schedule schedules configs to checks
 func (s *CheckScheduler) Schedule(configs []integration.Config) {
-	checks := s.GetChecksFromConfigs(configs, true)
+	checks, schedules := s.getChecksFromConfigs(configs, true)
 	for _, c := range checks {
-		_, err := s.collector.RunCheck(c)
+		schedule := schedules[c.ID()]
+
+		_, err := s.collector.RunCheckWithSchedule(c, schedule)
 		if err != nil {
 			log.Errorf("Unable to run Check %s: %v", c, err)
 			errorStats.setRunError(c.ID(), err.Error())
 			continue
 		}
+		s.setSchedule(c.ID(), schedule)
 	}
 }
 
@@ -100,6 +106,9 @@ func (s *CheckScheduler) Unschedule(configs []integration.Config) {
 				errorStats.setRunError(id, err.Error())
 			} else {
 				stopped[id] = struct{}{}
+				s.m.Lock()
+				delete(s.schedules, id)
+				s.m.Unlock()
 			}
 		}
 
@@ -139,9 +148,11 @@ func (s *CheckScheduler) AddLoader(loader check.Loader) {
 }
 
 // getChecks takes a check configuration and returns a slice of Check instances
-// along with any error it might happen during the process
-func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, error) {
+// along with any error it might happen during the process. The scheduling
+// options of the loaded checks are added to `schedules`.
+func (s *CheckScheduler) getChecks(config integration.Config, schedules map[check.ID]*scheduler.Schedule) ([]check.Check, error) {
 	checks := []check.Check{}
+	scheduleErrors := []string{}
 	numLoaders := len(s.loaders)
 
 	initConfig := commonInitConfig{}
@@ -165,6 +176,13 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 		if instanceConfig.LoaderName != "" {
 			selectedInstanceLoader = instanceConfig.LoaderName
 		}
+		schedule, err := scheduler.ParseSchedule(instance)
+		if err != nil {
+			log.Errorf("Unable to parse the scheduling options of an instance of check `%s`: %v", config.Name, err)
+			scheduleErrors = append(scheduleErrors, err.Error())
+			continue
+		}
+
 		if selectedInstanceLoader != "" {
 			log.Debugf("Loading check instance for check '%s' using loader %s (init_config loader: %s, instance loader: %s)", config.Name, selectedInstanceLoader, initConfig.LoaderName, instanceConfig.LoaderName)
 		} else {
@@ -182,6 +200,9 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 				log.Debugf("%v: successfully loaded check '%s'", loader, config.Name)
 				errorStats.removeLoaderErrors(config.Name)
 				checks = append(checks, c)
+				if schedule != nil {
+					schedules[c.ID()] = schedule
+				}
 				break
 			} else if c != nil && check.IsJMXInstance(config.Name, instance, config.InitConfig) {
 				// JMXfetch is more permissive than the agent regarding instance configuration. It
@@ -190,6 +211,9 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 				log.Debugf("%v: loading issue for JMX check '%s', the agent will still attempt to schedule it", loader, config.Name)
 				errorStats.setLoaderError(config.Name, fmt.Sprintf("%v", loader), err.Error())
 				checks = append(checks, c)
+				if schedule != nil {
+					schedules[c.ID()] = schedule
+				}
 				break
 			} else {
 				errorStats.setLoaderError(config.Name, fmt.Sprintf("%v", loader), err.Error())
@@ -202,6 +226,12 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 		}
 	}
 
+	// report the instances dropped because of their scheduling options after
+	// the loop, so a successfully loaded instance doesn't clear the error
+	if len(scheduleErrors) > 0 {
+		errorStats.setLoaderError(config.Name, "scheduler", strings.Join(scheduleErrors, "; "))
+	}
+
 	if len(checks) == 0 {
 		return checks, fmt.Errorf("unable to load any check from config '%s'", config.Name)
 	}
@@ -209,6 +239,16 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 	return checks, nil
 }
 
+// setSchedule keeps track of the scheduling options of a check, if it has some
+func (s *CheckScheduler) setSchedule(id check.ID, schedule *scheduler.Schedule) {
+	if schedule == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.schedules[id] = schedule
+}
+
 // GetChecksByNameForConfigs returns checks matching name for passed in configs
 func GetChecksByNameForConfigs(checkName string, configs []integration.Config) []check.Check {
 	var checks []check.Check
@@ -229,10 +269,18 @@ func GetChecksByNameForConfigs(checkName string, configs []integration.Config) [
 // GetChecksFromConfigs gets all the check instances for given configurations
 // optionally can populate the configToChecks cache
 func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, populateCache bool) []check.Check {
+	checks, _ := s.getChecksFromConfigs(configs, populateCache)
+	return checks
+}
+
+// getChecksFromConfigs gets all the check instances for given configurations,
+// along with the scheduling options of the checks that have some
+func (s *CheckScheduler) getChecksFromConfigs(configs []integration.Config, populateCache bool) ([]check.Check, map[check.ID]*scheduler.Schedule) {
 	s.m.Lock()
 	defer s.m.Unlock()
 
 	var allChecks []check.Check
+	schedules := make(map[check.ID]*scheduler.Schedule)
 	for _, config := range configs {
 		if !config.IsCheckConfig() {
 			// skip non check configs.
@@ -243,7 +291,7 @@ func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, popu
 			continue
 		}
 		configDigest := config.Digest()
-		checks, err := s.getChecks(config)
+		checks, err := s.getChecks(config, schedules)
 		if err != nil {
 			log.Errorf("Unable to load the check: %v", err)
 			continue
@@ -257,7 +305,7 @@ func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, popu
 		}
 	}
 
-	return allChecks
+	return allChecks, schedules
 }
 
 // GetLoaderErrors returns the check loader errors
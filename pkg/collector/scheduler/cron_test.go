@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, invalid := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every 5m",
+	} {
+		_, err := parseCron(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	spec, err := parseCron("*/15 2,4-6 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1|1<<15|1<<30|1<<45), spec.minute)
+	assert.Equal(t, uint64(1<<2|1<<4|1<<5|1<<6), spec.hour)
+
+	spec, err = parseCron("5/20 * * * 7")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1<<5|1<<25|1<<45), spec.minute)
+	assert.Equal(t, uint64(1|1<<7), spec.dow)
+}
+
+func TestCronNext(t *testing.T) {
+	// Friday
+	from := time.Date(2021, 1, 15, 10, 30, 20, 0, time.UTC)
+
+	for _, tt := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2021, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2021, 1, 15, 10, 40, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2021, 1, 16, 2, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2021, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 3 * * 1-5", time.Date(2021, 1, 18, 3, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 0 20 * 0", time.Date(2021, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			spec, err := parseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, spec.next(from))
+		})
+	}
+}
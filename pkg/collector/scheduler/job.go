@@ -68,17 +68,20 @@ type jobQueue struct {
 	schedulingBucketIdx uint
 	running             bool
 	health              *health.Handle
-	mu                  sync.RWMutex // to protect critical sections in struct's fields
+	cancelDelayed       chan bool      // to cancel the delayed enqueues when the queue stops
+	wgDelayed           sync.WaitGroup // to track the exit of the delayed enqueue goroutines
+	mu                  sync.RWMutex   // to protect critical sections in struct's fields
 }
 
 // newJobQueue creates a new jobQueue instance
 func newJobQueue(interval time.Duration) *jobQueue {
 	jq := &jobQueue{
-		interval:     interval,
-		stop:         make(chan bool),
-		stopped:      make(chan bool),
-		health:       health.RegisterLiveness("collector-queue"),
-		bucketTicker: time.NewTicker(time.Second),
+		interval:      interval,
+		stop:          make(chan bool),
+		stopped:       make(chan bool),
+		health:        health.RegisterLiveness("collector-queue"),
+		bucketTicker:  time.NewTicker(time.Second),
+		cancelDelayed: make(chan bool),
 	}
 
 	var nb int
@@ -174,7 +177,7 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 	select {
 	case <-jq.stop:
-		jq.health.Deregister() //nolint:errcheck
+		jq.shutdown()
 		return false
 	case t := <-jq.bucketTicker.C:
 		log.Tracef("Bucket ticked... current index: %v", jq.currentBucketIdx)
@@ -195,18 +198,27 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 		log.Tracef("Jobs in bucket: %v", jobs)
 
+		due := make([]check.Check, 0, len(jobs))
 		for _, check := range jobs {
-			if !s.IsCheckScheduled(check.ID()) {
+			schedule, found := s.getSchedule(check.ID())
+			if !found {
 				continue
 			}
-
-			select {
-			// blocking, we'll be here as long as it takes
-			case s.checksPipe <- check:
-			case <-jq.stop:
-				jq.health.Deregister() //nolint:errcheck
-				return false
+			if schedule.inMaintenance(t) {
+				log.Tracef("Check %s is in a maintenance window, skipping it", check.ID())
+				continue
+			}
+			if delay := schedule.randomDelay(jq.interval); delay > 0 {
+				jq.enqueueDelayed(s, check, delay)
+				continue
 			}
+			due = append(due, check)
+		}
+
+		// blocking, we'll be here as long as it takes
+		if !s.enqueueAll(due, jq.stop) {
+			jq.shutdown()
+			return false
 		}
 		jq.mu.Lock()
 		jq.currentBucketIdx = (jq.currentBucketIdx + 1) % uint(len(jq.buckets))
@@ -217,3 +229,84 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 	return true
 }
+
+// enqueueDelayed enqueues a check to the execution pipeline after the given delay.
+// Only called from the queue goroutine, so that it can't race with shutdown.
+func (jq *jobQueue) enqueueDelayed(s *Scheduler, check check.Check, delay time.Duration) {
+	jq.wgDelayed.Add(1)
+	go func() {
+		defer jq.wgDelayed.Done()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-jq.cancelDelayed:
+			return
+		}
+
+		if !s.IsCheckScheduled(check.ID()) {
+			return
+		}
+		s.enqueue(check, jq.cancelDelayed)
+	}()
+}
+
+// shutdown cancels the pending delayed enqueues and deregisters the queue health
+func (jq *jobQueue) shutdown() {
+	close(jq.cancelDelayed)
+	jq.wgDelayed.Wait()
+	jq.health.Deregister() //nolint:errcheck
+}
+
+// cronJob sends a check to the execution pipeline at every time matching
+// its cron schedule, in its own goroutine.
+type cronJob struct {
+	check    check.Check
+	schedule *Schedule
+	stop     chan bool // to stop this job
+	stopped  chan bool // closed when this job has stopped
+}
+
+func newCronJob(c check.Check, schedule *Schedule) *cronJob {
+	return &cronJob{
+		check:    c,
+		schedule: schedule,
+		stop:     make(chan bool),
+		stopped:  make(chan bool),
+	}
+}
+
+// run schedules the check until the job is stopped.
+// Not blocking, runs in a new goroutine.
+func (j *cronJob) run(s *Scheduler) {
+	go func() {
+		defer close(j.stopped)
+		for {
+			next := j.schedule.cron.next(time.Now())
+			if next.IsZero() {
+				log.Errorf("The schedule of check %s never matches, it won't run", j.check.ID())
+				<-j.stop
+				return
+			}
+			next = next.Add(j.schedule.randomDelay(0))
+			log.Debugf("Next run of check %s scheduled at %s", j.check.ID(), next)
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-j.stop:
+				timer.Stop()
+				return
+			case t := <-timer.C:
+				if j.schedule.inMaintenance(t) {
+					log.Debugf("Check %s is in a maintenance window, skipping it", j.check.ID())
+					continue
+				}
+				// blocking, we'll be here as long as it takes
+				if !s.enqueue(j.check, j.stop) {
+					return
+				}
+			}
+		}
+	}()
+}
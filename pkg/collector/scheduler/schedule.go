@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// scheduleConfig holds the scheduling options of a check instance
+type scheduleConfig struct {
+	Schedule           string                    `yaml:"schedule"`
+	Jitter             int                       `yaml:"jitter"` // in seconds
+	MaintenanceWindows []maintenanceWindowConfig `yaml:"maintenance_windows"`
+}
+
+type maintenanceWindowConfig struct {
+	Schedule string `yaml:"schedule"`
+	Duration int    `yaml:"duration"` // in seconds
+}
+
+// maintenanceWindow is a recurring period during which a check doesn't run.
+// It opens at every time matching its cron expression, for the given duration.
+type maintenanceWindow struct {
+	start    *cronSpec
+	duration time.Duration
+}
+
+// Schedule holds the optional scheduling options of a check: a cron expression
+// replacing its collection interval, a random delay applied to every run, and
+// the maintenance windows during which it is paused.
+type Schedule struct {
+	cron               *cronSpec
+	jitter             time.Duration
+	maintenanceWindows []maintenanceWindow
+}
+
+// ParseSchedule returns the scheduling options of a check instance, or nil if
+// the instance doesn't set any.
+func ParseSchedule(instance integration.Data) (*Schedule, error) {
+	cfg := scheduleConfig{}
+	if err := yaml.Unmarshal(instance, &cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Schedule == "" && cfg.Jitter == 0 && len(cfg.MaintenanceWindows) == 0 {
+		return nil, nil
+	}
+
+	s := &Schedule{}
+	if cfg.Schedule != "" {
+		cron, err := parseCron(cfg.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule: %s", err)
+		}
+		s.cron = cron
+	}
+
+	if cfg.Jitter < 0 {
+		return nil, fmt.Errorf("invalid jitter %d, must be positive", cfg.Jitter)
+	}
+	s.jitter = time.Duration(cfg.Jitter) * time.Second
+
+	for _, w := range cfg.MaintenanceWindows {
+		start, err := parseCron(w.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window: %s", err)
+		}
+		if w.Duration <= 0 {
+			return nil, fmt.Errorf("invalid maintenance window duration %d, must be positive", w.Duration)
+		}
+		s.maintenanceWindows = append(s.maintenanceWindows, maintenanceWindow{
+			start:    start,
+			duration: time.Duration(w.Duration) * time.Second,
+		})
+	}
+
+	return s, nil
+}
+
+// inMaintenance returns whether t is within one of the maintenance windows
+func (s *Schedule) inMaintenance(t time.Time) bool {
+	if s == nil {
+		return false
+	}
+	for _, w := range s.maintenanceWindows {
+		// the window is open if it started less than its duration ago
+		start := w.start.next(t.Add(-w.duration))
+		if !start.IsZero() && !start.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomDelay returns the delay to apply to a run of the check, up to the
+// jitter and strictly lower than max if max is not zero
+func (s *Schedule) randomDelay(max time.Duration) time.Duration {
+	if s == nil {
+		return 0
+	}
+	jitter := s.jitter
+	if max > 0 && jitter > max {
+		jitter = max
+	}
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
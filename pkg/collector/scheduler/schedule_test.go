@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseSchedule(t *testing.T) {
+	s, err := ParseSchedule(integration.Data("host: localhost\nmin_collection_interval: 30"))
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = ParseSchedule(integration.Data(`
+schedule: "0 2 * * *"
+jitter: 60
+maintenance_windows:
+  - schedule: "0 8 * * 1-5"
+    duration: 3600
+`))
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.NotNil(t, s.cron)
+	assert.Equal(t, time.Minute, s.jitter)
+	require.Len(t, s.maintenanceWindows, 1)
+	assert.Equal(t, time.Hour, s.maintenanceWindows[0].duration)
+
+	for _, invalid := range []string{
+		`schedule: "0 25 * * *"`,
+		`jitter: -1`,
+		"maintenance_windows:\n  - schedule: \"0 8 * * *\"",
+		"maintenance_windows:\n  - schedule: \"daily\"\n    duration: 60",
+		`jitter: [1]`,
+	} {
+		_, err := ParseSchedule(integration.Data(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestInMaintenance(t *testing.T) {
+	s, err := ParseSchedule(integration.Data(`
+maintenance_windows:
+  - schedule: "0 8 * * 1-5"
+    duration: 3600
+  - schedule: "30 23 * * *"
+    duration: 3600
+`))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		t        time.Time
+		expected bool
+	}{
+		{time.Date(2021, 1, 15, 7, 59, 59, 0, time.UTC), false},
+		{time.Date(2021, 1, 15, 8, 0, 0, 0, time.UTC), true},
+		{time.Date(2021, 1, 15, 8, 59, 59, 0, time.UTC), true},
+		{time.Date(2021, 1, 15, 9, 0, 0, 0, time.UTC), false},
+		// Saturday
+		{time.Date(2021, 1, 16, 8, 30, 0, 0, time.UTC), false},
+		// windows spanning midnight
+		{time.Date(2021, 1, 16, 23, 45, 0, 0, time.UTC), true},
+		{time.Date(2021, 1, 17, 0, 15, 0, 0, time.UTC), true},
+		{time.Date(2021, 1, 17, 0, 30, 0, 0, time.UTC), false},
+	} {
+		assert.Equal(t, tt.expected, s.inMaintenance(tt.t), tt.t.String())
+	}
+
+	var noSchedule *Schedule
+	assert.False(t, noSchedule.inMaintenance(time.Now()))
+}
+
+func TestRandomDelay(t *testing.T) {
+	var noSchedule *Schedule
+	assert.Zero(t, noSchedule.randomDelay(0))
+
+	s := &Schedule{jitter: time.Minute}
+	for i := 0; i < 100; i++ {
+		delay := s.randomDelay(0)
+		assert.True(t, delay >= 0 && delay < time.Minute, delay)
+		delay = s.randomDelay(10 * time.Second)
+		assert.True(t, delay >= 0 && delay < 10*time.Second, delay)
+	}
+}
@@ -39,6 +39,7 @@ func init() {
 // Scheduler keeps things rolling.
 // More docs to come...
 type Scheduler struct {
+	queueDepth       int64                       // Number of checks waiting to be sent to the pipe, first for 64-bit atomic alignment
 	running          uint32                      // Flag to see if the scheduler is running
 	checksPipe       chan<- check.Check          // The pipe the Runner pops the checks from, initially set to nil
 	done             chan bool                   // Guard for the main loop
@@ -46,6 +47,9 @@ type Scheduler struct {
 	started          chan bool                   // Used to internally communicate the queues are up
 	jobQueues        map[time.Duration]*jobQueue // We have one scheduling queue for every interval
 	checkToQueue     map[check.ID]*jobQueue      // Keep track of what is the queue for any Check
+	cronJobs         map[check.ID]*cronJob       // The jobs of the Checks running on a cron schedule
+	schedules        map[check.ID]*Schedule      // The scheduling options of the Checks that have some
+	suspended        map[check.ID]struct{}       // The Checks that must not be sent to the pipe for now
 	tlmTrackedChecks map[check.ID]string         // Keep track of the checks that are tracked with telemetry
 	mu               sync.Mutex                  // To protect critical sections in struct's fields
 
@@ -62,6 +66,9 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		started:          make(chan bool),
 		jobQueues:        make(map[time.Duration]*jobQueue),
 		checkToQueue:     make(map[check.ID]*jobQueue),
+		cronJobs:         make(map[check.ID]*cronJob),
+		schedules:        make(map[check.ID]*Schedule),
+		suspended:        make(map[check.ID]struct{}),
 		tlmTrackedChecks: make(map[check.ID]string),
 		running:          0,
 		cancelOneTime:    make(chan bool),
@@ -72,12 +79,23 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 // Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value.
 // If the interval is 0, the check is supposed to run only once.
 func (s *Scheduler) Enter(check check.Check) error {
+	return s.EnterWithSchedule(check, nil)
+}
+
+// EnterWithSchedule schedules a `Check` like `Enter` does, applying the given
+// scheduling options if they're not nil: when they define a cron schedule, it
+// replaces the `Check.Interval()` value.
+func (s *Scheduler) EnterWithSchedule(check check.Check, schedule *Schedule) error {
 	// enqueue immediately if this is a one-time schedule
 	if check.Interval() == 0 {
 		s.enqueueOnce(check)
 		return nil
 	}
 
+	if schedule != nil && schedule.cron != nil {
+		return s.enterCronJob(check, schedule)
+	}
+
 	if check.Interval() < minAllowedInterval {
 		return fmt.Errorf("Schedule interval must be greater than %v or 0", minAllowedInterval)
 	}
@@ -88,6 +106,9 @@ func (s *Scheduler) Enter(check check.Check) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if schedule != nil {
+		s.schedules[check.ID()] = schedule
+	}
 	if _, ok := s.jobQueues[check.Interval()]; !ok {
 		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
 		s.startQueue(s.jobQueues[check.Interval()])
@@ -100,6 +121,32 @@ func (s *Scheduler) Enter(check check.Check) error {
 	// map each check to the Job Queue it was assigned to
 	s.checkToQueue[check.ID()] = s.jobQueues[check.Interval()]
 
+	s.trackEnteredCheck(check)
+	return nil
+}
+
+// enterCronJob schedules a `Check` on its cron schedule, in its own job
+func (s *Scheduler) enterCronJob(check check.Check, schedule *Schedule) error {
+	log.Infof("Scheduling check %v on a cron schedule", check)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, found := s.cronJobs[check.ID()]; found {
+		return fmt.Errorf("check %s is already scheduled", check.ID())
+	}
+
+	job := newCronJob(check, schedule)
+	s.cronJobs[check.ID()] = job
+	s.schedules[check.ID()] = schedule
+	job.run(s)
+
+	s.trackEnteredCheck(check)
+	return nil
+}
+
+// trackEnteredCheck updates the telemetry and expvars once a check is entered
+func (s *Scheduler) trackEnteredCheck(check check.Check) {
 	schedulerChecksEntered.Add(1)
 	if check.IsTelemetryEnabled() {
 		checkName := check.String()
@@ -107,7 +154,6 @@ func (s *Scheduler) Enter(check check.Check) error {
 		tlmChecksEntered.Inc(checkName)
 	}
 	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
-	return nil
 }
 
 // Cancel remove a Check from the scheduled queue. If the check is not
@@ -118,16 +164,20 @@ func (s *Scheduler) Cancel(id check.ID) error {
 
 	log.Infof("Unscheduling check %s", string(id))
 
-	if _, ok := s.checkToQueue[id]; !ok {
+	if job, ok := s.cronJobs[id]; ok {
+		close(job.stop)
+		delete(s.cronJobs, id)
+	} else if _, ok := s.checkToQueue[id]; ok {
+		// remove it from the queue
+		err := s.checkToQueue[id].removeJob(id)
+		if err != nil {
+			return fmt.Errorf("unable to remove the Job from the queue: %s", err)
+		}
+		delete(s.checkToQueue, id)
+	} else {
 		return nil
 	}
-
-	// remove it from the queue
-	err := s.checkToQueue[id].removeJob(id)
-	if err != nil {
-		return fmt.Errorf("unable to remove the Job from the queue: %s", err)
-	}
-	delete(s.checkToQueue, id)
+	delete(s.schedules, id)
 
 	schedulerChecksEntered.Add(-1)
 	if checkName, ok := s.tlmTrackedChecks[id]; ok {
@@ -204,26 +254,85 @@ func (s *Scheduler) IsCheckScheduled(id check.ID) bool {
 	defer s.mu.Unlock()
 
 	_, found := s.checkToQueue[id]
+	if !found {
+		_, found = s.cronJobs[id]
+	}
 	return found
 }
 
+// SuspendCheck stops sending a check to the pipe until ResumeCheck is called,
+// its runs being skipped in the meantime
+func (s *Scheduler) SuspendCheck(id check.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.suspended[id] = struct{}{}
+}
+
+// ResumeCheck sends a check suspended by SuspendCheck to the pipe again
+func (s *Scheduler) ResumeCheck(id check.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.suspended, id)
+}
+
+// IsCheckSuspended returns whether a check is suspended
+func (s *Scheduler) IsCheckSuspended(id check.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.suspended[id]
+	return found
+}
+
+// getSchedule returns the scheduling options of a check, nil if it has none,
+// and whether the check is in the schedule
+func (s *Scheduler) getSchedule(id check.ID) (*Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, found := s.checkToQueue[id]
+	if !found {
+		_, found = s.cronJobs[id]
+	}
+	return s.schedules[id], found
+}
+
 // stopQueues shuts down the timers for each active queue
 // Blocks until all the queues have fully stopped
 func (s *Scheduler) stopQueues() {
+	// The queues and jobs are waited for without holding the lock, as their
+	// goroutines take it to check whether a check is still scheduled
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	log.Debugf("Stopping %v queue(s)", len(s.jobQueues))
+	queues := make([]*jobQueue, 0, len(s.jobQueues))
 	for _, q := range s.jobQueues {
 		// check that the queue is actually running or this blocks
 		// while posting to the channel
 		if q.running {
-			q.stop <- true
-			<-q.stopped
-			log.Debugf("Stopped queue %v", q.interval)
+			queues = append(queues, q)
 			q.running = false
 		}
 	}
+	jobs := make([]*cronJob, 0, len(s.cronJobs))
+	for id, job := range s.cronJobs {
+		jobs = append(jobs, job)
+		delete(s.cronJobs, id)
+	}
+	s.mu.Unlock()
+
+	log.Debugf("Stopping %v queue(s)", len(queues))
+	for _, q := range queues {
+		q.stop <- true
+		<-q.stopped
+		log.Debugf("Stopped queue %v", q.interval)
+	}
+
+	log.Debugf("Stopping %v cron job(s)", len(jobs))
+	for _, job := range jobs {
+		close(job.stop)
+		<-job.stopped
+	}
 }
 
 // startQueues loads the timer for each queue
@@ -254,15 +363,56 @@ func (s *Scheduler) enqueueOnce(check check.Check) {
 
 	go func(cancelOneTime <-chan bool) {
 		defer s.wgOneTime.Done()
-		select {
-		case s.checksPipe <- check:
-		case <-cancelOneTime:
-		}
+		s.enqueue(check, cancelOneTime)
 	}(s.cancelOneTime)
 
 	schedulerChecksEntered.Add(1)
 }
 
+// enqueue sends a check to the checksPipe, blocking until a worker picks it up
+// or the cancel channel is signaled, in which case it returns false.
+// Suspended and cancelled checks are skipped.
+func (s *Scheduler) enqueue(c check.Check, cancel <-chan bool) bool {
+	return s.enqueueAll([]check.Check{c}, cancel)
+}
+
+// enqueueAll sends checks that are due to the checksPipe one after the other,
+// blocking until workers pick them all up or the cancel channel is signaled,
+// in which case it returns false. All the checks are counted in the queue depth
+// until a worker picks them up, not only the one being sent.
+func (s *Scheduler) enqueueAll(checks []check.Check, cancel <-chan bool) bool {
+	atomic.AddInt64(&s.queueDepth, int64(len(checks)))
+
+	for i, c := range checks {
+		if s.IsCheckSuspended(c.ID()) {
+			log.Debugf("Check %s is suspended, skipping it", c.ID())
+			atomic.AddInt64(&s.queueDepth, -1)
+			continue
+		}
+		// the check may have been cancelled while the previous ones were sent,
+		// one-time checks aren't tracked
+		if c.Interval() != 0 && !s.IsCheckScheduled(c.ID()) {
+			atomic.AddInt64(&s.queueDepth, -1)
+			continue
+		}
+
+		select {
+		case s.checksPipe <- c:
+			atomic.AddInt64(&s.queueDepth, -1)
+		case <-cancel:
+			atomic.AddInt64(&s.queueDepth, -int64(len(checks)-i))
+			return false
+		}
+	}
+	return true
+}
+
+// QueueDepth returns the number of checks that are due but haven't been picked
+// up by a worker yet
+func (s *Scheduler) QueueDepth() int64 {
+	return atomic.LoadInt64(&s.queueDepth)
+}
+
 // expQueues return a function to get the stats for the queues
 func expQueues(s *Scheduler) func() interface{} {
 	return func() interface{} {
@@ -196,3 +196,149 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+func TestEnterWithSchedule(t *testing.T) {
+	ch := make(chan check.Check)
+	stop := make(chan bool)
+	s := NewScheduler(ch)
+
+	// consume the enqueued checks
+	go consume(ch, stop)
+	defer func() {
+		stop <- true
+	}()
+
+	cron, err := ParseSchedule([]byte(`schedule: "@daily"`))
+	assert.Nil(t, err)
+	cronCheck := &TestJobCheck{TestCheck: TestCheck{intl: 15 * time.Second}, id: "cron"}
+	assert.Nil(t, s.EnterWithSchedule(cronCheck, cron))
+	// checks running on a cron schedule don't use the interval queues
+	assert.Len(t, s.jobQueues, 0)
+	assert.Len(t, s.cronJobs, 1)
+	assert.True(t, s.IsCheckScheduled(cronCheck.ID()))
+	assert.NotNil(t, s.EnterWithSchedule(cronCheck, cron))
+
+	jitter, err := ParseSchedule([]byte(`jitter: 5`))
+	assert.Nil(t, err)
+	jitterCheck := &TestJobCheck{TestCheck: TestCheck{intl: 15 * time.Second}, id: "jitter"}
+	assert.Nil(t, s.EnterWithSchedule(jitterCheck, jitter))
+	assert.Len(t, s.jobQueues, 1)
+	schedule, found := s.getSchedule(jitterCheck.ID())
+	assert.True(t, found)
+	assert.Equal(t, jitter, schedule)
+
+	s.Run()
+
+	assert.Nil(t, s.Cancel(cronCheck.ID()))
+	assert.False(t, s.IsCheckScheduled(cronCheck.ID()))
+	assert.Len(t, s.cronJobs, 0)
+	assert.Nil(t, s.Cancel(jitterCheck.ID()))
+	_, found = s.getSchedule(jitterCheck.ID())
+	assert.False(t, found)
+
+	assert.Nil(t, s.EnterWithSchedule(cronCheck, cron))
+	assert.Nil(t, s.Stop())
+	assert.Len(t, s.cronJobs, 0)
+}
+
+func TestMaintenanceWindowSkipsCheck(t *testing.T) {
+	ch := make(chan check.Check, 10)
+	s := NewScheduler(ch)
+
+	// a window that is always open
+	schedule, err := ParseSchedule([]byte("maintenance_windows:\n  - schedule: \"* * * * *\"\n    duration: 120"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.EnterWithSchedule(&TestJobCheck{TestCheck: TestCheck{intl: time.Second}, id: "paused"}, schedule))
+
+	jitter, err := ParseSchedule([]byte("jitter: 1"))
+	assert.Nil(t, err)
+	assert.Nil(t, s.EnterWithSchedule(&TestJobCheck{TestCheck: TestCheck{intl: time.Second}, id: "jitter"}, jitter))
+
+	s.Run()
+	time.Sleep(2500 * time.Millisecond)
+	assert.Nil(t, s.Stop())
+
+	assert.NotZero(t, len(ch))
+	for len(ch) > 0 {
+		assert.Equal(t, check.ID("jitter"), (<-ch).ID())
+	}
+}
+
+func TestSuspendCheck(t *testing.T) {
+	c := &TestCheck{}
+	ch := make(chan check.Check, 1)
+	s := NewScheduler(ch)
+
+	s.SuspendCheck(c.ID())
+	assert.True(t, s.enqueue(c, nil))
+	assert.Len(t, ch, 0)
+
+	s.ResumeCheck(c.ID())
+	assert.True(t, s.enqueue(c, nil))
+	assert.Len(t, ch, 1)
+}
+
+func TestQueueDepth(t *testing.T) {
+	ch := make(chan check.Check)
+	s := NewScheduler(ch)
+	checks := []check.Check{&TestCheck{}, &TestCheck{}, &TestCheck{}}
+
+	done := make(chan bool)
+	go func() {
+		done <- s.enqueueAll(checks, nil)
+	}()
+
+	// all the due checks are counted, not only the one being sent
+	assert.Eventually(t, func() bool { return s.QueueDepth() == 3 }, time.Second, 10*time.Millisecond)
+	<-ch
+	assert.Eventually(t, func() bool { return s.QueueDepth() == 2 }, time.Second, 10*time.Millisecond)
+	<-ch
+	<-ch
+	assert.True(t, <-done)
+	assert.Equal(t, int64(0), s.QueueDepth())
+
+	// the checks that weren't sent are uncounted on cancel
+	cancel := make(chan bool)
+	go func() {
+		done <- s.enqueueAll(checks, cancel)
+	}()
+	<-ch
+	close(cancel)
+	assert.False(t, <-done)
+	assert.Equal(t, int64(0), s.QueueDepth())
+}
+
+// blockingIDCheck blocks the callers of ID until released
+type blockingIDCheck struct {
+	TestCheck
+	release chan bool
+}
+
+func (c *blockingIDCheck) ID() check.ID {
+	<-c.release
+	return c.TestCheck.ID()
+}
+
+func TestStopWithPendingDelayedEnqueue(t *testing.T) {
+	s := getScheduler()
+	assert.Nil(t, s.Enter(&TestCheck{intl: 10 * time.Second}))
+
+	// a delayed enqueue that looks the check up while the queues are stopping
+	c := &blockingIDCheck{release: make(chan bool)}
+	s.jobQueues[10*time.Second].enqueueDelayed(s, c, 0)
+	s.Run()
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- s.Stop()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(c.release)
+
+	select {
+	case err := <-stopped:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the scheduler didn't stop")
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -114,3 +115,35 @@ func TestGetChecksFromConfigs(t *testing.T) {
 		"Loader: core, Check: check_c",
 	}, actualChecks)
 }
+
+func TestGetChecksFromConfigsSchedules(t *testing.T) {
+	s := CheckScheduler{
+		configToChecks: make(map[string][]check.ID),
+		schedules:      make(map[check.ID]*scheduler.Schedule),
+	}
+	s.AddLoader(&MockCoreLoader{})
+
+	conf := integration.Config{
+		Name: "check_scheduled",
+		Instances: []integration.Data{
+			integration.Data("{\"schedule\": \"*/5 * * * *\"}"),
+			integration.Data("{\"schedule\": \"not a cron\"}"),
+		},
+		InitConfig: integration.Data("{}"),
+	}
+
+	// looking up checks must not record their schedules
+	checks := s.GetChecksFromConfigs([]integration.Config{conf}, false)
+	assert.Len(t, checks, 1)
+	assert.Len(t, s.schedules, 0)
+
+	checks, schedules := s.getChecksFromConfigs([]integration.Config{conf}, true)
+	assert.Len(t, checks, 1)
+	assert.Len(t, schedules, 1)
+	assert.NotNil(t, schedules[checks[0].ID()])
+	assert.Len(t, s.schedules, 0)
+
+	// the instance with an invalid schedule is reported in the status
+	loaderErrors := GetLoaderErrors()
+	assert.Contains(t, loaderErrors["check_scheduled"], "scheduler")
+}
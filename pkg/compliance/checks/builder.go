@@ -580,6 +580,9 @@ func (b *builder) newCheck(meta *compliance.SuiteMeta, ruleScope compliance.Rule
 		description: rule.Description,
 		interval:    b.checkInterval,
 
+		remediation: rule.Remediation,
+		references:  rule.References,
+
 		suiteMeta: meta,
 
 		resourceHandler: handler,
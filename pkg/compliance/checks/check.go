@@ -29,6 +29,9 @@ type complianceCheck struct {
 	description string
 	interval    time.Duration
 
+	remediation string
+	references  []string
+
 	suiteMeta *compliance.SuiteMeta
 
 	scope           compliance.RuleScope
@@ -117,6 +120,8 @@ func (c *complianceCheck) Run() error {
 			ResourceType:     resource.Type,
 			Result:           result,
 			Data:             data,
+			Remediation:      c.remediation,
+			References:       c.references,
 		}
 
 		log.Debugf("%s: reporting [%s] [%s] [%s]", c.ruleID, e.Result, e.ResourceID, e.ResourceType)
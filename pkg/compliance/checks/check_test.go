@@ -28,6 +28,8 @@ func TestCheckRun(t *testing.T) {
 	tests := []struct {
 		name         string
 		checkReports []*compliance.Report
+		remediation  string
+		references   []string
 		expectEvent  *event.Event
 		expectErr    error
 	}{
@@ -73,6 +75,31 @@ func TestCheckRun(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "failed check with remediation",
+			checkReports: []*compliance.Report{
+				{
+					Passed: false,
+					Data: event.Data{
+						"file.permissions": 0644,
+					},
+				},
+			},
+			remediation: "chmod 600 /etc/shadow",
+			references:  []string{"https://www.cisecurity.org/"},
+			expectEvent: &event.Event{
+				AgentRuleID:      ruleID,
+				AgentFrameworkID: frameworkID,
+				ResourceType:     resourceType,
+				ResourceID:       resourceID,
+				Result:           "failed",
+				Data: event.Data{
+					"file.permissions": 0644,
+				},
+				Remediation: "chmod 600 /etc/shadow",
+				References:  []string{"https://www.cisecurity.org/"},
+			},
+		},
 		{
 			name: "check error",
 			checkReports: []*compliance.Report{
@@ -113,6 +140,9 @@ func TestCheckRun(t *testing.T) {
 				checkable: checkable,
 				scope:     resourceType,
 
+				remediation: test.remediation,
+				references:  test.references,
+
 				suiteMeta: &compliance.SuiteMeta{Framework: frameworkID},
 			}
 
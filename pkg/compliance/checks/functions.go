@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+)
+
+var functionRegistry = make(map[compliance.ResourceKind]eval.FunctionMap)
+
+// RegisterFunction registers a function callable from the conditions of the
+// resources of the given kind, unless the resources define a function with the
+// same name. It must be called before the checks are built, from an init
+// function.
+func RegisterFunction(kind compliance.ResourceKind, name string, fn eval.Function) {
+	functions, found := functionRegistry[kind]
+	if !found {
+		functions = make(eval.FunctionMap)
+		functionRegistry[kind] = functions
+	}
+	functions[name] = fn
+}
+
+// withFunctions returns the instance with the functions registered for the
+// kind of the resource
+func (c *resourceCheck) withFunctions(instance eval.Instance) eval.Instance {
+	if len(c.functions) == 0 {
+		return instance
+	}
+
+	functions := make(eval.FunctionMap, len(c.functions))
+	for name, fn := range c.functions {
+		if _, found := instance.Function(name); !found {
+			functions[name] = fn
+		}
+	}
+	return eval.ExtendInstance(instance, nil, functions)
+}
+
+// functionsIterator adds the functions registered for the kind of the
+// resource to the instances of an iterator
+type functionsIterator struct {
+	eval.Iterator
+	c *resourceCheck
+}
+
+func (it *functionsIterator) Next() (eval.Instance, error) {
+	instance, err := it.Iterator.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if ri, ok := instance.(resolvedInstance); ok {
+		return newResolvedInstance(it.c.withFunctions(ri), ri.ID(), ri.Type()), nil
+	}
+	return it.c.withFunctions(instance), nil
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestRegisterFunction(t *testing.T) {
+	defer delete(functionRegistry, compliance.KindSysctl)
+
+	// returns whether a range of ports, like `32768 60999`, contains a port
+	RegisterFunction(compliance.KindSysctl, "sysctl.inRange", func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, errors.New(`expecting a range and a value`)
+		}
+		var min, max int64
+		if _, err := fmt.Sscanf(args[0].(string), "%d %d", &min, &max); err != nil {
+			return nil, err
+		}
+		value := args[1].(int64)
+		return value >= min && value <= max, nil
+	})
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot("sysctl"))
+
+	resource := compliance.Resource{
+		Sysctl: &compliance.Sysctl{
+			Key: "net.ipv4.ip_local_port_range",
+		},
+		Condition: `!sysctl.inRange(sysctl.value, 8080) && sysctl.inRange(sysctl.value, 40000)`,
+	}
+
+	sysctlCheck, err := newResourceCheck(env, "rule-id", resource)
+	assert.NoError(t, err)
+
+	reports := sysctlCheck.check(env)
+	assert.Equal(t, &compliance.Report{
+		Passed: true,
+		Data: event.Data{
+			"sysctl.key":   "net.ipv4.ip_local_port_range",
+			"sysctl.value": "32768 60999",
+		},
+		Resource: compliance.ReportResource{
+			ID:   "net.ipv4.ip_local_port_range",
+			Type: "sysctl",
+		},
+	}, reports[0])
+
+	// the functions are only available to the resources of the kind
+	resource = compliance.Resource{
+		KernelModule: &compliance.KernelModule{
+			Name: "overlay",
+		},
+		Condition: `sysctl.inRange("1 2", 1)`,
+	}
+
+	env = &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot("kmod"))
+
+	kernelModuleCheck, err := newResourceCheck(env, "rule-id", resource)
+	assert.NoError(t, err)
+
+	reports = kernelModuleCheck.check(env)
+	assert.EqualError(t, reports[0].Error, `1:1: unknown function "sysctl.inRange()"`)
+}
+
+func TestRegisterFunctionIterator(t *testing.T) {
+	defer delete(functionRegistry, compliance.KindProcess)
+
+	RegisterFunction(compliance.KindProcess, "process.isRoot", func(instance eval.Instance, args ...interface{}) (interface{}, error) {
+		user, _ := instance.Var("process.user")
+		return user == "root", nil
+	})
+
+	check := &resourceCheck{
+		ruleID: "rule-id",
+		resource: compliance.Resource{
+			Condition: `none(process.isRoot())`,
+		},
+		resolve: func(_ context.Context, _ env.Env, _ string, _ compliance.Resource) (resolved, error) {
+			return newResolvedInstances([]resolvedInstance{
+				newResolvedInstance(eval.NewInstance(eval.VarMap{"process.user": "nobody"}, nil), "1", "process"),
+				newResolvedInstance(eval.NewInstance(eval.VarMap{"process.user": "root"}, nil), "2", "process"),
+			}), nil
+		},
+		functions: functionRegistry[compliance.KindProcess],
+	}
+
+	reports := check.check(&mocks.Env{})
+	assert.Len(t, reports, 1)
+	assert.NoError(t, reports[0].Error)
+	assert.False(t, reports[0].Passed)
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var kernelModuleReportedFields = []string{
+	compliance.KernelModuleFieldName,
+	compliance.KernelModuleFieldLoaded,
+	compliance.KernelModuleFieldDisabled,
+	compliance.KernelModuleFieldBlacklisted,
+}
+
+// modprobeConfigDirs are the directories of the modprobe configuration files,
+// see modprobe.d(5)
+var modprobeConfigDirs = []string{"/etc/modprobe.d", "/run/modprobe.d", "/usr/lib/modprobe.d", "/lib/modprobe.d"}
+
+func resolveKernelModule(_ context.Context, e env.Env, id string, res compliance.Resource) (resolved, error) {
+	if res.KernelModule == nil {
+		return nil, fmt.Errorf("%s: expecting kernel module resource in kernel module check", id)
+	}
+
+	// the names of the modules use underscores, while modprobe accepts dashes
+	name := strings.ReplaceAll(res.KernelModule.Name, "-", "_")
+	if name == "" {
+		return nil, fmt.Errorf("%s: kernel module name is missing", id)
+	}
+
+	loaded, err := isKernelModuleLoaded(e.NormalizeToHostRoot("/proc/modules"), name)
+	if err != nil {
+		return nil, wrapErrorWithID(id, err)
+	}
+
+	files, err := modprobeConfigFiles(e)
+	if err != nil {
+		return nil, wrapErrorWithID(id, err)
+	}
+
+	var disabled, blacklisted bool
+	for _, file := range files {
+		d, b, err := readModprobeConfig(file, name)
+		if err != nil {
+			log.Warnf("%s: failed to read %s: %v", id, file, err)
+			continue
+		}
+		disabled, blacklisted = disabled || d, blacklisted || b
+	}
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.KernelModuleFieldName:        name,
+			compliance.KernelModuleFieldLoaded:      loaded,
+			compliance.KernelModuleFieldDisabled:    disabled,
+			compliance.KernelModuleFieldBlacklisted: blacklisted,
+		},
+		nil,
+	)
+
+	return newResolvedInstance(instance, name, "kernel_module"), nil
+}
+
+// modprobeConfigFiles returns the modprobe configuration files in effect, a
+// file overriding the files with the same name in the directories of lower
+// priority, so that a vendor configuration can be masked from /etc
+func modprobeConfigFiles(e env.Env) ([]string, error) {
+	filesByName := make(map[string]string)
+	for _, dir := range modprobeConfigDirs {
+		files, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(dir), "*.conf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if _, found := filesByName[filepath.Base(file)]; !found {
+				filesByName[filepath.Base(file)] = file
+			}
+		}
+	}
+
+	names := make([]string, 0, len(filesByName))
+	for name := range filesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, filesByName[name])
+	}
+	return files, nil
+}
+
+// isKernelModuleLoaded returns whether the module is listed by /proc/modules
+func isKernelModuleLoaded(modulesPath, name string) (bool, error) {
+	f, err := os.Open(modulesPath)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 && fields[0] == name {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// readModprobeConfig returns whether a modprobe configuration file disables the
+// module, by installing it with `/bin/true` or `/bin/false`, and whether it
+// blacklists it
+func readModprobeConfig(configPath, name string) (disabled bool, blacklisted bool, err error) {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return false, false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.ReplaceAll(fields[1], "-", "_") != name {
+			continue
+		}
+
+		switch fields[0] {
+		case "install":
+			if len(fields) > 2 {
+				if cmd := path.Base(fields[2]); cmd == "true" || cmd == "false" {
+					disabled = true
+				}
+			}
+		case "blacklist":
+			blacklisted = true
+		}
+	}
+	return disabled, blacklisted, scanner.Err()
+}
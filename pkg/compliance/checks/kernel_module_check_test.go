@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestKernelModuleCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   string
+		expected event.Data
+	}{
+		{
+			name:   "disabled and blacklisted",
+			module: "cramfs",
+			expected: event.Data{
+				"kernelModule.name":        "cramfs",
+				"kernelModule.loaded":      false,
+				"kernelModule.disabled":    true,
+				"kernelModule.blacklisted": true,
+			},
+		},
+		{
+			name:   "disabled but loaded",
+			module: "usb-storage",
+			expected: event.Data{
+				"kernelModule.name":        "usb_storage",
+				"kernelModule.loaded":      true,
+				"kernelModule.disabled":    true,
+				"kernelModule.blacklisted": false,
+			},
+		},
+		{
+			name:   "loaded",
+			module: "overlay",
+			expected: event.Data{
+				"kernelModule.name":        "overlay",
+				"kernelModule.loaded":      true,
+				"kernelModule.disabled":    false,
+				"kernelModule.blacklisted": false,
+			},
+		},
+		{
+			name:   "vendor configuration overridden",
+			module: "floppy",
+			expected: event.Data{
+				"kernelModule.name":        "floppy",
+				"kernelModule.loaded":      false,
+				"kernelModule.disabled":    false,
+				"kernelModule.blacklisted": false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot("kmod"))
+
+			resource := compliance.Resource{
+				KernelModule: &compliance.KernelModule{
+					Name: test.module,
+				},
+				Condition: `!kernelModule.loaded && kernelModule.disabled`,
+			}
+
+			kernelModuleCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			reports := kernelModuleCheck.check(env)
+			assert.Equal(&compliance.Report{
+				Passed: test.expected["kernelModule.disabled"] == true && test.expected["kernelModule.loaded"] == false,
+				Data:   test.expected,
+				Resource: compliance.ReportResource{
+					ID:   test.expected["kernelModule.name"].(string),
+					Type: "kernel_module",
+				},
+			}, reports[0])
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+)
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldInstalled,
+	compliance.PackageFieldVersion,
+}
+
+// ErrPackageDatabaseNotFound is returned when no supported package database can be found
+var ErrPackageDatabaseNotFound = errors.New("no dpkg, apk or rpm package database found")
+
+const (
+	dpkgStatusPath    = "/var/lib/dpkg/status"
+	apkInstalledPath  = "/lib/apk/db/installed"
+	rpmSQLitePath     = "/var/lib/rpm/rpmdb.sqlite"
+	rpmBerkeleyDBPath = "/var/lib/rpm/Packages"
+)
+
+// packageDatabase describes how to find the installed version of a package in
+// the database of a package manager
+type packageDatabase struct {
+	path string
+	find func(f *os.File, name string) (string, bool, error)
+}
+
+var packageDatabases = []packageDatabase{
+	{path: dpkgStatusPath, find: findDpkgPackage},
+	{path: apkInstalledPath, find: findApkPackage},
+	// rpm 4.16 moved to SQLite but may leave the old Berkeley DB file behind
+	{path: rpmSQLitePath, find: findRpmSQLitePackage},
+	{path: rpmBerkeleyDBPath, find: findRpmBerkeleyDBPackage},
+}
+
+func resolvePackage(_ context.Context, e env.Env, id string, res compliance.Resource) (resolved, error) {
+	if res.Package == nil {
+		return nil, fmt.Errorf("%s: expecting package resource in package check", id)
+	}
+
+	name := res.Package.Name
+	if name == "" {
+		return nil, fmt.Errorf("%s: package name is missing", id)
+	}
+
+	for _, db := range packageDatabases {
+		f, err := os.Open(e.NormalizeToHostRoot(db.path))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, wrapErrorWithID(id, err)
+		}
+
+		version, installed, err := db.find(f, name)
+		f.Close()
+		if err != nil {
+			return nil, wrapErrorWithID(id, err)
+		}
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.PackageFieldName:      name,
+				compliance.PackageFieldInstalled: installed,
+				compliance.PackageFieldVersion:   version,
+			},
+			nil,
+		)
+
+		return newResolvedInstance(instance, name, "package"), nil
+	}
+
+	return nil, ErrPackageDatabaseNotFound
+}
+
+// scanStanzas calls fn with the fields of each stanza of a database made of
+// `key: value` lines separated by blank lines, until it returns true
+func scanStanzas(f *os.File, separator string, fn func(fields map[string]string) bool) error {
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	fields := make(map[string]string)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			if len(fields) > 0 && fn(fields) {
+				return nil
+			}
+			fields = make(map[string]string)
+			continue
+		}
+
+		// skip the continuation lines of multi-line values
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		if parts := strings.SplitN(line, separator, 2); len(parts) == 2 {
+			fields[parts[0]] = strings.TrimSpace(parts[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		fn(fields)
+	}
+	return nil
+}
+
+// findDpkgPackage looks for a package in the dpkg status file, the package
+// being installed when its status is `install ok installed`
+func findDpkgPackage(f *os.File, name string) (version string, installed bool, err error) {
+	err = scanStanzas(f, ":", func(fields map[string]string) bool {
+		if fields["Package"] != name {
+			return false
+		}
+		if statusFields := strings.Fields(fields["Status"]); len(statusFields) == 3 && statusFields[2] == "installed" {
+			version, installed = fields["Version"], true
+			return true
+		}
+		return false
+	})
+	return version, installed, err
+}
+
+// findApkPackage looks for a package in the apk database, which only lists
+// the installed packages
+func findApkPackage(f *os.File, name string) (version string, installed bool, err error) {
+	err = scanStanzas(f, ":", func(fields map[string]string) bool {
+		if fields["P"] != name {
+			return false
+		}
+		version, installed = fields["V"], true
+		return true
+	})
+	return version, installed, err
+}
@@ -0,0 +1,271 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPackageCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostRoot  string
+		pkg       string
+		condition string
+
+		expectReport *compliance.Report
+	}{
+		{
+			name:      "dpkg installed package",
+			hostRoot:  "package/dpkg",
+			pkg:       "openssh-server",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.installed": true,
+					"package.version":   "1:8.2p1-4ubuntu0.2",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "dpkg removed package",
+			hostRoot:  "package/dpkg",
+			pkg:       "telnetd",
+			condition: `!package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnetd",
+					"package.installed": false,
+					"package.version":   "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnetd",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "apk installed package",
+			hostRoot:  "package/apk",
+			pkg:       "openssh-server",
+			condition: `package.version == "8.4_p1-r3"`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.installed": true,
+					"package.version":   "8.4_p1-r3",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "apk missing package",
+			hostRoot:  "package/apk",
+			pkg:       "telnetd",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "telnetd",
+					"package.installed": false,
+					"package.version":   "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnetd",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm berkeley db installed package",
+			hostRoot:  "package/rpm-bdb",
+			pkg:       "bash",
+			condition: `package.version == "4.2.46-34.el7"`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "bash",
+					"package.installed": true,
+					"package.version":   "4.2.46-34.el7",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "bash",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm berkeley db package with epoch",
+			hostRoot:  "package/rpm-bdb",
+			pkg:       "openssl-libs",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssl-libs",
+					"package.installed": true,
+					"package.version":   "1:1.0.2k-19.el7",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssl-libs",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm berkeley db latest installed version",
+			hostRoot:  "package/rpm-bdb",
+			pkg:       "kernel",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "kernel",
+					"package.installed": true,
+					"package.version":   "3.10.0-1160.el7",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "kernel",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm berkeley db missing package",
+			hostRoot:  "package/rpm-bdb",
+			pkg:       "telnet-server",
+			condition: `!package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnet-server",
+					"package.installed": false,
+					"package.version":   "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm sqlite installed package",
+			hostRoot:  "package/rpm-sqlite",
+			pkg:       "bash",
+			condition: `package.version == "4.2.46-34.el7"`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "bash",
+					"package.installed": true,
+					"package.version":   "4.2.46-34.el7",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "bash",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm sqlite latest installed version",
+			hostRoot:  "package/rpm-sqlite",
+			pkg:       "kernel",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "kernel",
+					"package.installed": true,
+					"package.version":   "3.10.0-1160.el7",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "kernel",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "rpm sqlite missing package",
+			hostRoot:  "package/rpm-sqlite",
+			pkg:       "telnet-server",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "telnet-server",
+					"package.installed": false,
+					"package.version":   "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name:      "no package database",
+			hostRoot:  "package/none",
+			pkg:       "openssh-server",
+			condition: `package.installed`,
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Error:  ErrPackageDatabaseNotFound,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot(test.hostRoot))
+
+			resource := compliance.Resource{
+				Package: &compliance.Package{
+					Name: test.pkg,
+				},
+				Condition: test.condition,
+			}
+
+			packageCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			reports := packageCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
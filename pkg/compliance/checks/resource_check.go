@@ -63,7 +63,7 @@ func (ri *_resolvedInstance) Evaluate(conditionExpression *eval.IterableExpressi
 			return []*compliance.Report{compliance.BuildReportForError(err)}
 		}
 
-		useFallback, err := fallbackExpression.BoolEvaluate(ri.Instance)
+		useFallback, err := fallbackExpression.BoolEvaluate(c.withFunctions(ri.Instance))
 		if err != nil {
 			return []*compliance.Report{compliance.BuildReportForError(err)}
 		}
@@ -72,7 +72,7 @@ func (ri *_resolvedInstance) Evaluate(conditionExpression *eval.IterableExpressi
 		}
 	}
 
-	passed, err := conditionExpression.Evaluate(ri.Instance)
+	passed, err := conditionExpression.Evaluate(c.withFunctions(ri.Instance))
 	if err != nil {
 		return []*compliance.Report{compliance.BuildReportForError(err)}
 	}
@@ -98,7 +98,12 @@ func (ri *resolvedIterator) Evaluate(conditionExpression *eval.IterableExpressio
 		return []*compliance.Report{compliance.BuildReportForError(ErrResourceCannotUseFallback)}
 	}
 
-	results, err := conditionExpression.EvaluateIterator(ri.Iterator, globalInstance)
+	iterator := ri.Iterator
+	if len(c.functions) > 0 {
+		iterator = &functionsIterator{Iterator: iterator, c: c}
+	}
+
+	results, err := conditionExpression.EvaluateIterator(iterator, globalInstance)
 	if err != nil {
 		return []*compliance.Report{compliance.BuildReportForError(err)}
 	}
@@ -136,6 +141,7 @@ type resourceCheck struct {
 	fallback checkable
 
 	reportedFields []string
+	functions      eval.FunctionMap
 }
 
 func (c *resourceCheck) check(env env.Env) []*compliance.Report {
@@ -195,6 +201,7 @@ func newResourceCheck(env env.Env, ruleID string, resource compliance.Resource)
 		resolve:        resolve,
 		fallback:       fallback,
 		reportedFields: reportedFields,
+		functions:      functionRegistry[kind],
 	}, nil
 }
 
@@ -214,6 +221,14 @@ func resourceKindToResolverAndFields(kind compliance.ResourceKind) (resolveFunc,
 		return resolveDocker, dockerReportedFields, nil
 	case compliance.KindKubernetes:
 		return resolveKubeapiserver, kubeResourceReportedFields, nil
+	case compliance.KindSysctl:
+		return resolveSysctl, sysctlReportedFields, nil
+	case compliance.KindKernelModule:
+		return resolveKernelModule, kernelModuleReportedFields, nil
+	case compliance.KindSystemd:
+		return resolveSystemd, systemdReportedFields, nil
+	case compliance.KindPackage:
+		return resolvePackage, packageReportedFields, nil
 	default:
 		return nil, nil, ErrResourceKindNotSupported
 	}
@@ -0,0 +1,512 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// The rpm database stores a header blob per installed package, either in a
+// Berkeley DB hash file (up to RHEL 8 and Amazon Linux 2) or in a SQLite
+// database (from RHEL 9 and Amazon Linux 2023). Both formats are read directly,
+// as the rpm tools are usually not available to the agent.
+
+// rpm header tags, see rpmtag.h
+const (
+	rpmTagName        = 1000
+	rpmTagVersion     = 1001
+	rpmTagRelease     = 1002
+	rpmTagEpoch       = 1003
+	rpmTagInstallTime = 1008
+)
+
+// rpm header data types
+const (
+	rpmTypeInt32  = 4
+	rpmTypeString = 6
+)
+
+var errInvalidRpmDatabase = errors.New("invalid rpm database")
+
+// rpmPackage holds the fields of an rpm header needed by package checks
+type rpmPackage struct {
+	name        string
+	version     string
+	installTime int64
+}
+
+// findRpmBerkeleyDBPackage looks for a package in a Berkeley DB rpm database
+func findRpmBerkeleyDBPackage(f *os.File, name string) (version string, installed bool, err error) {
+	return findRpmPackage(name, func(fn func(header []byte) error) error {
+		return scanBerkeleyDBHash(f, fn)
+	})
+}
+
+// findRpmSQLitePackage looks for a package in a SQLite rpm database. The
+// changes still in its write-ahead log, if any, are not read.
+func findRpmSQLitePackage(f *os.File, name string) (version string, installed bool, err error) {
+	return findRpmPackage(name, func(fn func(header []byte) error) error {
+		return scanSQLiteTable(f, "Packages", func(record []interface{}) error {
+			if len(record) < 2 {
+				return errInvalidRpmDatabase
+			}
+			header, ok := record[1].([]byte)
+			if !ok {
+				return errInvalidRpmDatabase
+			}
+			return fn(header)
+		})
+	})
+}
+
+// findRpmPackage returns the version of the package with the given name, the
+// most recently installed one when several versions are installed, like kernels
+func findRpmPackage(name string, scan func(fn func(header []byte) error) error) (version string, installed bool, err error) {
+	var found *rpmPackage
+	err = scan(func(header []byte) error {
+		pkg, err := parseRpmHeader(header)
+		if err != nil {
+			return err
+		}
+		if pkg.name == name && (found == nil || pkg.installTime >= found.installTime) {
+			found = pkg
+		}
+		return nil
+	})
+	if err != nil || found == nil {
+		return "", false, err
+	}
+	return found.version, true, nil
+}
+
+// parseRpmHeader parses a header blob as stored in the rpm database, made of the
+// number of index entries and the size of the data store, followed by the index
+// entries and the data store, in big endian
+func parseRpmHeader(header []byte) (*rpmPackage, error) {
+	if len(header) < 8 {
+		return nil, errInvalidRpmDatabase
+	}
+	indexLength := int(binary.BigEndian.Uint32(header[0:4]))
+	dataLength := int(binary.BigEndian.Uint32(header[4:8]))
+	dataStart := 8 + 16*indexLength
+	if indexLength < 0 || dataLength < 0 || dataStart < 0 || dataStart+dataLength > len(header) {
+		return nil, errInvalidRpmDatabase
+	}
+	data := header[dataStart : dataStart+dataLength]
+
+	var epoch, version, release string
+	pkg := &rpmPackage{}
+	for i := 0; i < indexLength; i++ {
+		entry := header[8+16*i : 8+16*(i+1)]
+		tag := binary.BigEndian.Uint32(entry[0:4])
+		typ := binary.BigEndian.Uint32(entry[4:8])
+		offset := int(int32(binary.BigEndian.Uint32(entry[8:12])))
+		if offset < 0 || offset >= len(data) {
+			continue
+		}
+
+		switch {
+		case typ == rpmTypeString && (tag == rpmTagName || tag == rpmTagVersion || tag == rpmTagRelease):
+			end := bytes.IndexByte(data[offset:], 0)
+			if end < 0 {
+				return nil, errInvalidRpmDatabase
+			}
+			value := string(data[offset : offset+end])
+			switch tag {
+			case rpmTagName:
+				pkg.name = value
+			case rpmTagVersion:
+				version = value
+			case rpmTagRelease:
+				release = value
+			}
+		case typ == rpmTypeInt32 && (tag == rpmTagEpoch || tag == rpmTagInstallTime):
+			if offset+4 > len(data) {
+				return nil, errInvalidRpmDatabase
+			}
+			value := int32(binary.BigEndian.Uint32(data[offset : offset+4]))
+			if tag == rpmTagEpoch {
+				epoch = strconv.Itoa(int(value))
+			} else {
+				pkg.installTime = int64(uint32(value))
+			}
+		}
+	}
+
+	// the version is formatted like the dpkg ones, [epoch:]version-release
+	pkg.version = version
+	if release != "" {
+		pkg.version += "-" + release
+	}
+	if epoch != "" && epoch != "0" {
+		pkg.version = epoch + ":" + pkg.version
+	}
+	return pkg, nil
+}
+
+// Berkeley DB page types and item types, see db_page.h
+const (
+	bdbHashMagic          = 0x061561
+	bdbPageHeaderSize     = 26
+	bdbPageTypeHashOld    = 2
+	bdbPageTypeOverflow   = 7
+	bdbPageTypeHashMeta   = 8
+	bdbPageTypeHash       = 13
+	bdbItemTypeKeyData    = 1
+	bdbItemTypeOffPage    = 3
+	bdbOffPageItemSize    = 12
+	bdbMetaPageHeaderSize = 72
+)
+
+// scanBerkeleyDBHash calls fn with the values of a Berkeley DB hash database,
+// stored either on the hash pages themselves or on overflow pages when large.
+func scanBerkeleyDBHash(f io.ReaderAt, fn func(value []byte) error) error {
+	meta := make([]byte, bdbMetaPageHeaderSize)
+	if _, err := f.ReadAt(meta, 0); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidRpmDatabase, err)
+	}
+
+	// the database is stored in the byte order of the host that created it
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(meta[12:16]) != bdbHashMagic {
+		order = binary.BigEndian
+		if order.Uint32(meta[12:16]) != bdbHashMagic {
+			return fmt.Errorf("%w: not a Berkeley DB hash database", errInvalidRpmDatabase)
+		}
+	}
+	if meta[25] != bdbPageTypeHashMeta {
+		return fmt.Errorf("%w: unexpected metadata page type %d", errInvalidRpmDatabase, meta[25])
+	}
+	if meta[24] != 0 {
+		return fmt.Errorf("%w: encrypted databases are not supported", errInvalidRpmDatabase)
+	}
+	pageSize := order.Uint32(meta[20:24])
+	if pageSize < 512 || pageSize > 65536 || pageSize&(pageSize-1) != 0 {
+		return fmt.Errorf("%w: invalid page size %d", errInvalidRpmDatabase, pageSize)
+	}
+	lastPage := order.Uint32(meta[32:36])
+
+	page := make([]byte, pageSize)
+	for pageNo := uint32(1); pageNo <= lastPage; pageNo++ {
+		if _, err := f.ReadAt(page, int64(pageNo)*int64(pageSize)); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidRpmDatabase, err)
+		}
+		if pageType := page[25]; pageType != bdbPageTypeHash && pageType != bdbPageTypeHashOld {
+			continue
+		}
+
+		// the items of a hash page are pairs of keys and values, indexed by
+		// offsets following the page header. They are stored from the end of
+		// the page, so that an item ends where the previous one starts.
+		entries := int(order.Uint16(page[20:22]))
+		if bdbPageHeaderSize+2*entries > len(page) {
+			return fmt.Errorf("%w: invalid hash page %d", errInvalidRpmDatabase, pageNo)
+		}
+		for i := 1; i < entries; i += 2 {
+			offset := int(order.Uint16(page[bdbPageHeaderSize+2*i:]))
+			end := int(order.Uint16(page[bdbPageHeaderSize+2*(i-1):]))
+			if offset >= end || end > len(page) {
+				return fmt.Errorf("%w: invalid hash page %d", errInvalidRpmDatabase, pageNo)
+			}
+
+			var value []byte
+			switch page[offset] {
+			case bdbItemTypeKeyData:
+				value = page[offset+1 : end]
+			case bdbItemTypeOffPage:
+				if offset+bdbOffPageItemSize > end {
+					return fmt.Errorf("%w: invalid hash page %d", errInvalidRpmDatabase, pageNo)
+				}
+				item := page[offset : offset+bdbOffPageItemSize]
+				var err error
+				if value, err = readBerkeleyDBOverflow(f, order, pageSize, order.Uint32(item[4:8]), order.Uint32(item[8:12])); err != nil {
+					return err
+				}
+			default:
+				continue
+			}
+			if err := fn(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readBerkeleyDBOverflow reads a value stored on a chain of overflow pages
+func readBerkeleyDBOverflow(f io.ReaderAt, order binary.ByteOrder, pageSize, pageNo, length uint32) ([]byte, error) {
+	value := make([]byte, 0, length)
+	page := make([]byte, pageSize)
+	for pageNo != 0 {
+		if _, err := f.ReadAt(page, int64(pageNo)*int64(pageSize)); err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidRpmDatabase, err)
+		}
+		if page[25] != bdbPageTypeOverflow {
+			return nil, fmt.Errorf("%w: unexpected type of overflow page %d", errInvalidRpmDatabase, pageNo)
+		}
+		// the high free offset of an overflow page is the length of its data
+		size := int(order.Uint16(page[22:24]))
+		if bdbPageHeaderSize+size > len(page) || len(value)+size > int(length) {
+			return nil, fmt.Errorf("%w: invalid overflow page %d", errInvalidRpmDatabase, pageNo)
+		}
+		value = append(value, page[bdbPageHeaderSize:bdbPageHeaderSize+size]...)
+		pageNo = order.Uint32(page[16:20])
+	}
+	if len(value) != int(length) {
+		return nil, fmt.Errorf("%w: truncated overflow value", errInvalidRpmDatabase)
+	}
+	return value, nil
+}
+
+// SQLite b-tree page types, see https://www.sqlite.org/fileformat.html
+const (
+	sqliteHeaderSize        = 100
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+)
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// sqliteFile reads the table b-trees of a SQLite database file
+type sqliteFile struct {
+	r          io.ReaderAt
+	pageSize   int
+	usableSize int
+}
+
+// scanSQLiteTable calls fn with the columns of each row of a table of a SQLite
+// database. The integer primary key columns, being aliases of the row ids, are nil.
+func scanSQLiteTable(f io.ReaderAt, table string, fn func(record []interface{}) error) error {
+	header := make([]byte, sqliteHeaderSize)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidRpmDatabase, err)
+	}
+	if !bytes.Equal(header[:len(sqliteMagic)], sqliteMagic) {
+		return fmt.Errorf("%w: not a SQLite database", errInvalidRpmDatabase)
+	}
+	pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return fmt.Errorf("%w: invalid page size %d", errInvalidRpmDatabase, pageSize)
+	}
+	db := &sqliteFile{r: f, pageSize: pageSize, usableSize: pageSize - int(header[20])}
+
+	// the schema table, rooted at the first page, holds the root pages of the tables
+	rootPage := int64(0)
+	err := db.scanTable(1, func(record []interface{}) error {
+		if len(record) >= 4 && record[0] == "table" && record[1] == table {
+			rootPage, _ = record[3].(int64)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if rootPage <= 0 {
+		return fmt.Errorf("%w: table %s not found", errInvalidRpmDatabase, table)
+	}
+	return db.scanTable(uint32(rootPage), fn)
+}
+
+func (db *sqliteFile) readPage(pageNo uint32) ([]byte, error) {
+	if pageNo == 0 {
+		return nil, fmt.Errorf("%w: invalid page number", errInvalidRpmDatabase)
+	}
+	page := make([]byte, db.pageSize)
+	if _, err := db.r.ReadAt(page, int64(pageNo-1)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRpmDatabase, err)
+	}
+	return page, nil
+}
+
+// scanTable walks the table b-tree rooted at the given page
+func (db *sqliteFile) scanTable(pageNo uint32, fn func(record []interface{}) error) error {
+	page, err := db.readPage(pageNo)
+	if err != nil {
+		return err
+	}
+	start := 0
+	if pageNo == 1 {
+		start = sqliteHeaderSize
+	}
+	if start+12 > len(page) {
+		return fmt.Errorf("%w: invalid page %d", errInvalidRpmDatabase, pageNo)
+	}
+
+	pageType := page[start]
+	cells := int(binary.BigEndian.Uint16(page[start+3 : start+5]))
+	pointers := start + 8
+	if pageType == sqlitePageInteriorTable {
+		pointers = start + 12
+	} else if pageType != sqlitePageLeafTable {
+		return fmt.Errorf("%w: unexpected type of page %d", errInvalidRpmDatabase, pageNo)
+	}
+	if pointers+2*cells > len(page) {
+		return fmt.Errorf("%w: invalid page %d", errInvalidRpmDatabase, pageNo)
+	}
+
+	for i := 0; i < cells; i++ {
+		offset := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+		if offset+4 > len(page) {
+			return fmt.Errorf("%w: invalid cell in page %d", errInvalidRpmDatabase, pageNo)
+		}
+		if pageType == sqlitePageInteriorTable {
+			if err := db.scanTable(binary.BigEndian.Uint32(page[offset:offset+4]), fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		payload, err := db.readPayload(page, offset)
+		if err != nil {
+			return err
+		}
+		record, err := parseSQLiteRecord(payload)
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if pageType == sqlitePageInteriorTable {
+		return db.scanTable(binary.BigEndian.Uint32(page[start+8:start+12]), fn)
+	}
+	return nil
+}
+
+// readPayload returns the payload of a table leaf cell, including the part of it
+// spilled onto overflow pages
+func (db *sqliteFile) readPayload(page []byte, offset int) ([]byte, error) {
+	size, n := sqliteVarint(page[offset:])
+	if n == 0 {
+		return nil, errInvalidRpmDatabase
+	}
+	offset += n
+	if _, n = sqliteVarint(page[offset:]); n == 0 { // row id
+		return nil, errInvalidRpmDatabase
+	}
+	offset += n
+
+	// the share of the payload stored in the page itself
+	local := int(size)
+	maxLocal := db.usableSize - 35
+	if local > maxLocal {
+		minLocal := (db.usableSize-12)*32/255 - 23
+		local = minLocal + (int(size)-minLocal)%(db.usableSize-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if offset+local > len(page) {
+		return nil, errInvalidRpmDatabase
+	}
+	payload := make([]byte, 0, size)
+	payload = append(payload, page[offset:offset+local]...)
+	if local == int(size) {
+		return payload, nil
+	}
+	if offset+local+4 > len(page) {
+		return nil, errInvalidRpmDatabase
+	}
+
+	for next := binary.BigEndian.Uint32(page[offset+local:]); len(payload) < int(size); {
+		overflow, err := db.readPage(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := overflow[4:db.usableSize]
+		if remaining := int(size) - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+		next = binary.BigEndian.Uint32(overflow[0:4])
+	}
+	return payload, nil
+}
+
+// parseSQLiteRecord parses the columns of a record, integers being returned as
+// int64, texts as strings and blobs as byte slices
+func parseSQLiteRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := sqliteVarint(payload)
+	if n == 0 || int(headerSize) > len(payload) {
+		return nil, errInvalidRpmDatabase
+	}
+
+	var record []interface{}
+	body := int(headerSize)
+	for offset := n; offset < int(headerSize); {
+		serialType, n := sqliteVarint(payload[offset:headerSize])
+		if n == 0 {
+			return nil, errInvalidRpmDatabase
+		}
+		offset += n
+
+		var size int
+		switch {
+		case serialType >= 12:
+			size = int(serialType-12) / 2
+		case serialType >= 1 && serialType <= 4:
+			size = int(serialType)
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		}
+		if body+size > len(payload) {
+			return nil, errInvalidRpmDatabase
+		}
+		value := payload[body : body+size]
+		body += size
+
+		switch {
+		case serialType == 0:
+			record = append(record, nil)
+		case serialType >= 1 && serialType <= 6:
+			// big endian two's complement integers
+			i := int64(int8(value[0]))
+			for _, b := range value[1:] {
+				i = i<<8 | int64(b)
+			}
+			record = append(record, i)
+		case serialType == 8 || serialType == 9:
+			record = append(record, int64(serialType-8))
+		case serialType >= 12 && serialType%2 == 0:
+			record = append(record, value)
+		case serialType >= 13:
+			record = append(record, string(value))
+		default:
+			// floats aren't needed
+			record = append(record, nil)
+		}
+	}
+	return record, nil
+}
+
+// sqliteVarint decodes a SQLite variable-length integer, returning its value and
+// its length, 0 if it is invalid
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
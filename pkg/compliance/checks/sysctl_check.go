@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+)
+
+var sysctlReportedFields = []string{
+	compliance.SysctlFieldKey,
+	compliance.SysctlFieldValue,
+}
+
+// ErrSysctlNotFound is returned when a kernel parameter cannot be found
+var ErrSysctlNotFound = errors.New("kernel parameter not found")
+
+func resolveSysctl(_ context.Context, e env.Env, id string, res compliance.Resource) (resolved, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", id)
+	}
+
+	key := res.Sysctl.Key
+	if key == "" || strings.Contains(key, "..") {
+		return nil, fmt.Errorf("%s: invalid kernel parameter %q", id, key)
+	}
+
+	path := e.NormalizeToHostRoot(filepath.Join("/proc/sys", strings.ReplaceAll(key, ".", "/")))
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, wrapErrorWithID(id, fmt.Errorf("%w: %s", ErrSysctlNotFound, key))
+		}
+		return nil, wrapErrorWithID(id, err)
+	}
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SysctlFieldKey:   key,
+			compliance.SysctlFieldValue: parseSysctlValue(string(content)),
+		},
+		nil,
+	)
+
+	return newResolvedInstance(instance, key, "sysctl"), nil
+}
+
+// parseSysctlValue returns the value of a kernel parameter as an integer if it
+// is one, or as a string with its whitespaces normalized, like `32768 60999`
+func parseSysctlValue(content string) interface{} {
+	value := strings.Join(strings.Fields(content), " ")
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+	return value
+}
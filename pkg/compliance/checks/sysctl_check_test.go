@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+// testdataHostRoot returns a function mapping the host paths to a testdata directory
+func testdataHostRoot(dir string) func(string) string {
+	return func(path string) string {
+		return filepath.Join("./testdata", dir, path)
+	}
+}
+
+func TestSysctlCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "integer parameter",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Key: "net.ipv4.conf.all.send_redirects",
+				},
+				Condition: `sysctl.value == 0`,
+			},
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.key":   "net.ipv4.conf.all.send_redirects",
+					"sysctl.value": 0,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.conf.all.send_redirects",
+					Type: "sysctl",
+				},
+			},
+		},
+		{
+			name: "integer parameter not matching",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Key: "kernel.randomize_va_space",
+				},
+				Condition: `sysctl.value < 2`,
+			},
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"sysctl.key":   "kernel.randomize_va_space",
+					"sysctl.value": 2,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "kernel.randomize_va_space",
+					Type: "sysctl",
+				},
+			},
+		},
+		{
+			name: "string parameter",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Key: "net.ipv4.ip_local_port_range",
+				},
+				Condition: `sysctl.value == "32768 60999"`,
+			},
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"sysctl.key":   "net.ipv4.ip_local_port_range",
+					"sysctl.value": "32768 60999",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "net.ipv4.ip_local_port_range",
+					Type: "sysctl",
+				},
+			},
+		},
+		{
+			name: "missing parameter",
+			resource: compliance.Resource{
+				Sysctl: &compliance.Sysctl{
+					Key: "net.ipv6.conf.all.forwarding",
+				},
+				Condition: `sysctl.value == 0`,
+			},
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Error:  fmt.Errorf("rule-id: %w", fmt.Errorf("%w: net.ipv6.conf.all.forwarding", ErrSysctlNotFound)),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot("sysctl"))
+
+			sysctlCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := sysctlCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+			if test.expectReport.Error != nil {
+				assert.True(errors.Is(reports[0].Error, ErrSysctlNotFound))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+)
+
+var systemdReportedFields = []string{
+	compliance.SystemdFieldUnit,
+	compliance.SystemdFieldExists,
+	compliance.SystemdFieldEnabled,
+	compliance.SystemdFieldMasked,
+	compliance.SystemdFieldActive,
+}
+
+// systemdUnitDirs are the directories of the system units, by decreasing
+// priority, see systemd.unit(5)
+var systemdUnitDirs = []string{"/etc/systemd/system", "/run/systemd/system", "/usr/local/lib/systemd/system", "/lib/systemd/system", "/usr/lib/systemd/system"}
+
+// systemdEnablementDirs are the directories holding the .wants and .requires
+// links of the enabled units, the vendor ones being enabled statically by the
+// packages
+var systemdEnablementDirs = []string{"/etc/systemd/system", "/lib/systemd/system", "/usr/lib/systemd/system"}
+
+// systemdInvocationDir holds a link for each unit started since the boot, and
+// still active, see sd_id128_get_invocation(3)
+const systemdInvocationDir = "/run/systemd/units"
+
+func resolveSystemd(_ context.Context, e env.Env, id string, res compliance.Resource) (resolved, error) {
+	if res.Systemd == nil {
+		return nil, fmt.Errorf("%s: expecting systemd resource in systemd check", id)
+	}
+
+	unit := res.Systemd.Unit
+	if unit == "" || strings.Contains(unit, "/") {
+		return nil, fmt.Errorf("%s: invalid systemd unit %q", id, unit)
+	}
+	// units are services unless specified
+	if filepath.Ext(unit) == "" {
+		unit += ".service"
+	}
+
+	var exists, masked bool
+	for _, dir := range systemdUnitDirs {
+		path := filepath.Join(e.NormalizeToHostRoot(dir), unit)
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+
+		// the first unit file found overrides the others
+		if target, err := os.Readlink(path); err == nil && target == "/dev/null" {
+			masked = true
+		} else {
+			exists = true
+		}
+		break
+	}
+
+	enabled := false
+	for _, dir := range systemdEnablementDirs {
+		for _, kind := range []string{"wants", "requires"} {
+			links, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(dir), "*."+kind, unit))
+			if err != nil {
+				return nil, wrapErrorWithID(id, err)
+			}
+			if len(links) > 0 {
+				enabled = true
+			}
+		}
+	}
+
+	_, err := os.Lstat(filepath.Join(e.NormalizeToHostRoot(systemdInvocationDir), "invocation:"+unit))
+	active := err == nil
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SystemdFieldUnit:    unit,
+			compliance.SystemdFieldExists:  exists,
+			compliance.SystemdFieldEnabled: enabled && !masked,
+			compliance.SystemdFieldMasked:  masked,
+			compliance.SystemdFieldActive:  active,
+		},
+		nil,
+	)
+
+	return newResolvedInstance(instance, unit, "systemd_unit"), nil
+}
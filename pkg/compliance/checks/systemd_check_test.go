@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSystemdCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      string
+		condition string
+
+		expectReport *compliance.Report
+	}{
+		{
+			name:      "enabled and active service",
+			unit:      "sshd",
+			condition: `systemd.enabled && systemd.active`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemd.unit":    "sshd.service",
+					"systemd.exists":  true,
+					"systemd.enabled": true,
+					"systemd.masked":  false,
+					"systemd.active":  true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "sshd.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "masked service",
+			unit:      "rsyncd.service",
+			condition: `!systemd.enabled || systemd.masked`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemd.unit":    "rsyncd.service",
+					"systemd.exists":  false,
+					"systemd.enabled": false,
+					"systemd.masked":  true,
+					"systemd.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "rsyncd.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "disabled service",
+			unit:      "auditd.service",
+			condition: `systemd.enabled`,
+
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"systemd.unit":    "auditd.service",
+					"systemd.exists":  true,
+					"systemd.enabled": false,
+					"systemd.masked":  false,
+					"systemd.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "auditd.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "statically enabled socket",
+			unit:      "dbus.socket",
+			condition: `systemd.enabled`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemd.unit":    "dbus.socket",
+					"systemd.exists":  true,
+					"systemd.enabled": true,
+					"systemd.masked":  false,
+					"systemd.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "dbus.socket",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "missing timer",
+			unit:      "backup.timer",
+			condition: `!systemd.exists`,
+
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"systemd.unit":    "backup.timer",
+					"systemd.exists":  false,
+					"systemd.enabled": false,
+					"systemd.masked":  false,
+					"systemd.active":  false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "backup.timer",
+					Type: "systemd_unit",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(testdataHostRoot("systemd"))
+
+			resource := compliance.Resource{
+				Systemd: &compliance.SystemdUnit{
+					Unit: test.unit,
+				},
+				Condition: test.condition,
+			}
+
+			systemdCheck, err := newResourceCheck(env, "rule-id", resource)
+			assert.NoError(err)
+
+			reports := systemdCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
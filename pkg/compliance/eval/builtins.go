@@ -0,0 +1,372 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/alecthomas/participle/lexer"
+)
+
+const (
+	anyFn = "any"
+
+	// quantifierVar is the variable holding the current element of a list in
+	// the condition of a quantifier
+	quantifierVar = "_"
+)
+
+var (
+	// builtInFunctions are available to every instance, unless it defines a
+	// function with the same name
+	builtInFunctions = FunctionMap{
+		"capture":          captureFn,
+		"semver.compare":   semverCompareFn,
+		"semver.satisfies": semverSatisfiesFn,
+		"int":              intFn,
+		"octal":            octalFn,
+		"size":             sizeFn,
+		"duration":         durationFn,
+	}
+
+	// quantifiers evaluate a condition for every element of a list, such as
+	// `any(process.flags, _ =~ "^--insecure")`, returning whether it passed
+	// given the number of elements passing it and the number of elements
+	quantifiers = map[string]func(passedCount, totalCount int) bool{
+		allFn:  func(passedCount, totalCount int) bool { return passedCount == totalCount },
+		anyFn:  func(passedCount, _ int) bool { return passedCount > 0 },
+		noneFn: func(passedCount, _ int) bool { return passedCount == 0 },
+	}
+
+	sizeUnits = map[string]uint64{
+		"":  1,
+		"B": 1,
+		"K": 1 << 10,
+		"M": 1 << 20,
+		"G": 1 << 30,
+		"T": 1 << 40,
+		"P": 1 << 50,
+	}
+
+	sizeRegexp = regexp.MustCompile(`^(\d+)\s*([KMGTP]?)(?:I?B)?$`)
+)
+
+// extendedInstance is an instance with additional variables and functions
+type extendedInstance struct {
+	Instance
+	vars      VarMap
+	functions FunctionMap
+}
+
+// ExtendInstance returns an instance with additional variables and functions,
+// which take precedence over the ones of the given instance
+func ExtendInstance(instance Instance, vars VarMap, functions FunctionMap) Instance {
+	return &extendedInstance{
+		Instance:  instance,
+		vars:      vars,
+		functions: functions,
+	}
+}
+
+func (i *extendedInstance) Var(name string) (interface{}, bool) {
+	if value, ok := i.vars[name]; ok {
+		return value, true
+	}
+	return i.Instance.Var(name)
+}
+
+func (i *extendedInstance) Vars() VarMap {
+	vars := VarMap{}
+	for name, value := range i.Instance.Vars() {
+		vars[name] = value
+	}
+	for name, value := range i.vars {
+		vars[name] = value
+	}
+	return vars
+}
+
+func (i *extendedInstance) Function(name string) (Function, bool) {
+	if function, ok := i.functions[name]; ok {
+		return function, true
+	}
+	return i.Instance.Function(name)
+}
+
+func (i *extendedInstance) Functions() FunctionMap {
+	functions := FunctionMap{}
+	for name, function := range i.Instance.Functions() {
+		functions[name] = function
+	}
+	for name, function := range i.functions {
+		functions[name] = function
+	}
+	return functions
+}
+
+// evaluateQuantifier evaluates a quantifier call, its condition being
+// evaluated for every element of the list
+func (c *Call) evaluateQuantifier(instance Instance, quantifier func(passedCount, totalCount int) bool) (interface{}, error) {
+	if len(c.Args) != 2 {
+		return nil, lexer.Errorf(c.Pos, `expecting a list and a condition for "%s()"`, c.Name)
+	}
+
+	value, err := c.Args[0].Evaluate(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []interface{}
+	if value != nil {
+		var ok bool
+		if list, ok = coerceArrays(value).([]interface{}); !ok {
+			return nil, lexer.Errorf(c.Pos, `expecting a list as first argument of "%s()"`, c.Name)
+		}
+	}
+
+	passedCount := 0
+	for _, element := range list {
+		elementInstance := ExtendInstance(instance, VarMap{quantifierVar: coerceIntegers(element)}, nil)
+		passed, err := c.Args[1].BoolEvaluate(elementInstance)
+		if err != nil {
+			return nil, err
+		}
+		if passed {
+			passedCount++
+		}
+	}
+
+	return quantifier(passedCount, len(list)), nil
+}
+
+func stringArg(args []interface{}, i int) (string, error) {
+	if i >= len(args) {
+		return "", fmt.Errorf(`expecting at least %d arguments`, i+1)
+	}
+	s, ok := args[i].(string)
+	if !ok {
+		return "", fmt.Errorf(`expecting a string as argument %d`, i+1)
+	}
+	return s, nil
+}
+
+// captureFn returns the group of the first match of a regular expression in a
+// string, or an empty string if it doesn't match: `capture(s, re)` returns the
+// first group, or the whole match if there isn't any, while
+// `capture(s, re, group)` returns the group of the given index or name
+func captureFn(_ Instance, args ...interface{}) (interface{}, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, errors.New(`expecting a string, a regular expression and an optional group`)
+	}
+
+	s, err := stringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	group := 0
+	if re.NumSubexp() > 0 {
+		group = 1
+	}
+
+	if len(args) == 3 {
+		switch arg := args[2].(type) {
+		case int64:
+			group = int(arg)
+		case uint64:
+			group = int(arg)
+		case string:
+			if group = re.SubexpIndex(arg); group < 0 {
+				return nil, fmt.Errorf(`unknown group "%s"`, arg)
+			}
+		default:
+			return nil, errors.New(`expecting an integer or a string as group`)
+		}
+	}
+
+	if group < 0 || group > re.NumSubexp() {
+		return nil, fmt.Errorf(`invalid group %d`, group)
+	}
+
+	match := re.FindStringSubmatch(s)
+	if match == nil {
+		return "", nil
+	}
+	return match[group], nil
+}
+
+func parseVersionArg(args []interface{}, i int) (*semver.Version, error) {
+	s, err := stringArg(args, i)
+	if err != nil {
+		return nil, err
+	}
+	v, err := semver.NewVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid version "%s": %v`, s, err)
+	}
+	return v, nil
+}
+
+// semverCompareFn compares two semantic versions, returning -1, 0 or 1 as
+// the first version is lower, equal or greater than the second one
+func semverCompareFn(_ Instance, args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, errors.New(`expecting two versions`)
+	}
+
+	a, err := parseVersionArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := parseVersionArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(a.Compare(b)), nil
+}
+
+// semverSatisfiesFn returns whether a semantic version satisfies a constraint,
+// like `>= 1.2, < 2`
+func semverSatisfiesFn(_ Instance, args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, errors.New(`expecting a version and a constraint`)
+	}
+
+	v, err := parseVersionArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := stringArg(args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	constraint, err := semver.NewConstraint(s)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid constraint "%s": %v`, s, err)
+	}
+
+	return constraint.Check(v), nil
+}
+
+func numberArg(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errors.New(`expecting one argument`)
+	}
+	if s, ok := args[0].(string); ok {
+		return strings.TrimSpace(s), nil
+	}
+	return args[0], nil
+}
+
+// intFn parses a decimal integer, ignoring the leading zeros, such that
+// `int("0600") == 600`
+func intFn(_ Instance, args ...interface{}) (interface{}, error) {
+	arg, err := numberArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arg := arg.(type) {
+	case int64, uint64:
+		return arg, nil
+	case string:
+		return strconv.ParseInt(arg, 10, 64)
+	default:
+		return nil, errors.New(`expecting an integer or a string`)
+	}
+}
+
+// octalFn parses permissions, such that `octal("600")`, `octal("0600")` and
+// `octal(600)` are all equal to `0600`. Unsigned integers, such as octal
+// literals or file permissions, are returned as is.
+func octalFn(_ Instance, args ...interface{}) (interface{}, error) {
+	arg, err := numberArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arg := arg.(type) {
+	case uint64:
+		return arg, nil
+	case int64:
+		return strconv.ParseUint(strconv.FormatInt(arg, 10), 8, 64)
+	case string:
+		return strconv.ParseUint(strings.TrimPrefix(arg, "0o"), 8, 64)
+	default:
+		return nil, errors.New(`expecting an integer or a string`)
+	}
+}
+
+// sizeFn parses a size in bytes with an optional binary unit, such that
+// `size("10M")`, `size("10MB")` and `size("10MiB")` are all equal to 10485760
+func sizeFn(_ Instance, args ...interface{}) (interface{}, error) {
+	arg, err := numberArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arg := arg.(type) {
+	case int64, uint64:
+		return arg, nil
+	case string:
+		match := sizeRegexp.FindStringSubmatch(strings.ToUpper(arg))
+		if match == nil {
+			return nil, fmt.Errorf(`invalid size "%s"`, arg)
+		}
+		size, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return size * sizeUnits[match[2]], nil
+	default:
+		return nil, errors.New(`expecting an integer or a string`)
+	}
+}
+
+// durationFn parses a duration in seconds, such that `duration("1h30m")` is
+// equal to 5400, integers being seconds already
+func durationFn(_ Instance, args ...interface{}) (interface{}, error) {
+	arg, err := numberArg(args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch arg := arg.(type) {
+	case int64, uint64:
+		return arg, nil
+	case string:
+		if seconds, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			return seconds, nil
+		}
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return nil, err
+		}
+		return int64(d / time.Second), nil
+	default:
+		return nil, errors.New(`expecting an integer or a string`)
+	}
+}
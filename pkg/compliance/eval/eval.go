@@ -548,6 +548,12 @@ func (a *Array) Evaluate(instance Instance) (interface{}, error) {
 // Evaluate implements Evaluatable interface
 func (c *Call) Evaluate(instance Instance) (interface{}, error) {
 	fn, ok := instance.Function(c.Name)
+	if !ok {
+		if quantifier, ok := quantifiers[c.Name]; ok {
+			return c.evaluateQuantifier(instance, quantifier)
+		}
+		fn, ok = builtInFunctions[c.Name]
+	}
 	if !ok {
 		return nil, lexer.Errorf(c.Pos, `unknown function "%s()"`, c.Name)
 	}
@@ -604,6 +604,196 @@ func TestEvalSubExpression(t *testing.T) {
 	}.Run(t)
 }
 
+func TestEvalQuantifiers(t *testing.T) {
+	vars := VarMap{
+		"flags": []string{"--anonymous-auth=false", "--profiling=false"},
+		"ports": []int{22, 443},
+		"empty": []string{},
+	}
+
+	instanceTests{
+		{
+			name:         "all - true",
+			expression:   `all(flags, _ =~ "=false$")`,
+			vars:         vars,
+			expectResult: true,
+		},
+		{
+			name:         "all - false",
+			expression:   `all(ports, _ > 100)`,
+			vars:         vars,
+			expectResult: false,
+		},
+		{
+			name:         "any - true",
+			expression:   `any(ports, _ == 22) && any(flags, _ == "--profiling=false")`,
+			vars:         vars,
+			expectResult: true,
+		},
+		{
+			name:         "any - empty list",
+			expression:   `any(empty, _ == "")`,
+			vars:         vars,
+			expectResult: false,
+		},
+		{
+			name:         "none - true",
+			expression:   `none(flags, _ =~ "^--insecure")`,
+			vars:         vars,
+			expectResult: true,
+		},
+		{
+			name:         "none - function in condition",
+			expression:   `none(flags, capture(_, "^--([a-z-]+)=") == "profiling")`,
+			vars:         vars,
+			expectResult: false,
+		},
+		{
+			name:        "missing condition",
+			expression:  `all(flags)`,
+			vars:        vars,
+			expectError: newLexerError(0, `expecting a list and a condition for "all()"`),
+		},
+		{
+			name:        "not a list",
+			expression:  `any("abc", _ == "a")`,
+			expectError: newLexerError(0, `expecting a list as first argument of "any()"`),
+		},
+		{
+			name:        "condition not a boolean",
+			expression:  `any(ports, _ | 1)`,
+			vars:        vars,
+			expectError: newLexerError(11, "expression must evaluate to a boolean"),
+		},
+	}.Run(t)
+}
+
+func TestEvalBuiltinFunctions(t *testing.T) {
+	instanceTests{
+		{
+			name:         "capture first group",
+			expression:   `capture("PermitRootLogin  no", "^PermitRootLogin\\s+(\\w+)")`,
+			expectResult: "no",
+		},
+		{
+			name:         "capture named group",
+			expression:   `capture("TLSv1.2", "^TLSv(?P<major>\\d)\\.(?P<minor>\\d)$", "minor") == "2"`,
+			expectResult: true,
+		},
+		{
+			name:         "capture whole match without groups",
+			expression:   `capture("umask 027", "0[0-7]+")`,
+			expectResult: "027",
+		},
+		{
+			name:         "capture without match",
+			expression:   `capture("umask 027", "^(PASS_MAX_DAYS)")`,
+			expectResult: "",
+		},
+		{
+			name:        "capture invalid group",
+			expression:  `capture("umask 027", "^(umask)", 2)`,
+			expectError: newLexerError(0, `call to "capture()" failed: invalid group 2`),
+		},
+		{
+			name:         "semver compare",
+			expression:   `semver.compare(version, "1.10.0") > 0`,
+			vars:         VarMap{"version": "v1.18.3"},
+			expectResult: true,
+		},
+		{
+			name:         "semver compare prerelease",
+			expression:   `semver.compare("1.0.0-rc.1", "1.0.0")`,
+			expectResult: int64(-1),
+		},
+		{
+			name:         "semver satisfies",
+			expression:   `semver.satisfies("20.10.2", ">= 19.03, < 21")`,
+			expectResult: true,
+		},
+		{
+			name:        "semver invalid version",
+			expression:  `semver.compare("1:8.2p1-4ubuntu0.2", "8.0")`,
+			expectError: newLexerError(0, `call to "semver.compare()" failed: invalid version "1:8.2p1-4ubuntu0.2": Invalid Semantic Version`),
+		},
+		{
+			name:         "int with leading zeros",
+			expression:   `int("0600") == 600`,
+			expectResult: true,
+		},
+		{
+			name:         "octal permissions",
+			expression:   `octal("600") == 0600 && octal(" 0600\n") == 0600 && octal(600) == 0600 && octal(0600) == 0600`,
+			expectResult: true,
+		},
+		{
+			name:         "octal permissions comparison",
+			expression:   `file.permissions & ^octal(umask) == 0640`,
+			vars:         VarMap{"file.permissions": uint32(0644), "umask": "027"},
+			expectResult: true,
+		},
+		{
+			name:        "invalid octal",
+			expression:  `octal("0800")`,
+			expectError: newLexerError(0, `call to "octal()" failed: strconv.ParseUint: parsing "0800": invalid syntax`),
+		},
+		{
+			name:         "size with units",
+			expression:   `size("10M") == 10485760 && size("10 MiB") == size("10mb") && size("512") == 512`,
+			expectResult: true,
+		},
+		{
+			name:         "size comparison",
+			expression:   `size(max_log_file) >= size("8M")`,
+			vars:         VarMap{"max_log_file": "100M"},
+			expectResult: true,
+		},
+		{
+			name:        "invalid size",
+			expression:  `size("10X")`,
+			expectError: newLexerError(0, `call to "size()" failed: invalid size "10X"`),
+		},
+		{
+			name:         "duration",
+			expression:   `duration("1h30m") == 5400 && duration("300") == 300 && duration(60) == 60`,
+			expectResult: true,
+		},
+		{
+			name:       "instance function overrides builtin",
+			expression: `size("10M")`,
+			functions: FunctionMap{
+				"size": func(instance Instance, args ...interface{}) (interface{}, error) {
+					return "custom", nil
+				},
+			},
+			expectResult: "custom",
+		},
+	}.Run(t)
+}
+
+func TestExtendInstance(t *testing.T) {
+	assert := assert.New(t)
+
+	base := NewInstance(
+		VarMap{"a": 1, "b": 2},
+		FunctionMap{"fn": func(instance Instance, args ...interface{}) (interface{}, error) { return "base", nil }},
+	)
+	instance := ExtendInstance(
+		base,
+		VarMap{"b": 3},
+		FunctionMap{"other": func(instance Instance, args ...interface{}) (interface{}, error) { return "other", nil }},
+	)
+
+	assert.Equal(VarMap{"a": 1, "b": 3}, instance.Vars())
+	assert.Len(instance.Functions(), 2)
+
+	expr, err := ParseExpression(`a == 1 && b == 3 && fn() == "base" && other() == "other"`)
+	assert.NoError(err)
+	passed, err := expr.BoolEvaluate(instance)
+	assert.NoError(err)
+	assert.True(passed)
+}
+
 type iteratorFixture struct {
 	vars      VarMap
 	functions FunctionMap
@@ -68,6 +68,11 @@ func ParseIterable(s string) (*IterableExpression, error) {
 	expr := &IterableExpression{}
 	err := iterableParser.ParseString(s, expr)
 	if err != nil {
+		// the list quantifiers, like `all(list, condition)`, are parsed as
+		// iterable comparisons first
+		if subexpr, subexprErr := ParseExpression(s); subexprErr == nil {
+			return &IterableExpression{Expression: subexpr}, nil
+		}
 		return nil, err
 	}
 	return expr, nil
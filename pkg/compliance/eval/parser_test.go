@@ -34,3 +34,27 @@ func TestParsePathError(t *testing.T) {
 	assert.Nil(expr)
 	assert.EqualError(err, `1:1: unexpected token "="`)
 }
+
+func TestParseIterableQuantifier(t *testing.T) {
+	assert := assert.New(t)
+
+	expr, err := ParseIterable(`all(process.flags, _ != "--profiling")`)
+	assert.NoError(err)
+	assert.Nil(expr.IterableComparison)
+	assert.NotNil(expr.Expression.Comparison.Term.Unary.Value.Call)
+	assert.Equal("all", expr.Expression.Comparison.Term.Unary.Value.Call.Name)
+	assert.Len(expr.Expression.Comparison.Term.Unary.Value.Call.Args, 2)
+
+	passed, err := expr.Evaluate(NewInstance(VarMap{"process.flags": []string{"--anonymous-auth"}}, nil))
+	assert.NoError(err)
+	assert.True(passed)
+
+	// single argument calls remain iterable comparisons
+	expr, err = ParseIterable(`all(file.permissions == 0644)`)
+	assert.NoError(err)
+	assert.NotNil(expr.IterableComparison)
+
+	expr, err = ParseIterable(`none(process.flags, )`)
+	assert.Nil(expr)
+	assert.EqualError(err, `1:19: unexpected token "," (expected ")")`)
+}
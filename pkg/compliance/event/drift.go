@@ -0,0 +1,253 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// DefaultDriftStateFilename is the default name of the file keeping the last
+// results in the run path
+const DefaultDriftStateFilename = "compliance-results.json"
+
+const (
+	driftStateVersion     = 1
+	driftStateFlushPeriod = time.Minute
+	// driftStateTTL is the delay after which the result of a resource that
+	// isn't checked anymore, like a removed container, is forgotten
+	driftStateTTL = 7 * 24 * time.Hour
+)
+
+// ResultState holds the last result of a rule on a resource
+type ResultState struct {
+	Result string `json:"result"`
+	// ChangedAt is when the result was first reported
+	ChangedAt time.Time `json:"changed_at"`
+	// ReportedAt is when the result was last reported
+	ReportedAt time.Time `json:"reported_at"`
+	// SeenAt is when the result was last checked
+	SeenAt time.Time `json:"seen_at"`
+}
+
+type driftState struct {
+	Version int                     `json:"version"`
+	Results map[string]*ResultState `json:"results"`
+}
+
+// DriftReporter is a reporter keeping the last result of every rule and
+// resource, only reporting the events whose result changed. The unchanged
+// results are reported again once per snapshot interval, so that the full
+// state is known downstream. The results are kept in a state file, to
+// survive restarts.
+type DriftReporter struct {
+	sync.Mutex
+
+	reporter         Reporter
+	statePath        string
+	snapshotInterval time.Duration
+	results          map[string]*ResultState
+	dirty            bool
+	now              func() time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewDriftReporter returns a reporter forwarding the changes of results to
+// the given reporter, loading the previous results from the state file if it
+// exists
+func NewDriftReporter(reporter Reporter, statePath string, snapshotInterval time.Duration) (*DriftReporter, error) {
+	if snapshotInterval <= 0 {
+		return nil, fmt.Errorf("invalid snapshot interval %s", snapshotInterval)
+	}
+
+	r := &DriftReporter{
+		reporter:         reporter,
+		statePath:        statePath,
+		snapshotInterval: snapshotInterval,
+		results:          make(map[string]*ResultState),
+		now:              time.Now,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func driftKey(event *Event) string {
+	return event.AgentRuleID + "/" + event.ResourceType + "/" + event.ResourceID
+}
+
+// Report forwards the event if its result changed since the last run, or if
+// it wasn't reported for the snapshot interval
+func (r *DriftReporter) Report(event *Event) {
+	if !r.track(event) {
+		log.Tracef("%s: result unchanged for [%s] [%s]", event.AgentRuleID, event.ResourceType, event.ResourceID)
+		return
+	}
+	r.reporter.Report(event)
+}
+
+// track updates the state with the result of the event, and returns whether
+// it must be reported
+func (r *DriftReporter) track(event *Event) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now().UTC()
+	key := driftKey(event)
+	r.dirty = true
+
+	state, exists := r.results[key]
+	if !exists {
+		r.results[key] = &ResultState{Result: event.Result, ChangedAt: now, ReportedAt: now, SeenAt: now}
+		return true
+	}
+
+	state.SeenAt = now
+	if state.Result != event.Result {
+		event.PreviousResult = state.Result
+		state.Result, state.ChangedAt, state.ReportedAt = event.Result, now, now
+		return true
+	}
+
+	if now.Sub(state.ReportedAt) >= r.snapshotInterval {
+		event.Snapshot = true
+		state.ReportedAt = now
+		return true
+	}
+
+	return false
+}
+
+// ReportRaw forwards the raw content, which isn't a result
+func (r *DriftReporter) ReportRaw(content []byte, service string, tags ...string) {
+	r.reporter.ReportRaw(content, service, tags...)
+}
+
+// Results returns a copy of the last results, indexed by rule, resource type
+// and resource ID
+func (r *DriftReporter) Results() map[string]ResultState {
+	r.Lock()
+	defer r.Unlock()
+
+	results := make(map[string]ResultState, len(r.results))
+	for key, state := range r.results {
+		results[key] = *state
+	}
+	return results
+}
+
+// Start periodically writes the state file
+func (r *DriftReporter) Start() {
+	r.done = make(chan struct{})
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(driftStateFlushPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					log.Errorf("Failed to write compliance results to %s: %v", r.statePath, err)
+				}
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop writes the state file and stops the periodic writes
+func (r *DriftReporter) Stop() {
+	if r.done != nil {
+		close(r.done)
+		r.wg.Wait()
+		r.done = nil
+	}
+
+	if err := r.Flush(); err != nil {
+		log.Errorf("Failed to write compliance results to %s: %v", r.statePath, err)
+	}
+}
+
+// Flush writes the state file if the results changed, forgetting the results
+// of the resources that weren't checked for a long time
+func (r *DriftReporter) Flush() error {
+	r.Lock()
+	if !r.dirty {
+		r.Unlock()
+		return nil
+	}
+
+	expireBefore := r.now().UTC().Add(-driftStateTTL)
+	for key, state := range r.results {
+		if state.SeenAt.Before(expireBefore) {
+			delete(r.results, key)
+		}
+	}
+
+	content, err := json.Marshal(&driftState{Version: driftStateVersion, Results: r.results})
+	r.dirty = false
+	r.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.statePath), 0755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first to never leave a truncated state
+	tmpPath := r.statePath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, r.statePath)
+}
+
+func (r *DriftReporter) load() error {
+	content, err := ioutil.ReadFile(r.statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var state driftState
+	if err := json.Unmarshal(content, &state); err != nil {
+		log.Warnf("Ignoring invalid compliance results from %s: %v", r.statePath, err)
+		return nil
+	}
+
+	if state.Version != driftStateVersion {
+		log.Warnf("Ignoring compliance results from %s with unsupported version %d", r.statePath, state.Version)
+		return nil
+	}
+
+	for key, result := range state.Results {
+		if result != nil {
+			r.results[key] = result
+		}
+	}
+	return nil
+}
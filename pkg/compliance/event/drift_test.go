@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func newTestDriftReporter(t *testing.T, recorder *Recorder, statePath string, now *time.Time) *DriftReporter {
+	reporter, err := NewDriftReporter(recorder, statePath, 24*time.Hour)
+	assert.NoError(t, err)
+	reporter.now = func() time.Time { return *now }
+	return reporter
+}
+
+func TestDriftReporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drift")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	statePath := filepath.Join(dir, "run", DefaultDriftStateFilename)
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	recorder := &Recorder{}
+	reporter := newTestDriftReporter(t, recorder, statePath, &now)
+
+	newEvent := func(resourceID, result string) *Event {
+		return &Event{AgentRuleID: "cis-docker-1", ResourceType: "docker_container", ResourceID: resourceID, Result: result}
+	}
+
+	// the first results are reported
+	reporter.Report(newEvent("abc", Passed))
+	reporter.Report(newEvent("def", Passed))
+	assert.Len(t, recorder.Events(), 2)
+
+	// the unchanged results aren't
+	now = now.Add(time.Hour)
+	reporter.Report(newEvent("abc", Passed))
+	reporter.Report(newEvent("def", Failed))
+
+	events := recorder.Events()
+	assert.Len(t, events, 3)
+	assert.Equal(t, Failed, events[2].Result)
+	assert.Equal(t, Passed, events[2].PreviousResult)
+	assert.False(t, events[2].Snapshot)
+
+	// the state survives restarts
+	assert.NoError(t, reporter.Flush())
+	recorder = &Recorder{}
+	reporter = newTestDriftReporter(t, recorder, statePath, &now)
+
+	results := reporter.Results()
+	assert.Len(t, results, 2)
+	assert.Equal(t, Failed, results["cis-docker-1/docker_container/def"].Result)
+	assert.Equal(t, now, results["cis-docker-1/docker_container/def"].ChangedAt)
+
+	reporter.Report(newEvent("def", Failed))
+	assert.Len(t, recorder.Events(), 0)
+
+	// the unchanged results are reported once per snapshot interval
+	now = now.Add(23 * time.Hour)
+	reporter.Report(newEvent("abc", Passed))
+	reporter.Report(newEvent("def", Failed))
+
+	events = recorder.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "abc", events[0].ResourceID)
+	assert.True(t, events[0].Snapshot)
+	assert.Empty(t, events[0].PreviousResult)
+
+	// the resources that aren't checked anymore are eventually forgotten
+	now = now.Add(driftStateTTL + time.Hour)
+	reporter.Report(newEvent("abc", Passed))
+	assert.NoError(t, reporter.Flush())
+
+	reporter = newTestDriftReporter(t, recorder, statePath, &now)
+	results = reporter.Results()
+	assert.Len(t, results, 1)
+	assert.Contains(t, results, "cis-docker-1/docker_container/abc")
+}
+
+func TestDriftReporterInvalidState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drift")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	statePath := filepath.Join(dir, DefaultDriftStateFilename)
+	assert.NoError(t, ioutil.WriteFile(statePath, []byte(`{"version":1,"results":`), 0644))
+
+	recorder := &Recorder{}
+	reporter, err := NewDriftReporter(recorder, statePath, time.Hour)
+	assert.NoError(t, err)
+	assert.Empty(t, reporter.Results())
+
+	reporter.Report(&Event{AgentRuleID: "cis-docker-1", ResourceType: "docker_daemon", ResourceID: "host", Result: Passed})
+	assert.Len(t, recorder.Events(), 1)
+
+	_, err = NewDriftReporter(recorder, statePath, 0)
+	assert.Error(t, err)
+}
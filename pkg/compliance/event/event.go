@@ -27,4 +27,11 @@ type Event struct {
 	ResourceID       string      `json:"resource_id,omitempty"`
 	Tags             []string    `json:"tags"`
 	Data             interface{} `json:"data,omitempty"`
+	Remediation      string      `json:"remediation,omitempty"`
+	References       []string    `json:"references,omitempty"`
+	// PreviousResult is the result of the previous run of the rule on the
+	// resource, when it changed
+	PreviousResult string `json:"previous_result,omitempty"`
+	// Snapshot is set when the result is reported again without having changed
+	Snapshot bool `json:"snapshot,omitempty"`
 }
@@ -0,0 +1,316 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// ReportFormat is the format of a report of the results of a compliance run
+type ReportFormat string
+
+// Report formats
+const (
+	// JUnitReportFormat reports the rules as JUnit test cases, grouped by framework
+	JUnitReportFormat ReportFormat = "junit"
+	// SARIFReportFormat reports the results in the SARIF 2.1.0 format
+	SARIFReportFormat ReportFormat = "sarif"
+	// CSVReportFormat reports one result per line
+	CSVReportFormat ReportFormat = "csv"
+)
+
+// ReportFormats lists the supported report formats
+var ReportFormats = []ReportFormat{JUnitReportFormat, SARIFReportFormat, CSVReportFormat}
+
+// Recorder is a reporter keeping the events it receives, to write a report of
+// a run once it is complete
+type Recorder struct {
+	sync.Mutex
+	events []*Event
+}
+
+// Report records the event
+func (r *Recorder) Report(event *Event) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// ReportRaw ignores the raw content, which isn't a result
+func (r *Recorder) ReportRaw(content []byte, service string, tags ...string) {
+}
+
+// Events returns the recorded events, sorted by rule and resource
+func (r *Recorder) Events() []*Event {
+	r.Lock()
+	defer r.Unlock()
+
+	events := make([]*Event, len(r.events))
+	copy(events, r.events)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].AgentRuleID != events[j].AgentRuleID {
+			return events[i].AgentRuleID < events[j].AgentRuleID
+		}
+		return events[i].ResourceID < events[j].ResourceID
+	})
+
+	return events
+}
+
+// WriteReport writes a report of the events in the given format
+func WriteReport(w io.Writer, format ReportFormat, events []*Event) error {
+	switch format {
+	case JUnitReportFormat:
+		return writeJUnitReport(w, events)
+	case SARIFReportFormat:
+		return writeSARIFReport(w, events)
+	case CSVReportFormat:
+		return writeCSVReport(w, events)
+	default:
+		return fmt.Errorf("unknown report format `%s`", format)
+	}
+}
+
+// evidence returns the data of the event in JSON
+func (e *Event) evidence() string {
+	if e.Data == nil {
+		return ""
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Sprint(e.Data)
+	}
+	return string(data)
+}
+
+// resource returns the type and the ID of the resource of the event
+func (e *Event) resource() string {
+	return e.ResourceType + ":" + e.ResourceID
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func writeJUnitReport(w io.Writer, events []*Event) error {
+	report := &junitTestSuites{Name: "compliance"}
+	suites := make(map[string]*junitTestSuite)
+
+	for _, e := range events {
+		suite, exists := suites[e.AgentFrameworkID]
+		if !exists {
+			suite = &junitTestSuite{Name: e.AgentFrameworkID}
+			suites[e.AgentFrameworkID] = suite
+			report.Suites = append(report.Suites, suite)
+		}
+
+		testCase := &junitTestCase{
+			Name:      e.AgentRuleID + " " + e.resource(),
+			ClassName: e.AgentFrameworkID,
+		}
+
+		switch e.Result {
+		case Failed:
+			testCase.Failure = &junitMessage{Message: "rule failed on " + e.resource(), Content: e.evidence()}
+			suite.Failures++
+		case Error:
+			testCase.Error = &junitMessage{Message: "rule evaluation error on " + e.resource(), Content: e.evidence()}
+			suite.Errors++
+		default:
+			testCase.SystemOut = e.evidence()
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+	}
+
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+type sarifReport struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Rules   []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID         string                 `json:"id"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []*sarifLocation       `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []*sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+func writeSARIFReport(w io.Writer, events []*Event) error {
+	run := &sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:    "datadog-security-agent",
+				Version: version.AgentVersion,
+				Rules:   []*sarifRule{},
+			},
+		},
+		Results: []*sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, e := range events {
+		if !rules[e.AgentRuleID] {
+			rules[e.AgentRuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &sarifRule{
+				ID:         e.AgentRuleID,
+				Properties: map[string]interface{}{"framework": e.AgentFrameworkID},
+			})
+		}
+
+		result := &sarifResult{
+			RuleID:  e.AgentRuleID,
+			Message: sarifMessage{Text: fmt.Sprintf("%s %s on %s", e.AgentRuleID, e.Result, e.resource())},
+			Locations: []*sarifLocation{{
+				LogicalLocations: []*sarifLogicalLocation{{Name: e.ResourceID, Kind: e.ResourceType}},
+			}},
+		}
+
+		switch e.Result {
+		case Failed:
+			result.Kind, result.Level = "fail", "error"
+		case Error:
+			// SARIF only allows a level other than "none" on "fail" results
+			result.Kind, result.Level = "fail", "warning"
+		default:
+			result.Kind, result.Level = "pass", "none"
+		}
+
+		if e.Data != nil {
+			result.Properties = map[string]interface{}{"evidence": e.Data}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifReport{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	})
+}
+
+func writeCSVReport(w io.Writer, events []*Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"rule_id", "framework", "resource_type", "resource_id", "result", "evidence"}); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		record := []string{e.AgentRuleID, e.AgentFrameworkID, e.ResourceType, e.ResourceID, e.Result, e.evidence()}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ParseReportFormat returns the report format of the given name
+func ParseReportFormat(name string) (ReportFormat, error) {
+	for _, format := range ReportFormats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown report format `%s`, supported formats are junit, sarif and csv", name)
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func newTestRecorder() *Recorder {
+	recorder := &Recorder{}
+	for _, e := range []*Event{
+		{AgentRuleID: "cis-docker-2", AgentFrameworkID: "cis-docker", ResourceType: "docker_container", ResourceID: "abc", Result: Failed, Data: Data{"container.privileged": true}},
+		{AgentRuleID: "cis-docker-1", AgentFrameworkID: "cis-docker", ResourceType: "docker_daemon", ResourceID: "host", Result: Passed},
+		{AgentRuleID: "cis-kubernetes-1", AgentFrameworkID: "cis-kubernetes", ResourceType: "kubernetes_worker_node", ResourceID: "host", Result: Error, Data: Data{"error": "no such file"}},
+	} {
+		recorder.Report(e)
+	}
+	return recorder
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReport(&buf, JUnitReportFormat, newTestRecorder().Events()))
+
+	var report junitTestSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	assert.Len(t, report.Suites, 2)
+
+	docker := report.Suites[0]
+	assert.Equal(t, "cis-docker", docker.Name)
+	assert.Len(t, docker.Cases, 2)
+	assert.Equal(t, "cis-docker-1 docker_daemon:host", docker.Cases[0].Name)
+	assert.Nil(t, docker.Cases[0].Failure)
+	assert.NotNil(t, docker.Cases[1].Failure)
+	assert.Equal(t, `{"container.privileged":true}`, docker.Cases[1].Failure.Content)
+
+	assert.NotNil(t, report.Suites[1].Cases[0].Error)
+}
+
+func TestSARIFReport(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReport(&buf, SARIFReportFormat, newTestRecorder().Events()))
+
+	var report sarifReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, "2.1.0", report.Version)
+	assert.Len(t, report.Runs, 1)
+	assert.Len(t, report.Runs[0].Tool.Driver.Rules, 3)
+
+	results := report.Runs[0].Results
+	assert.Len(t, results, 3)
+	assert.Equal(t, "pass", results[0].Kind)
+	assert.Equal(t, "fail", results[1].Kind)
+	assert.Equal(t, "error", results[1].Level)
+	assert.Equal(t, "abc", results[1].Locations[0].LogicalLocations[0].Name)
+	assert.Equal(t, "fail", results[2].Kind)
+	assert.Equal(t, "warning", results[2].Level)
+}
+
+func TestSARIFReportSchema(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReport(&buf, SARIFReportFormat, newTestRecorder().Events()))
+
+	schema, err := filepath.Abs("testdata/sarif-schema-2.1.0.json")
+	assert.NoError(t, err)
+
+	result, err := gojsonschema.Validate(gojsonschema.NewReferenceLoader("file://"+schema), gojsonschema.NewBytesLoader(buf.Bytes()))
+	assert.NoError(t, err)
+	for _, desc := range result.Errors() {
+		t.Errorf("%s", desc)
+	}
+	assert.True(t, result.Valid())
+}
+
+func TestCSVReport(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteReport(&buf, CSVReportFormat, newTestRecorder().Events()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"rule_id", "framework", "resource_type", "resource_id", "result", "evidence"},
+		{"cis-docker-1", "cis-docker", "docker_daemon", "host", "passed", ""},
+		{"cis-docker-2", "cis-docker", "docker_container", "abc", "failed", `{"container.privileged":true}`},
+		{"cis-kubernetes-1", "cis-kubernetes", "kubernetes_worker_node", "host", "error", `{"error":"no such file"}`},
+	}, records)
+}
+
+func TestParseReportFormat(t *testing.T) {
+	format, err := ParseReportFormat("SARIF")
+	assert.NoError(t, err)
+	assert.Equal(t, SARIFReportFormat, format)
+
+	_, err = ParseReportFormat("html")
+	assert.Error(t, err)
+}
@@ -32,6 +32,14 @@ const (
 	KindKubernetes = ResourceKind("kubernetes")
 	// KindCustom is used for a Custom check
 	KindCustom = ResourceKind("custom")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
+	// KindKernelModule is used for a KernelModule resource
+	KindKernelModule = ResourceKind("kernelModule")
+	// KindSystemd is used for a SystemdUnit resource
+	KindSystemd = ResourceKind("systemd")
+	// KindPackage is used for a Package resource
+	KindPackage = ResourceKind("package")
 )
 
 // Resource describes supported resource types observed by a Rule
@@ -44,6 +52,10 @@ type Resource struct {
 	Docker        *DockerResource     `yaml:"docker,omitempty"`
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
+	Sysctl        *Sysctl             `yaml:"sysctl,omitempty"`
+	KernelModule  *KernelModule       `yaml:"kernelModule,omitempty"`
+	Systemd       *SystemdUnit        `yaml:"systemd,omitempty"`
+	Package       *Package            `yaml:"package,omitempty"`
 	Condition     string              `yaml:"condition"`
 	Fallback      *Fallback           `yaml:"fallback,omitempty"`
 }
@@ -67,6 +79,14 @@ func (r *Resource) Kind() ResourceKind {
 		return KindKubernetes
 	case r.Custom != nil:
 		return KindCustom
+	case r.Sysctl != nil:
+		return KindSysctl
+	case r.KernelModule != nil:
+		return KindKernelModule
+	case r.Systemd != nil:
+		return KindSystemd
+	case r.Package != nil:
+		return KindPackage
 	default:
 		return KindInvalid
 	}
@@ -257,3 +277,55 @@ type Custom struct {
 	Name      string            `yaml:"name"`
 	Variables map[string]string `yaml:"variables,omitempty"`
 }
+
+// Fields available for Sysctl
+const (
+	SysctlFieldKey   = "sysctl.key"
+	SysctlFieldValue = "sysctl.value"
+)
+
+// Sysctl describes a kernel parameter, like `net.ipv4.ip_forward`
+type Sysctl struct {
+	Key string `yaml:"key"`
+}
+
+// Fields available for KernelModule
+const (
+	KernelModuleFieldName        = "kernelModule.name"
+	KernelModuleFieldLoaded      = "kernelModule.loaded"
+	KernelModuleFieldDisabled    = "kernelModule.disabled"
+	KernelModuleFieldBlacklisted = "kernelModule.blacklisted"
+)
+
+// KernelModule describes a kernel module, whether it is loaded, and whether
+// the modprobe configuration disables it or blacklists it
+type KernelModule struct {
+	Name string `yaml:"name"`
+}
+
+// Fields available for SystemdUnit
+const (
+	SystemdFieldUnit    = "systemd.unit"
+	SystemdFieldExists  = "systemd.exists"
+	SystemdFieldEnabled = "systemd.enabled"
+	SystemdFieldMasked  = "systemd.masked"
+	SystemdFieldActive  = "systemd.active"
+)
+
+// SystemdUnit describes the state of a systemd unit, like `auditd.service`
+type SystemdUnit struct {
+	Unit string `yaml:"unit"`
+}
+
+// Fields available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldInstalled = "package.installed"
+	PackageFieldVersion   = "package.version"
+)
+
+// Package describes a package, installed or not, of the dpkg, apk or rpm package
+// managers
+type Package struct {
+	Name string `yaml:"name"`
+}
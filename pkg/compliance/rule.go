@@ -16,6 +16,10 @@ type Rule struct {
 	HostSelector string        `yaml:"hostSelector,omitempty"`
 	ResourceType string        `yaml:"resourceType,omitempty"`
 	Resources    []Resource    `yaml:"resources,omitempty"`
+	// Remediation describes how to fix the resources failing the rule
+	Remediation string `yaml:"remediation,omitempty"`
+	// References lists links to the documentation of the rule
+	References []string `yaml:"references,omitempty"`
 }
 
 // RuleScope defines scope for applicability of a rule
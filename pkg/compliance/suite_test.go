@@ -44,6 +44,8 @@ func TestParseSuite(t *testing.T) {
 								Condition: `file.permissions == 0644`,
 							},
 						},
+						Remediation: "chmod 644 /etc/docker/daemon.json\n",
+						References:  []string{"https://www.cisecurity.org/benchmark/docker/"},
 					},
 				},
 			},
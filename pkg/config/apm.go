@@ -29,6 +29,13 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.obfuscation.cassandra.enabled")
+	config.SetKnown("apm_config.obfuscation.graphql.enabled")
+	config.SetKnown("apm_config.obfuscation.graphql.keep_values")
+	config.SetKnown("apm_config.obfuscation.grpc.enabled")
+	config.SetKnown("apm_config.obfuscation.grpc.metadata")
+	config.SetKnown("apm_config.obfuscation.aws.enabled")
+	config.SetKnown("apm_config.obfuscation.aws.keep_values")
 	config.SetKnown("apm_config.filter_tags.require")
 	config.SetKnown("apm_config.filter_tags.reject")
 	config.SetKnown("apm_config.extra_sample_rate")
@@ -65,6 +72,8 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.max_events_per_second", "DD_APM_MAX_EPS", "DD_MAX_EPS")
 	config.BindEnv("apm_config.max_traces_per_second", "DD_APM_MAX_TPS", "DD_MAX_TPS")
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
+	config.BindEnv("apm_config.prometheus_metrics.enabled", "DD_APM_PROMETHEUS_METRICS_ENABLED")
+	config.BindEnv("apm_config.debug_capture.enabled", "DD_APM_DEBUG_CAPTURE_ENABLED")
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")
 	config.BindEnv("apm_config.env", "DD_APM_ENV")
 	config.BindEnv("apm_config.apm_non_local_traffic", "DD_APM_NON_LOCAL_TRAFFIC")
@@ -70,6 +70,9 @@ const (
 	// DefaultRuntimePoliciesDir is the default policies directory used by the runtime security module
 	DefaultRuntimePoliciesDir = "/etc/datadog-agent/runtime-security.d"
 
+	// DefaultRuntimeEventsExportFile is the default file the security agent exports the runtime security events to
+	DefaultRuntimeEventsExportFile = "/var/log/datadog/runtime-security-events.json"
+
 	// DefaultLogsSenderBackoffFactor is the default logs sender backoff randomness factor
 	DefaultLogsSenderBackoffFactor = 2.0
 
@@ -188,6 +191,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("conf_path", ".")
 	config.BindEnvAndSetDefault("confd_path", defaultConfdPath)
 	config.BindEnvAndSetDefault("additional_checksd", defaultAdditionalChecksPath)
+	config.BindEnvAndSetDefault("external_checksd", "")
 	config.BindEnvAndSetDefault("jmx_log_file", "")
 	config.BindEnvAndSetDefault("log_payloads", false)
 	config.BindEnvAndSetDefault("log_file", "")
@@ -212,6 +216,12 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_timeout", 0) // in seconds, 0 disables the timeout
+	config.BindEnvAndSetDefault("check_timeouts", map[string]string{})
+	config.BindEnvAndSetDefault("check_runners_autoscaling.enabled", false)
+	config.BindEnvAndSetDefault("check_runners_autoscaling.min_workers", DefaultNumWorkers)
+	config.BindEnvAndSetDefault("check_runners_autoscaling.max_workers", MaxNumWorkers)
+	config.BindEnvAndSetDefault("check_runners_autoscaling.interval", 30) // in seconds
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -479,6 +489,18 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_node_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("kubernetes_namespace_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
+	config.BindEnv("tagger_rules") // Defines rules adding or removing tags on the entities matching them
+	config.SetEnvKeyTransformer("tagger_rules", func(in string) interface{} {
+		var rules []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Warnf(`"tagger_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+	config.BindEnvAndSetDefault("tagger_snapshot.enabled", false)
+	config.BindEnvAndSetDefault("tagger_snapshot.path", "")        // defaults to tagger-<flavor>.snapshot in run_path
+	config.BindEnvAndSetDefault("tagger_snapshot.interval", 60)    // in seconds
+	config.BindEnvAndSetDefault("tagger_snapshot.expiration", 600) // in seconds
 
 	// CRI
 	config.BindEnvAndSetDefault("cri_socket_path", "")              // empty is disabled
@@ -525,6 +547,9 @@ func InitConfig(config Config) {
 		return promChecks
 	})
 
+	// Process listener
+	config.BindEnvAndSetDefault("process_listener.refresh_interval", 10) // in seconds
+
 	// SNMP
 	config.SetKnown("snmp_listener.discovery_interval")
 	config.SetKnown("snmp_listener.allowed_failures")
@@ -870,6 +895,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("compliance_config.check_max_events_per_run", 100)
 	config.BindEnvAndSetDefault("compliance_config.dir", "/etc/datadog-agent/compliance.d")
 	config.BindEnvAndSetDefault("compliance_config.run_path", defaultRunPath)
+	config.BindEnvAndSetDefault("compliance_config.drift.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.drift.snapshot_interval", 24*time.Hour)
 	bindEnvAndSetLogsConfigKeys(config, "compliance_config.endpoints.", false)
 
 	// Datadog security agent (runtime)
@@ -878,6 +905,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.erpc_dentry_resolution_enabled", true)
 	config.BindEnvAndSetDefault("runtime_security_config.map_dentry_resolution_enabled", true)
 	config.BindEnvAndSetDefault("runtime_security_config.policies.dir", DefaultRuntimePoliciesDir)
+	config.BindEnvAndSetDefault("runtime_security_config.policies.override_dirs", []string{})
 	config.BindEnvAndSetDefault("runtime_security_config.socket", "/opt/datadog-agent/run/runtime-security.sock")
 	config.BindEnvAndSetDefault("runtime_security_config.enable_approvers", true)
 	config.BindEnvAndSetDefault("runtime_security_config.enable_kernel_filters", true)
@@ -900,6 +928,22 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("runtime_security_config.log_patterns", []string{})
 	bindEnvAndSetLogsConfigKeys(config, "runtime_security_config.endpoints.", true)
 	config.BindEnvAndSetDefault("runtime_security_config.self_test.enabled", true)
+	config.BindEnvAndSetDefault("runtime_security_config.export.file.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.export.file.path", DefaultRuntimeEventsExportFile)
+	config.BindEnvAndSetDefault("runtime_security_config.export.file.max_size_mb", 100)
+	config.BindEnvAndSetDefault("runtime_security_config.export.file.max_backups", 5)
+	config.BindEnvAndSetDefault("runtime_security_config.export.syslog.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.export.syslog.network", "")
+	config.BindEnvAndSetDefault("runtime_security_config.export.syslog.address", "")
+	config.BindEnvAndSetDefault("runtime_security_config.export.syslog.tag", "datadog-runtime-security")
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.enabled", false)
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.url", "")
+	config.SetKnown("runtime_security_config.export.webhook.headers")
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.batch_size", 100)
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.flush_interval", 5)
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.max_retries", 3)
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.timeout", 10)
+	config.BindEnvAndSetDefault("runtime_security_config.export.webhook.queue_size", 10000)
 
 	// Serverless Agent
 	config.BindEnvAndSetDefault("serverless.logs_enabled", true)
@@ -7,6 +7,7 @@ package flare
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -179,7 +180,22 @@ func zipComplianceFiles(tempDir, hostname string, permsInfos permissionsInfos) e
 
 func zipRuntimeFiles(tempDir, hostname string, permsInfos permissionsInfos) error {
 	runtimeDir := config.Datadog.GetString("runtime_security_config.policies.dir")
+	if err := zipRuntimeDir(runtimeDir, filepath.Join(tempDir, hostname, "runtime-security.d"), permsInfos); err != nil {
+		return err
+	}
+
+	// the override directories are copied in the order they are loaded
+	for i, overrideDir := range config.Datadog.GetStringSlice("runtime_security_config.policies.override_dirs") {
+		dstDir := filepath.Join(tempDir, hostname, fmt.Sprintf("runtime-security.d.override-%d", i+1))
+		if err := zipRuntimeDir(overrideDir, dstDir, permsInfos); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+func zipRuntimeDir(runtimeDir, dstDir string, permsInfos permissionsInfos) error {
 	if permsInfos != nil {
 		addParentPerms(runtimeDir, permsInfos)
 	}
@@ -192,7 +208,7 @@ func zipRuntimeFiles(tempDir, hostname string, permsInfos permissionsInfos) erro
 			return nil
 		}
 
-		dst := filepath.Join(tempDir, hostname, "runtime-security.d", f.Name())
+		dst := filepath.Join(dstDir, f.Name())
 
 		if permsInfos != nil {
 			permsInfos.add(src)
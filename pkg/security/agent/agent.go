@@ -25,6 +25,7 @@ import (
 type RuntimeSecurityAgent struct {
 	hostname      string
 	reporter      event.Reporter
+	exporters     []EventExporter
 	conn          *grpc.ClientConn
 	running       atomic.Value
 	wg            sync.WaitGroup
@@ -52,9 +53,15 @@ func NewRuntimeSecurityAgent(hostname string, reporter event.Reporter) (*Runtime
 		return nil, errors.Errorf("failed to initialize the telemetry reporter")
 	}
 
+	exporters, err := NewEventExporters()
+	if err != nil {
+		return nil, err
+	}
+
 	return &RuntimeSecurityAgent{
 		conn:      conn,
 		reporter:  reporter,
+		exporters: exporters,
 		hostname:  hostname,
 		telemetry: tel,
 	}, nil
@@ -77,6 +84,10 @@ func (rsa *RuntimeSecurityAgent) Stop() {
 	rsa.running.Store(false)
 	rsa.wg.Wait()
 	rsa.conn.Close()
+
+	for _, exporter := range rsa.exporters {
+		exporter.Stop()
+	}
 }
 
 // StartEventListener starts listening for new events from system-probe
@@ -124,8 +135,21 @@ func (rsa *RuntimeSecurityAgent) StartEventListener() {
 
 // DispatchEvent dispatches a security event message to the subsytems of the runtime security agent
 func (rsa *RuntimeSecurityAgent) DispatchEvent(evt *api.SecurityEventMessage) {
-	// For now simply log to Datadog
 	rsa.reporter.ReportRaw(evt.GetData(), evt.Service, evt.GetTags()...)
+
+	if len(rsa.exporters) == 0 {
+		return
+	}
+
+	// mirror the event to the local destinations
+	data, err := marshalExportedEvent(evt)
+	if err != nil {
+		log.Errorf("failed to export event of rule %s: %v", evt.GetRuleID(), err)
+		return
+	}
+	for _, exporter := range rsa.exporters {
+		exporter.Export(data)
+	}
 }
 
 // GetStatus returns the current status on the agent
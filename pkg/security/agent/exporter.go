@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/security/api"
+)
+
+// EventExporter describes a local destination the runtime security events are
+// mirrored to, in addition to the Datadog intake
+type EventExporter interface {
+	// Export exports the JSON representation of an event
+	Export(data []byte)
+	// Stop flushes the pending events and releases the resources of the exporter
+	Stop()
+}
+
+// exportedEvent is the JSON envelope of the exported events, holding the
+// service and the tags the intake attaches to them
+type exportedEvent struct {
+	Service string          `json:"service"`
+	Tags    []string        `json:"tags"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// marshalExportedEvent returns the JSON representation of an event message
+// as it is exported
+func marshalExportedEvent(evt *api.SecurityEventMessage) ([]byte, error) {
+	tags := evt.GetTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return json.Marshal(exportedEvent{
+		Service: evt.GetService(),
+		Tags:    tags,
+		Event:   json.RawMessage(evt.GetData()),
+	})
+}
+
+// NewEventExporters returns the exporters enabled by the configuration
+func NewEventExporters() ([]EventExporter, error) {
+	var exporters []EventExporter
+
+	stopAll := func() {
+		for _, exporter := range exporters {
+			exporter.Stop()
+		}
+	}
+
+	if coreconfig.Datadog.GetBool("runtime_security_config.export.file.enabled") {
+		exporter, err := NewFileExporter(
+			coreconfig.Datadog.GetString("runtime_security_config.export.file.path"),
+			coreconfig.Datadog.GetInt64("runtime_security_config.export.file.max_size_mb")*1024*1024,
+			coreconfig.Datadog.GetInt("runtime_security_config.export.file.max_backups"),
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create the file exporter")
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	if coreconfig.Datadog.GetBool("runtime_security_config.export.syslog.enabled") {
+		exporter, err := NewSyslogExporter(
+			coreconfig.Datadog.GetString("runtime_security_config.export.syslog.network"),
+			coreconfig.Datadog.GetString("runtime_security_config.export.syslog.address"),
+			coreconfig.Datadog.GetString("runtime_security_config.export.syslog.tag"),
+		)
+		if err != nil {
+			stopAll()
+			return nil, errors.Wrap(err, "failed to create the syslog exporter")
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	if coreconfig.Datadog.GetBool("runtime_security_config.export.webhook.enabled") {
+		exporter, err := NewWebhookExporter(WebhookConfig{
+			URL:           coreconfig.Datadog.GetString("runtime_security_config.export.webhook.url"),
+			Headers:       coreconfig.Datadog.GetStringMapString("runtime_security_config.export.webhook.headers"),
+			BatchSize:     coreconfig.Datadog.GetInt("runtime_security_config.export.webhook.batch_size"),
+			FlushInterval: time.Duration(coreconfig.Datadog.GetInt("runtime_security_config.export.webhook.flush_interval")) * time.Second,
+			MaxRetries:    coreconfig.Datadog.GetInt("runtime_security_config.export.webhook.max_retries"),
+			Timeout:       time.Duration(coreconfig.Datadog.GetInt("runtime_security_config.export.webhook.timeout")) * time.Second,
+			QueueSize:     coreconfig.Datadog.GetInt("runtime_security_config.export.webhook.queue_size"),
+		})
+		if err != nil {
+			stopAll()
+			return nil, errors.Wrap(err, "failed to create the webhook exporter")
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	return exporters, nil
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// FileExporter writes the events to a file, one JSON object per line. The file
+// is rotated once it reaches its maximum size, the previous files being kept
+// with the `.1`, `.2`, ... suffixes, the most recent first.
+type FileExporter struct {
+	sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileExporter returns an exporter writing to the given file
+func NewFileExporter(path string, maxSize int64, maxBackups int) (*FileExporter, error) {
+	if path == "" {
+		return nil, errors.New("no path defined")
+	}
+	if maxSize <= 0 {
+		return nil, errors.New("the maximum size must be positive")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	e := &FileExporter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := e.open(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *FileExporter) open() error {
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	e.file, e.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the previous files, dropping the oldest one, and starts a new
+// file
+func (e *FileExporter) rotate() error {
+	if err := e.file.Close(); err != nil {
+		log.Warnf("failed to close %s: %s", e.path, err)
+	}
+	e.file = nil
+
+	backup := func(i int) string {
+		return fmt.Sprintf("%s.%d", e.path, i)
+	}
+
+	if e.maxBackups > 0 {
+		for i := e.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(e.path, backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return e.open()
+}
+
+// Export writes the event to the file
+func (e *FileExporter) Export(data []byte) {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.file == nil {
+		// a previous rotation failed
+		if err := e.open(); err != nil {
+			log.Errorf("failed to open %s: %s", e.path, err)
+			return
+		}
+	}
+
+	if e.size > 0 && e.size+int64(len(data))+1 > e.maxSize {
+		if err := e.rotate(); err != nil {
+			log.Errorf("failed to rotate %s: %s", e.path, err)
+			return
+		}
+	}
+
+	line := make([]byte, len(data)+1)
+	copy(line, data)
+	line[len(data)] = '\n'
+
+	n, err := e.file.Write(line)
+	e.size += int64(n)
+	if err != nil {
+		log.Errorf("failed to write the event to %s: %s", e.path, err)
+	}
+}
+
+// Stop closes the file
+func (e *FileExporter) Stop() {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.file != nil {
+		e.file.Close()
+		e.file = nil
+	}
+}
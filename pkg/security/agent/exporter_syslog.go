@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !windows
+
+package agent
+
+import (
+	"log/syslog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// SyslogExporter sends the events to a syslog destination, with the info
+// severity and the security facility
+type SyslogExporter struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogExporter returns an exporter sending to the syslog server at the
+// given address, or to the local syslog server if the network is empty
+func NewSyslogExporter(network, address, tag string) (*SyslogExporter, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogExporter{writer: writer}, nil
+}
+
+// Export sends the event, the writer reconnecting if the connection was lost
+func (e *SyslogExporter) Export(data []byte) {
+	if _, err := e.writer.Write(data); err != nil {
+		log.Errorf("failed to send the event to syslog: %s", err)
+	}
+}
+
+// Stop closes the connection
+func (e *SyslogExporter) Stop() {
+	e.writer.Close()
+}
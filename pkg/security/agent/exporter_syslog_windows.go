@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build windows
+
+package agent
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SyslogExporter is not supported on Windows
+type SyslogExporter struct{}
+
+// NewSyslogExporter returns an error, syslog being not supported on Windows
+func NewSyslogExporter(network, address, tag string) (*SyslogExporter, error) {
+	return nil, errors.New("syslog export is not supported on Windows")
+}
+
+// Export does nothing
+func (e *SyslogExporter) Export(data []byte) {}
+
+// Stop does nothing
+func (e *SyslogExporter) Stop() {}
@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/security/api"
+)
+
+type testExporter struct {
+	events [][]byte
+}
+
+func (e *testExporter) Export(data []byte) {
+	e.events = append(e.events, data)
+}
+
+func (e *testExporter) Stop() {}
+
+func TestDispatchEventExport(t *testing.T) {
+	reporter := &mocks.Reporter{}
+	reporter.On("ReportRaw", mock.Anything, "runtime-security-agent", "rule_id:exec", "container_id:abc").Once()
+
+	exporter := &testExporter{}
+	rsa := &RuntimeSecurityAgent{
+		reporter:  reporter,
+		exporters: []EventExporter{exporter},
+	}
+
+	rsa.DispatchEvent(&api.SecurityEventMessage{
+		RuleID:  "exec",
+		Data:    []byte(`{"id":1}`),
+		Tags:    []string{"rule_id:exec", "container_id:abc"},
+		Service: "runtime-security-agent",
+	})
+	reporter.AssertExpectations(t)
+
+	require.Len(t, exporter.events, 1)
+	assert.JSONEq(t, `{"service":"runtime-security-agent","tags":["rule_id:exec","container_id:abc"],"event":{"id":1}}`, string(exporter.events[0]))
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exporter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events", "events.json")
+	exporter, err := NewFileExporter(path, 20, 2)
+	require.NoError(t, err)
+
+	// every event but the first one triggers a rotation
+	for _, event := range []string{`{"id":1,"a":"b"}`, `{"id":2,"a":"b"}`, `{"id":3,"a":"b"}`, `{"id":4,"a":"b"}`} {
+		exporter.Export([]byte(event))
+	}
+	exporter.Stop()
+
+	for name, expected := range map[string]string{
+		"events.json":   `{"id":4,"a":"b"}` + "\n",
+		"events.json.1": `{"id":3,"a":"b"}` + "\n",
+		"events.json.2": `{"id":2,"a":"b"}` + "\n",
+	} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, "events", name))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content), name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	// the size of an existing file is taken into account
+	exporter, err = NewFileExporter(path, 20, 2)
+	require.NoError(t, err)
+	exporter.Export([]byte(`{"id":5}`))
+	exporter.Stop()
+
+	content, err := ioutil.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":4,"a":"b"}`+"\n", string(content))
+}
+
+func TestWebhookExporter(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		batches  [][]map[string]interface{}
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		// the first request is throttled
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		var batch []map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	exporter := newWebhookExporter(WebhookConfig{
+		URL:           server.URL,
+		Headers:       map[string]string{"X-Token": "secret"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		MaxRetries:    1,
+		Timeout:       time.Second,
+		QueueSize:     10,
+	}, func(int) time.Duration { return 0 })
+
+	for _, event := range []string{`{"id":1}`, `{"id":2}`, `{"id":3}`} {
+		exporter.Export([]byte(event))
+	}
+
+	// the last event is sent when stopping
+	exporter.Stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.Equal(t, 3, requests)
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Equal(t, float64(1), batches[0][0]["id"])
+	assert.Len(t, batches[1], 1)
+	assert.Equal(t, float64(3), batches[1][0]["id"])
+}
+
+func TestWebhookExporterPermanentError(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	exporter := newWebhookExporter(WebhookConfig{
+		URL:           server.URL,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		MaxRetries:    3,
+		QueueSize:     10,
+	}, func(int) time.Duration { return 0 })
+
+	exporter.Export([]byte(`{"id":1}`))
+	exporter.Stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	// the client errors aren't retried
+	assert.Equal(t, 1, requests)
+}
+
+func TestWebhookExporterStopTimeout(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests int
+	)
+	received := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	exporter := newWebhookExporter(WebhookConfig{
+		URL:           server.URL,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		MaxRetries:    3,
+		QueueSize:     10,
+	}, func(int) time.Duration { return time.Hour })
+	exporter.stopTimeout = 10 * time.Millisecond
+
+	exporter.Export([]byte(`{"id":1}`))
+	<-received
+
+	// the retry is abandoned once the stop timeout expires
+	done := make(chan struct{})
+	go func() {
+		exporter.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the exporter didn't stop after its stop timeout")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, 1, requests)
+}
+
+func TestNewWebhookExporterInvalidConfig(t *testing.T) {
+	_, err := NewWebhookExporter(WebhookConfig{BatchSize: 1, FlushInterval: time.Second, QueueSize: 1})
+	assert.Error(t, err)
+
+	_, err = NewWebhookExporter(WebhookConfig{URL: "http://localhost", FlushInterval: time.Second, QueueSize: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/util/backoff"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// webhookStopTimeout is the maximum duration of the sending of the pending
+// events, including their retries, when the webhook exporter is stopped
+const webhookStopTimeout = 10 * time.Second
+
+// WebhookConfig holds the configuration of a webhook exporter
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	// BatchSize is the maximum number of events sent by request
+	BatchSize int
+	// FlushInterval is the maximum delay before the pending events are sent
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a failed request is retried before its
+	// events are dropped
+	MaxRetries int
+	Timeout    time.Duration
+	// QueueSize is the number of events waiting to be sent above which the
+	// events are dropped
+	QueueSize int
+}
+
+// WebhookExporter sends the events to an HTTP endpoint, as JSON arrays posted
+// by batches. The failed requests are retried with an exponential backoff.
+type WebhookExporter struct {
+	// dropped is first to be 64-bit aligned for the atomic operations
+	dropped uint64
+
+	config      WebhookConfig
+	client      *http.Client
+	events      chan []byte
+	retryDelay  func(retries int) time.Duration
+	stopTimeout time.Duration
+	stop        chan struct{}
+	// ctx is cancelled once the stop timeout expires, to abort the requests
+	// and the retries in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhookExporter returns a started webhook exporter
+func NewWebhookExporter(config WebhookConfig) (*WebhookExporter, error) {
+	if config.URL == "" {
+		return nil, errors.New("no URL defined")
+	}
+	if config.BatchSize <= 0 || config.FlushInterval <= 0 || config.QueueSize <= 0 {
+		return nil, errors.New("the batch size, the flush interval and the queue size must be positive")
+	}
+
+	policy := backoff.NewPolicy(2, 1, 30, 0, false)
+	return newWebhookExporter(config, policy.GetBackoffDuration), nil
+}
+
+func newWebhookExporter(config WebhookConfig, retryDelay func(retries int) time.Duration) *WebhookExporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &WebhookExporter{
+		config:      config,
+		client:      &http.Client{Timeout: config.Timeout},
+		events:      make(chan []byte, config.QueueSize),
+		retryDelay:  retryDelay,
+		stopTimeout: webhookStopTimeout,
+		stop:        make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.run()
+	}()
+
+	return e
+}
+
+func (e *WebhookExporter) run() {
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch [][]byte
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = nil
+		}
+	}
+
+	for {
+		select {
+		case data := <-e.events:
+			batch = append(batch, data)
+			if len(batch) >= e.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			// send the queued events before leaving
+			for {
+				select {
+				case data := <-e.events:
+					batch = append(batch, data)
+					if len(batch) >= e.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send posts a batch of events, retrying on network errors, server errors and
+// throttling. When the exporter is stopped, the retries go on until the stop
+// timeout expires.
+func (e *WebhookExporter) send(batch [][]byte) {
+	if dropped := atomic.SwapUint64(&e.dropped, 0); dropped > 0 {
+		log.Warnf("%d events dropped by the webhook exporter, its queue being full", dropped)
+	}
+
+	body := append([]byte{'['}, bytes.Join(batch, []byte{','})...)
+	body = append(body, ']')
+
+	for retries := 0; ; retries++ {
+		retry, err := e.post(body)
+		if err == nil {
+			return
+		}
+
+		if !retry || retries >= e.config.MaxRetries {
+			log.Errorf("failed to send %d events to the webhook: %s", len(batch), err)
+			return
+		}
+		log.Debugf("failed to send %d events to the webhook, retrying: %s", len(batch), err)
+
+		timer := time.NewTimer(e.retryDelay(retries + 1))
+		select {
+		case <-timer.C:
+		case <-e.ctx.Done():
+			timer.Stop()
+			log.Errorf("failed to send %d events to the webhook before stopping: %s", len(batch), err)
+			return
+		}
+	}
+}
+
+// post sends the body and returns whether the request can be retried if it
+// failed
+func (e *WebhookExporter) post(body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(e.ctx, http.MethodPost, e.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status: %s", resp.Status)
+	default:
+		return false, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+}
+
+// Export queues the event, dropping it if the queue is full
+func (e *WebhookExporter) Export(data []byte) {
+	select {
+	case e.events <- data:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// Stop sends the queued events and stops the exporter. The events that can't
+// be sent within the stop timeout are dropped.
+func (e *WebhookExporter) Stop() {
+	close(e.stop)
+	timer := time.AfterFunc(e.stopTimeout, e.cancel)
+	e.wg.Wait()
+	timer.Stop()
+	e.cancel()
+}
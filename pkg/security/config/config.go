@@ -30,6 +30,9 @@ type Config struct {
 	RuntimeEnabled bool
 	// PoliciesDir defines the folder in which the policy files are located
 	PoliciesDir string
+	// PoliciesOverrideDirs defines the folders of the policy files loaded after the ones of PoliciesDir, in order,
+	// which can override their rules and macros
+	PoliciesOverrideDirs []string
 	// EnableKernelFilters defines if in-kernel filtering should be activated or not
 	EnableKernelFilters bool
 	// EnableApprovers defines if in-kernel approvers should be activated or not
@@ -78,8 +81,13 @@ type Config struct {
 	MapDentryResolutionEnabled bool
 	// RemoteTaggerEnabled defines whether the remote tagger is enabled
 	RemoteTaggerEnabled bool
+	// PodLabelsAsTags maps the Kubernetes pod labels to the tags set by the tagger, from which
+	// the labels of the pods are resolved
+	PodLabelsAsTags map[string]string
 	// HostServiceName string
 	HostServiceName string
+	// HostTags are the configured tags of the host, matched against the filters of the rules
+	HostTags []string
 	// LogPatterns pattern to be used by the logger for trace level
 	LogPatterns []string
 	// SelfTestEnabled defines if the self tester should be enabled (useful for tests for example)
@@ -91,6 +99,11 @@ func (c *Config) IsEnabled() bool {
 	return c.RuntimeEnabled || c.FIMEnabled
 }
 
+// GetPoliciesDirs returns the folders of the policy files, in the order they are loaded
+func (c *Config) GetPoliciesDirs() []string {
+	return append([]string{c.PoliciesDir}, c.PoliciesOverrideDirs...)
+}
+
 // NewConfig returns a new Config object
 func NewConfig(cfg *config.Config) (*Config, error) {
 	c := &Config{
@@ -104,6 +117,7 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		SocketPath:                         aconfig.Datadog.GetString("runtime_security_config.socket"),
 		SyscallMonitor:                     aconfig.Datadog.GetBool("runtime_security_config.syscall_monitor.enabled"),
 		PoliciesDir:                        aconfig.Datadog.GetString("runtime_security_config.policies.dir"),
+		PoliciesOverrideDirs:               aconfig.Datadog.GetStringSlice("runtime_security_config.policies.override_dirs"),
 		EventServerBurst:                   aconfig.Datadog.GetInt("runtime_security_config.event_server.burst"),
 		EventServerRate:                    aconfig.Datadog.GetInt("runtime_security_config.event_server.rate"),
 		EventServerRetention:               aconfig.Datadog.GetInt("runtime_security_config.event_server.retention"),
@@ -120,6 +134,7 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		ERPCDentryResolutionEnabled:        aconfig.Datadog.GetBool("runtime_security_config.erpc_dentry_resolution_enabled"),
 		MapDentryResolutionEnabled:         aconfig.Datadog.GetBool("runtime_security_config.map_dentry_resolution_enabled"),
 		RemoteTaggerEnabled:                aconfig.Datadog.GetBool("runtime_security_config.remote_tagger"),
+		PodLabelsAsTags:                    aconfig.Datadog.GetStringMapString("kubernetes_pod_labels_as_tags"),
 		LogPatterns:                        aconfig.Datadog.GetStringSlice("runtime_security_config.log_patterns"),
 		SelfTestEnabled:                    aconfig.Datadog.GetBool("runtime_security_config.self_test.enabled"),
 	}
@@ -149,7 +164,9 @@ func NewConfig(cfg *config.Config) (*Config, error) {
 		c.MapDentryResolutionEnabled = true
 	}
 
-	serviceName := utils.GetTagValue("service", aconfig.GetConfiguredTags(true))
+	c.HostTags = aconfig.GetConfiguredTags(true)
+
+	serviceName := utils.GetTagValue("service", c.HostTags)
 	if len(serviceName) > 0 {
 		c.HostServiceName = fmt.Sprintf("service:%s", serviceName)
 	}
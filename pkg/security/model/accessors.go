@@ -6,6 +6,7 @@ package model
 
 import (
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
@@ -417,6 +418,16 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "container.image":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).ContainerContext.Image
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
 	case "container.tags":
 		return &eval.StringArrayEvaluator{
 
@@ -842,6 +853,26 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "kube.pod.name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).KubeContext.Pod.Name
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
+	case "kube.pod.namespace":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).KubeContext.Pod.Namespace
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
 	case "link.file.destination.filesystem":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -3684,6 +3715,17 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		key := strings.TrimPrefix(field, "kube.pod.labels.")
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				return (*Event)(ctx.Object).KubeContext.Pod.Labels[key]
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+	}
+
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -3760,6 +3802,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"container.id",
 
+		"container.image",
+
 		"container.tags",
 
 		"exec.args",
@@ -3844,6 +3888,10 @@ func (e *Event) GetFields() []eval.Field {
 
 		"exec.user",
 
+		"kube.pod.name",
+
+		"kube.pod.namespace",
+
 		"link.file.destination.filesystem",
 
 		"link.file.destination.gid",
@@ -4455,6 +4503,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.ContainerContext.ID, nil
 
+	case "container.image":
+
+		return e.ContainerContext.Image, nil
+
 	case "container.tags":
 
 		return e.ContainerContext.Tags, nil
@@ -4623,6 +4675,14 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.Exec.Process.Credentials.User, nil
 
+	case "kube.pod.name":
+
+		return e.KubeContext.Pod.Name, nil
+
+	case "kube.pod.namespace":
+
+		return e.KubeContext.Pod.Namespace, nil
+
 	case "link.file.destination.filesystem":
 
 		return e.Link.Target.Filesytem, nil
@@ -6169,6 +6229,11 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		key := strings.TrimPrefix(field, "kube.pod.labels.")
+		return e.KubeContext.Pod.Labels[key], nil
+	}
+
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -6280,6 +6345,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "container.id":
 		return "*", nil
 
+	case "container.image":
+		return "*", nil
+
 	case "container.tags":
 		return "*", nil
 
@@ -6406,6 +6474,12 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "exec.user":
 		return "exec", nil
 
+	case "kube.pod.name":
+		return "*", nil
+
+	case "kube.pod.namespace":
+		return "*", nil
+
 	case "link.file.destination.filesystem":
 		return "link", nil
 
@@ -7107,6 +7181,10 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		return "*", nil
+	}
+
 	return "", &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -7253,6 +7331,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "container.image":
+
+		return reflect.String, nil
+
 	case "container.tags":
 
 		return reflect.String, nil
@@ -7421,6 +7503,14 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "kube.pod.name":
+
+		return reflect.String, nil
+
+	case "kube.pod.namespace":
+
+		return reflect.String, nil
+
 	case "link.file.destination.filesystem":
 
 		return reflect.String, nil
@@ -8355,6 +8445,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		return reflect.String, nil
+	}
+
 	return reflect.Invalid, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -8720,6 +8814,17 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "container.image":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "ContainerContext.Image"}
+		}
+		e.ContainerContext.Image = str
+
+		return nil
+
 	case "container.tags":
 
 		var ok bool
@@ -9154,6 +9259,28 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "kube.pod.name":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Name"}
+		}
+		e.KubeContext.Pod.Name = str
+
+		return nil
+
+	case "kube.pod.namespace":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Namespace"}
+		}
+		e.KubeContext.Pod.Namespace = str
+
+		return nil
+
 	case "link.file.destination.filesystem":
 
 		var ok bool
@@ -11690,5 +11817,17 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Labels"}
+		}
+		if e.KubeContext.Pod.Labels == nil {
+			e.KubeContext.Pod.Labels = make(map[string]string)
+		}
+		e.KubeContext.Pod.Labels[strings.TrimPrefix(field, "kube.pod.labels.")] = str
+		return nil
+	}
+
 	return &eval.ErrFieldNotFound{Field: field}
 }
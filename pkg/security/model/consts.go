@@ -12,6 +12,7 @@ import (
 	"math"
 	"math/bits"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -358,6 +359,25 @@ func bitmaskToStringArray(bitmask int, intToStrMap map[int]string) []string {
 	return strs
 }
 
+// stringArrayToBitmask is the reverse of bitmaskU64ToStringArray, the unknown
+// bits being formatted as a number
+func stringArrayToBitmask(strs []string, strToIntMap map[string]uint64) (uint64, error) {
+	var bitmask uint64
+	for _, s := range strs {
+		if v, found := strToIntMap[s]; found {
+			bitmask |= v
+			continue
+		}
+
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unknown value `%s`", s)
+		}
+		bitmask |= v
+	}
+	return bitmask, nil
+}
+
 func bitmaskToString(bitmask int, intToStrMap map[int]string) string {
 	return strings.Join(bitmaskToStringArray(bitmask, intToStrMap), " | ")
 }
@@ -407,6 +427,12 @@ func (f OpenFlags) StringArray() []string {
 	return bitmaskToStringArray(int(f), openFlagsStrings)
 }
 
+// ParseOpenFlags returns the open flags of an array of strings, as returned by StringArray
+func ParseOpenFlags(strs []string) (OpenFlags, error) {
+	flags, err := stringArrayToBitmask(strs, toU64Map(openFlagsConstants))
+	return OpenFlags(flags), err
+}
+
 // ChmodMode represent a chmod mode bitmask value
 type ChmodMode int
 
@@ -426,6 +452,12 @@ func (f UnlinkFlags) StringArray() []string {
 	return bitmaskToStringArray(int(f), unlinkFlagsStrings)
 }
 
+// ParseUnlinkFlags returns the unlink flags of an array of strings, as returned by StringArray
+func ParseUnlinkFlags(strs []string) (UnlinkFlags, error) {
+	flags, err := stringArrayToBitmask(strs, toU64Map(unlinkFlagsConstants))
+	return UnlinkFlags(flags), err
+}
+
 // RetValError represents a syscall return error value
 type RetValError int
 
@@ -452,3 +484,17 @@ func (kc KernelCapability) String() string {
 func (kc KernelCapability) StringArray() []string {
 	return bitmaskU64ToStringArray(uint64(kc), kernelCapabilitiesStrings)
 }
+
+// ParseKernelCapability returns the kernel capabilities of an array of strings, as returned by StringArray
+func ParseKernelCapability(strs []string) (KernelCapability, error) {
+	kc, err := stringArrayToBitmask(strs, KernelCapabilityConstants)
+	return KernelCapability(kc), err
+}
+
+func toU64Map(m map[string]int) map[string]uint64 {
+	u64 := make(map[string]uint64, len(m))
+	for k, v := range m {
+		u64[k] = uint64(v)
+	}
+	return u64
+}
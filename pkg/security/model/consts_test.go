@@ -34,3 +34,25 @@ func TestFlagsToString(t *testing.T) {
 		t.Errorf("expexted flags not found, got: %s", str)
 	}
 }
+
+func TestParseFlags(t *testing.T) {
+	flags, err := ParseOpenFlags(OpenFlags(syscall.O_CREAT | syscall.O_TRUNC | 1<<32).StringArray())
+	if err != nil || flags != syscall.O_CREAT|syscall.O_TRUNC|1<<32 {
+		t.Errorf("expected flags not found, got: %s (%v)", flags, err)
+	}
+
+	flags, err = ParseOpenFlags(OpenFlags(syscall.O_RDONLY).StringArray())
+	if err != nil || flags != syscall.O_RDONLY {
+		t.Errorf("expected flags not found, got: %s (%v)", flags, err)
+	}
+
+	if _, err := ParseOpenFlags([]string{"O_UNKNOWN"}); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	kc := KernelCapability(KernelCapabilityConstants["CAP_SYS_ADMIN"] | KernelCapabilityConstants["CAP_CHOWN"])
+	parsed, err := ParseKernelCapability(kc.StringArray())
+	if err != nil || parsed != kc {
+		t.Errorf("expected capabilities not found, got: %s (%v)", parsed, err)
+	}
+}
@@ -101,8 +101,22 @@ type ChownEvent struct {
 
 // ContainerContext holds the container context of an event
 type ContainerContext struct {
-	ID   string   `field:"id,ResolveContainerID"`
-	Tags []string `field:"tags,ResolveContainerTags:9999"`
+	ID    string   `field:"id,ResolveContainerID"`
+	Tags  []string `field:"tags,ResolveContainerTags:9999"`
+	Image string   `field:"image,ResolveContainerImage:9999"`
+}
+
+// KubeContext holds the Kubernetes context of an event, resolved from the tags
+// of its container
+type KubeContext struct {
+	Pod KubePodContext `field:"pod"`
+}
+
+// KubePodContext holds the context of the Kubernetes pod of an event
+type KubePodContext struct {
+	Name      string            `field:"name,ResolveKubePodName:9999"`
+	Namespace string            `field:"namespace,ResolveKubePodNamespace:9999"`
+	Labels    map[string]string `field:"labels,ResolveKubePodLabel:9999"`
 }
 
 // Event represents an event sent from the kernel
@@ -115,6 +129,7 @@ type Event struct {
 
 	ProcessContext   ProcessContext   `field:"process" event:"*"`
 	ContainerContext ContainerContext `field:"container"`
+	KubeContext      KubeContext      `field:"kube" event:"*"`
 
 	Chmod       ChmodEvent    `field:"chmod" event:"chmod"`
 	Chown       ChownEvent    `field:"chown" event:"chown"`
@@ -168,6 +183,11 @@ func (e *Event) GetPointer() unsafe.Pointer {
 	return unsafe.Pointer(e)
 }
 
+// GetTimestamp returns the date of the Event, zero if it isn't known
+func (e *Event) GetTimestamp() time.Time {
+	return e.Timestamp
+}
+
 // SetuidEvent represents a setuid event
 type SetuidEvent struct {
 	UID    uint32 `field:"uid"`
@@ -13,6 +13,7 @@ type AgentContext struct {
 	RuleID        string `json:"rule_id"`
 	PolicyName    string `json:"policy_name,omitempty"`
 	PolicyVersion string `json:"policy_version,omitempty"`
+	Severity      string `json:"severity,omitempty"`
 	Version       string `json:"version,omitempty"`
 }
 
@@ -20,5 +21,6 @@ type AgentContext struct {
 // easyjson:json
 type Signal struct {
 	*AgentContext `json:"agent"`
-	Title         string `json:"title"`
+	Title         string                 `json:"title"`
+	Context       map[string]interface{} `json:"context,omitempty"`
 }
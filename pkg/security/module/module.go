@@ -21,6 +21,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
 	"google.golang.org/grpc"
 
 	"github.com/DataDog/datadog-agent/cmd/system-probe/api/module"
@@ -216,26 +217,28 @@ func (m *Module) Reload() error {
 	atomic.StoreUint64(&m.reloading, 1)
 	defer atomic.StoreUint64(&m.reloading, 0)
 
-	policiesDir := m.config.PoliciesDir
+	policiesDirs := m.config.GetPoliciesDirs()
 	rsa := sprobe.NewRuleSetApplier(m.config, m.probe)
 
 	newRuleSetOpts := func() *rules.Opts {
-		return rules.NewOptsWithParams(
+		opts := rules.NewOptsWithParams(
 			model.SECLConstants,
 			sprobe.SupportedDiscarders,
 			m.getEventTypeEnabled(),
 			sprobe.AllCustomRuleIDs(),
 			model.SECLLegacyAttributes,
 			&seclog.PatternLogger{})
+		opts.RuleFilters = []rules.RuleFilter{&rules.HostTagsFilter{Tags: m.config.HostTags}}
+		return opts
 	}
 
 	ruleSet := m.probe.NewRuleSet(newRuleSetOpts())
 
-	loadErr := rules.LoadPolicies(policiesDir, ruleSet)
+	loadErr := rules.LoadPoliciesFromDirs(policiesDirs, ruleSet)
 
 	model := &model.Model{}
 	approverRuleSet := rules.NewRuleSet(model, model.NewEvent, newRuleSetOpts())
-	loadApproversErr := rules.LoadPolicies(policiesDir, approverRuleSet)
+	loadApproversErr := rules.LoadPoliciesFromDirs(policiesDirs, approverRuleSet)
 
 	if loadErr.ErrorOrNil() != nil {
 		logMultiErrors("error while loading policies: %+v", loadErr)
@@ -243,6 +246,10 @@ func (m *Module) Reload() error {
 		logMultiErrors("error while loading policies for Approvers: %+v", loadApproversErr)
 	}
 
+	for _, label := range m.probe.GetResolvers().TagsResolver.GetUnmappedPodLabels(ruleSet.GetFields()) {
+		log.Warnf("the pod label `%s` used by the rules isn't converted to a tag by `kubernetes_pod_labels_as_tags`, it will always be empty", label)
+	}
+
 	monitor := m.probe.GetMonitor()
 	ruleSetLoadedReport := monitor.PrepareRuleSetLoadedReport(ruleSet, loadErr)
 
@@ -281,7 +288,7 @@ func (m *Module) Reload() error {
 	ruleIDs = append(ruleIDs, sprobe.AllCustomRuleIDs()...)
 
 	m.apiServer.Apply(ruleIDs)
-	m.rateLimiter.Apply(ruleIDs)
+	m.rateLimiter.Apply(ruleSet, sprobe.AllCustomRuleIDs())
 
 	m.displayReport(report)
 
@@ -367,6 +374,42 @@ func (m *Module) RuleMatch(rule *rules.Rule, event eval.Event) {
 		m.selfTester.SendEventIfExpecting(rule, event)
 	}
 	m.SendEvent(rule, event, extTagsCb, service)
+
+	m.applyActions(rule, event.(*sprobe.Event))
+}
+
+// applyActions applies the kill and metric actions of a rule that matched.
+// The events dropped by the rate limiter still trigger the actions.
+func (m *Module) applyActions(rule *rules.Rule, event *sprobe.Event) {
+	if rule.Definition == nil {
+		return
+	}
+
+	for _, action := range rule.Definition.Actions {
+		switch {
+		case action.Kill != nil:
+			pid := int(event.ProcessContext.Pid)
+			if pid <= 1 || pid == os.Getpid() {
+				log.Warnf("Rule %s: refusing to kill process %d", rule.ID, pid)
+				continue
+			}
+
+			signal := action.Kill.GetSignal()
+			if err := unix.Kill(pid, unix.SignalNum(signal)); err != nil {
+				log.Errorf("Rule %s: failed to send %s to process %d: %s", rule.ID, signal, pid, err)
+			} else {
+				log.Infof("Rule %s: sent %s to process %d", rule.ID, signal, pid)
+			}
+		case action.Metric != nil:
+			if m.statsdClient == nil {
+				continue
+			}
+			tags := append([]string{"rule_id:" + rule.ID}, action.Metric.Tags...)
+			if err := m.statsdClient.Count(action.Metric.Name, 1, tags, 1.0); err != nil {
+				log.Debugf("Rule %s: failed to send metric %s: %s", rule.ID, action.Metric.Name, err)
+			}
+		}
+	}
 }
 
 // SendEvent sends an event to the backend after checking that the rate limiter allows it for the provided rule
@@ -73,23 +73,39 @@ func NewRateLimiter(client *statsd.Client, opts LimiterOpts) *RateLimiter {
 	}
 }
 
-// Apply a set of rules
-func (rl *RateLimiter) Apply(rules []rules.RuleID) {
+// Apply a set of rules, the limits defined by the rate limit actions of the
+// rules replacing the limits of the options
+func (rl *RateLimiter) Apply(ruleSet *rules.RuleSet, customRuleIDs []rules.RuleID) {
 	rl.Lock()
 	defer rl.Unlock()
 
+	limits := make(map[rules.RuleID]Limit)
+	for id, limit := range rl.opts.Limits {
+		limits[id] = limit
+	}
+
+	ruleIDs := append([]rules.RuleID{}, customRuleIDs...)
+	for id, rule := range ruleSet.GetRules() {
+		ruleIDs = append(ruleIDs, id)
+		if rateLimit := rule.Definition.GetRateLimit(); rateLimit != nil {
+			limits[id] = Limit{Limit: rateLimit.Limit, Burst: rateLimit.Burst}
+		}
+	}
+
 	newLimiters := make(map[string]*Limiter)
-	for _, id := range rules {
-		if limiter, found := rl.limiters[id]; found {
+	for _, id := range ruleIDs {
+		limit := defaultLimit
+		burst := defaultBurst
+
+		if l, exists := limits[id]; exists {
+			limit = rate.Limit(l.Limit)
+			burst = l.Burst
+		}
+
+		// keep the state of the limiters whose limit didn't change
+		if limiter, found := rl.limiters[id]; found && limiter.limiter.Limit() == limit && limiter.limiter.Burst() == burst {
 			newLimiters[id] = limiter
 		} else {
-			limit := defaultLimit
-			burst := defaultBurst
-
-			if l, exists := rl.opts.Limits[id]; exists {
-				limit = rate.Limit(l.Limit)
-				burst = l.Burst
-			}
 			newLimiters[id] = NewLimiter(limit, burst)
 		}
 	}
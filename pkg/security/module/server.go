@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/metrics"
 	sprobe "github.com/DataDog/datadog-agent/pkg/security/probe"
 	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -236,8 +237,9 @@ func (a *APIServer) RunSelfTest(ctx context.Context, params *api.RunSelfTestPara
 // SendEvent forwards events sent by the runtime security module to Datadog
 func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []string, service string) {
 	agentContext := &AgentContext{
-		RuleID:  rule.Definition.ID,
-		Version: version.AgentVersion,
+		RuleID:   rule.Definition.ID,
+		Severity: rule.Definition.GetSeverity(),
+		Version:  version.AgentVersion,
 	}
 
 	ruleEvent := &Signal{
@@ -245,6 +247,21 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []
 		AgentContext: agentContext,
 	}
 
+	// add the fields requested by the context actions of the rule
+	if evalEvent, ok := event.(eval.Event); ok {
+		for _, field := range rule.Definition.GetContextFields() {
+			value, err := evalEvent.GetFieldValue(field)
+			if err != nil {
+				seclog.Tracef("failed to get the context field `%s` of rule `%s`: %s", field, rule.ID, err)
+				continue
+			}
+			if ruleEvent.Context == nil {
+				ruleEvent.Context = make(map[string]interface{})
+			}
+			ruleEvent.Context[field] = value
+		}
+	}
+
 	if policy := rule.Definition.Policy; policy != nil {
 		agentContext.PolicyName = policy.Name
 		agentContext.PolicyVersion = policy.Version
@@ -276,6 +293,9 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []
 	}
 
 	msg.tags["rule_id:"+rule.Definition.ID] = true
+	if agentContext.Severity != "" {
+		msg.tags["severity:"+agentContext.Severity] = true
+	}
 
 	for _, tag := range rule.Tags {
 		msg.tags[tag] = true
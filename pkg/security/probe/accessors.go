@@ -6,6 +6,7 @@ package probe
 
 import (
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/security/model"
@@ -418,6 +419,16 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.HandlerWeight,
 		}, nil
 
+	case "container.image":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).ResolveContainerImage(&(*Event)(ctx.Object).ContainerContext)
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
 	case "container.tags":
 		return &eval.StringArrayEvaluator{
 
@@ -843,6 +854,26 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 
+	case "kube.pod.name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).ResolveKubePodName(&(*Event)(ctx.Object).KubeContext.Pod)
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
+	case "kube.pod.namespace":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+
+				return (*Event)(ctx.Object).ResolveKubePodNamespace(&(*Event)(ctx.Object).KubeContext.Pod)
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+
 	case "link.file.destination.filesystem":
 		return &eval.StringEvaluator{
 			EvalFnc: func(ctx *eval.Context) string {
@@ -3889,6 +3920,17 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		key := strings.TrimPrefix(field, "kube.pod.labels.")
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				return (*Event)(ctx.Object).ResolveKubePodLabel(&(*Event)(ctx.Object).KubeContext.Pod, key)
+			},
+			Field:  field,
+			Weight: 9999,
+		}, nil
+	}
+
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -3965,6 +4007,8 @@ func (e *Event) GetFields() []eval.Field {
 
 		"container.id",
 
+		"container.image",
+
 		"container.tags",
 
 		"exec.args",
@@ -4049,6 +4093,10 @@ func (e *Event) GetFields() []eval.Field {
 
 		"exec.user",
 
+		"kube.pod.name",
+
+		"kube.pod.namespace",
+
 		"link.file.destination.filesystem",
 
 		"link.file.destination.gid",
@@ -4660,6 +4708,10 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.ResolveContainerID(&e.ContainerContext), nil
 
+	case "container.image":
+
+		return e.ResolveContainerImage(&e.ContainerContext), nil
+
 	case "container.tags":
 
 		return e.ResolveContainerTags(&e.ContainerContext), nil
@@ -4828,6 +4880,14 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 		return e.Exec.Process.Credentials.User, nil
 
+	case "kube.pod.name":
+
+		return e.ResolveKubePodName(&e.KubeContext.Pod), nil
+
+	case "kube.pod.namespace":
+
+		return e.ResolveKubePodNamespace(&e.KubeContext.Pod), nil
+
 	case "link.file.destination.filesystem":
 
 		return e.ResolveFileFilesystem(&e.Link.Target), nil
@@ -6374,6 +6434,11 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		key := strings.TrimPrefix(field, "kube.pod.labels.")
+		return e.ResolveKubePodLabel(&e.KubeContext.Pod, key), nil
+	}
+
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -6485,6 +6550,9 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "container.id":
 		return "*", nil
 
+	case "container.image":
+		return "*", nil
+
 	case "container.tags":
 		return "*", nil
 
@@ -6611,6 +6679,12 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	case "exec.user":
 		return "exec", nil
 
+	case "kube.pod.name":
+		return "*", nil
+
+	case "kube.pod.namespace":
+		return "*", nil
+
 	case "link.file.destination.filesystem":
 		return "link", nil
 
@@ -7312,6 +7386,10 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		return "*", nil
+	}
+
 	return "", &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -7458,6 +7536,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "container.image":
+
+		return reflect.String, nil
+
 	case "container.tags":
 
 		return reflect.String, nil
@@ -7626,6 +7708,14 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "kube.pod.name":
+
+		return reflect.String, nil
+
+	case "kube.pod.namespace":
+
+		return reflect.String, nil
+
 	case "link.file.destination.filesystem":
 
 		return reflect.String, nil
@@ -8560,6 +8650,10 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		return reflect.String, nil
+	}
+
 	return reflect.Invalid, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -8925,6 +9019,17 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "container.image":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "ContainerContext.Image"}
+		}
+		e.ContainerContext.Image = str
+
+		return nil
+
 	case "container.tags":
 
 		var ok bool
@@ -9359,6 +9464,28 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 		return nil
 
+	case "kube.pod.name":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Name"}
+		}
+		e.KubeContext.Pod.Name = str
+
+		return nil
+
+	case "kube.pod.namespace":
+
+		var ok bool
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Namespace"}
+		}
+		e.KubeContext.Pod.Namespace = str
+
+		return nil
+
 	case "link.file.destination.filesystem":
 
 		var ok bool
@@ -11895,5 +12022,17 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 
 	}
 
+	if strings.HasPrefix(field, "kube.pod.labels.") {
+		str, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "KubeContext.Pod.Labels"}
+		}
+		if e.KubeContext.Pod.Labels == nil {
+			e.KubeContext.Pod.Labels = make(map[string]string)
+		}
+		e.KubeContext.Pod.Labels[strings.TrimPrefix(field, "kube.pod.labels.")] = str
+		return nil
+	}
+
 	return &eval.ErrFieldNotFound{Field: field}
 }
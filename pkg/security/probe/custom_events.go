@@ -222,7 +222,7 @@ func NewRuleSetLoadedEvent(rs *rules.RuleSet, err *multierror.Error) (*rules.Rul
 		}
 		policy.RulesLoaded = append(policy.RulesLoaded, &RuleLoaded{
 			ID:         rule.ID,
-			Expression: rule.Definition.Expression,
+			Expression: rule.Definition.GetExpression(),
 		})
 	}
 
@@ -238,7 +238,7 @@ func NewRuleSetLoadedEvent(rs *rules.RuleSet, err *multierror.Error) (*rules.Rul
 				}
 				policy.RulesIgnored = append(policy.RulesIgnored, &RuleIgnored{
 					ID:         rerr.Definition.ID,
-					Expression: rerr.Definition.Expression,
+					Expression: rerr.Definition.GetExpression(),
 					Reason:     rerr.Err.Error(),
 				})
 			}
@@ -0,0 +1,368 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+)
+
+// UnmarshalEventFixtures returns the events of a fixture, a JSON event or a JSON
+// array of events, in the format of the events sent by the agent
+func UnmarshalEventFixtures(data []byte) ([]*model.Event, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		event, err := UnmarshalEventFixture(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*model.Event{event}, nil
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.Event, 0, len(raws))
+	for i, raw := range raws {
+		event, err := UnmarshalEventFixture(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "event #%d", i+1)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// UnmarshalEventFixture returns the event of a JSON fixture in the format of the
+// events sent by the agent, as serialized by NewEventSerializer. The fields of
+// the event are set from the fixture instead of being resolved, and the fields
+// missing from the serialized events, like the exact error returned by a
+// syscall, are approximated.
+func UnmarshalEventFixture(data []byte) (*model.Event, error) {
+	var s EventSerializer
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	if s.EventContextSerializer == nil || s.EventContextSerializer.Name == "" {
+		return nil, errors.New("missing event type, `evt.name` is required")
+	}
+
+	event := &model.Event{
+		Type:      uint64(model.ParseEvalEventType(s.EventContextSerializer.Name)),
+		Timestamp: s.Date,
+	}
+	if event.GetEventType() == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", s.EventContextSerializer.Name)
+	}
+
+	if s.ContainerContextSerializer != nil {
+		event.ContainerContext.ID = s.ContainerContextSerializer.ID
+	}
+
+	if ps := s.ProcessContextSerializer; ps != nil && ps.ProcessCacheEntrySerializer != nil {
+		process, err := newProcessFromSerializer(ps.ProcessCacheEntrySerializer)
+		if err != nil {
+			return nil, errors.Wrap(err, "process")
+		}
+		event.ProcessContext.Process = *process
+
+		ancestor := &event.ProcessContext.Ancestor
+		for _, as := range ps.Ancestors {
+			process, err := newProcessFromSerializer(as)
+			if err != nil {
+				return nil, errors.Wrap(err, "process ancestor")
+			}
+
+			*ancestor = &model.ProcessCacheEntry{ProcessContext: model.ProcessContext{Process: *process}}
+			ancestor = &(*ancestor).Ancestor
+		}
+	}
+
+	retval := fixtureRetval(s.EventContextSerializer.Outcome)
+
+	var fs, dest FileSerializer
+	if s.FileEventSerializer != nil {
+		fs = s.FileEventSerializer.FileSerializer
+		if s.FileEventSerializer.Destination != nil {
+			dest = *s.FileEventSerializer.Destination
+		}
+	}
+
+	var err error
+	switch event.GetEventType() {
+	case model.FileChmodEventType:
+		event.Chmod.Retval = retval
+		event.Chmod.File = newFileFromSerializer(&fs)
+		event.Chmod.Mode = uint32Value(dest.Mode)
+	case model.FileChownEventType:
+		event.Chown.Retval = retval
+		event.Chown.File = newFileFromSerializer(&fs)
+		event.Chown.UID, event.Chown.GID = dest.UID, dest.GID
+		event.Chown.User, event.Chown.Group = dest.User, dest.Group
+	case model.FileLinkEventType:
+		event.Link.Retval = retval
+		event.Link.Source = newFileFromSerializer(&fs)
+		event.Link.Target = newFileFromSerializer(&dest)
+	case model.FileOpenEventType:
+		event.Open.Retval = retval
+		event.Open.File = newFileFromSerializer(&fs)
+		event.Open.Mode = uint32Value(dest.Mode)
+		var flags model.OpenFlags
+		flags, err = model.ParseOpenFlags(fs.Flags)
+		event.Open.Flags = uint32(flags)
+	case model.FileMkdirEventType:
+		event.Mkdir.Retval = retval
+		event.Mkdir.File = newFileFromSerializer(&fs)
+		event.Mkdir.Mode = uint32Value(dest.Mode)
+	case model.FileRmdirEventType:
+		event.Rmdir.Retval = retval
+		event.Rmdir.File = newFileFromSerializer(&fs)
+	case model.FileUnlinkEventType:
+		event.Unlink.Retval = retval
+		event.Unlink.File = newFileFromSerializer(&fs)
+		var flags model.UnlinkFlags
+		flags, err = model.ParseUnlinkFlags(fs.Flags)
+		event.Unlink.Flags = uint32(flags)
+	case model.FileRenameEventType:
+		event.Rename.Retval = retval
+		event.Rename.Old = newFileFromSerializer(&fs)
+		event.Rename.New = newFileFromSerializer(&dest)
+	case model.FileSetXAttrEventType:
+		event.SetXAttr.Retval = retval
+		event.SetXAttr.File = newFileFromSerializer(&fs)
+		event.SetXAttr.Name, event.SetXAttr.Namespace = dest.XAttrName, dest.XAttrNamespace
+	case model.FileRemoveXAttrEventType:
+		event.RemoveXAttr.Retval = retval
+		event.RemoveXAttr.File = newFileFromSerializer(&fs)
+		event.RemoveXAttr.Name, event.RemoveXAttr.Namespace = dest.XAttrName, dest.XAttrNamespace
+	case model.FileUtimeEventType:
+		event.Utimes.Retval = retval
+		event.Utimes.File = newFileFromSerializer(&fs)
+		if dest.Atime != nil {
+			event.Utimes.Atime = *dest.Atime
+		}
+		if dest.Mtime != nil {
+			event.Utimes.Mtime = *dest.Mtime
+		}
+	case model.SetuidEventType:
+		var ss SetuidSerializer
+		if err = unmarshalCredentialsDestination(&s, &ss); err == nil {
+			event.SetUID = model.SetuidEvent{
+				UID: uint32(ss.UID), User: ss.User,
+				EUID: uint32(ss.EUID), EUser: ss.EUser,
+				FSUID: uint32(ss.FSUID), FSUser: ss.FSUser,
+			}
+		}
+	case model.SetgidEventType:
+		var ss SetgidSerializer
+		if err = unmarshalCredentialsDestination(&s, &ss); err == nil {
+			event.SetGID = model.SetgidEvent{
+				GID: uint32(ss.GID), Group: ss.Group,
+				EGID: uint32(ss.EGID), EGroup: ss.EGroup,
+				FSGID: uint32(ss.FSGID), FSGroup: ss.FSGroup,
+			}
+		}
+	case model.CapsetEventType:
+		var cs CapsetSerializer
+		if err = unmarshalCredentialsDestination(&s, &cs); err == nil {
+			var effective, permitted model.KernelCapability
+			if effective, err = model.ParseKernelCapability(cs.CapEffective); err == nil {
+				permitted, err = model.ParseKernelCapability(cs.CapPermitted)
+			}
+			event.Capset.CapEffective, event.Capset.CapPermitted = uint64(effective), uint64(permitted)
+		}
+	case model.ExecEventType:
+		event.Exec.Process = event.ProcessContext.Process
+		if ps := s.ProcessContextSerializer; ps != nil && ps.ProcessCacheEntrySerializer != nil {
+			event.Exec.Argv = ps.Args
+			event.Exec.Args = strings.Join(ps.Args, " ")
+			event.Exec.ArgsTruncated = ps.ArgsTruncated
+			event.Exec.Envs = ps.Envs
+			event.Exec.EnvsTruncated = ps.EnvsTruncated
+		}
+	case model.SELinuxEventType:
+		event.SELinux.File = newFileFromSerializer(&fs)
+		if ss := s.SELinuxEventSerializer; ss != nil {
+			switch {
+			case ss.BoolChange != nil:
+				event.SELinux.EventKind = model.SELinuxBoolChangeEventKind
+				event.SELinux.BoolName, event.SELinux.BoolChangeValue = ss.BoolChange.Name, ss.BoolChange.State
+			case ss.EnforceStatus != nil:
+				event.SELinux.EventKind = model.SELinuxStatusChangeEventKind
+				event.SELinux.EnforceStatus = ss.EnforceStatus.Status
+			case ss.BoolCommit != nil:
+				event.SELinux.EventKind = model.SELinuxBoolCommitEventKind
+				event.SELinux.BoolCommitValue = ss.BoolCommit.State
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, event.GetType())
+	}
+
+	return event, nil
+}
+
+// fixtureRetval returns a return value matching the outcome of an event. The
+// refused syscalls are reported as returning EACCES, the failed ones EINVAL.
+func fixtureRetval(outcome string) int64 {
+	switch outcome {
+	case "Refused":
+		return -int64(syscall.EACCES)
+	case "Error":
+		return -int64(syscall.EINVAL)
+	default:
+		return 0
+	}
+}
+
+// unmarshalCredentialsDestination decodes the destination credentials of the
+// setuid, setgid and capset events
+func unmarshalCredentialsDestination(s *EventSerializer, dest interface{}) error {
+	ps := s.ProcessContextSerializer
+	if ps == nil || ps.ProcessCacheEntrySerializer == nil || ps.Credentials == nil || ps.Credentials.Destination == nil {
+		return errors.New("missing `process.credentials.destination`")
+	}
+
+	data, err := json.Marshal(ps.Credentials.Destination)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func newFileFromSerializer(fs *FileSerializer) model.FileEvent {
+	file := model.FileEvent{
+		FileFields: model.FileFields{
+			UID:   fs.UID,
+			GID:   fs.GID,
+			User:  fs.User,
+			Group: fs.Group,
+			Mode:  uint16(uint32Value(fs.Mode)),
+		},
+		PathnameStr: fs.Path,
+		BasenameStr: fs.Name,
+		Filesytem:   fs.Filesystem,
+	}
+
+	if file.BasenameStr == "" && file.PathnameStr != "" {
+		file.BasenameStr = path.Base(file.PathnameStr)
+	}
+	if fs.Inode != nil {
+		file.Inode = *fs.Inode
+	}
+	if fs.MountID != nil {
+		file.MountID = *fs.MountID
+	}
+	if fs.InUpperLayer != nil {
+		file.InUpperLayer = *fs.InUpperLayer
+	}
+	if fs.Mtime != nil {
+		file.MTime = *fs.Mtime
+	}
+	if fs.Ctime != nil {
+		file.CTime = *fs.Ctime
+	}
+
+	return file
+}
+
+func newProcessFromSerializer(ps *ProcessCacheEntrySerializer) (*model.Process, error) {
+	process := &model.Process{
+		Pid:         ps.Pid,
+		Tid:         ps.Tid,
+		PPid:        ps.PPid,
+		PathnameStr: ps.Path,
+		Filesystem:  ps.Filesystem,
+		Comm:        ps.Comm,
+		TTYName:     ps.TTY,
+		Credentials: model.Credentials{
+			UID:   uint32(ps.UID),
+			GID:   uint32(ps.GID),
+			User:  ps.User,
+			Group: ps.Group,
+		},
+	}
+
+	if ps.Executable != nil {
+		file := newFileFromSerializer(ps.Executable)
+		process.FileFields = file.FileFields
+		if process.PathnameStr == "" {
+			process.PathnameStr = file.PathnameStr
+		}
+		process.BasenameStr = file.BasenameStr
+		if process.Filesystem == "" {
+			process.Filesystem = file.Filesytem
+		}
+	}
+	if process.BasenameStr == "" && process.PathnameStr != "" {
+		process.BasenameStr = path.Base(process.PathnameStr)
+	}
+	if ps.Inode != 0 {
+		process.FileFields.Inode = ps.Inode
+	}
+	if ps.MountID != 0 {
+		process.FileFields.MountID = ps.MountID
+	}
+
+	if ps.Container != nil {
+		process.ContainerID = ps.Container.ID
+	}
+	if ps.ForkTime != nil {
+		process.ForkTime = *ps.ForkTime
+	}
+	if ps.ExecTime != nil {
+		process.ExecTime = *ps.ExecTime
+	}
+	if ps.ExitTime != nil {
+		process.ExitTime = *ps.ExitTime
+	}
+
+	if cs := ps.Credentials; cs != nil && cs.CredentialsSerializer != nil {
+		capEffective, err := model.ParseKernelCapability(cs.CapEffective)
+		if err != nil {
+			return nil, err
+		}
+		capPermitted, err := model.ParseKernelCapability(cs.CapPermitted)
+		if err != nil {
+			return nil, err
+		}
+
+		process.Credentials = model.Credentials{
+			UID: uint32(cs.UID), GID: uint32(cs.GID),
+			User: cs.User, Group: cs.Group,
+			EUID: uint32(cs.EUID), EGID: uint32(cs.EGID),
+			EUser: cs.EUser, EGroup: cs.EGroup,
+			FSUID: uint32(cs.FSUID), FSGID: uint32(cs.FSGID),
+			FSUser: cs.FSUser, FSGroup: cs.FSGroup,
+			CapEffective: uint64(capEffective),
+			CapPermitted: uint64(capPermitted),
+		}
+	}
+
+	return process, nil
+}
+
+func uint32Value(i *uint32) uint32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
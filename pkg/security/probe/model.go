@@ -18,6 +18,7 @@ import (
 	pconfig "github.com/DataDog/datadog-agent/pkg/process/config"
 	"github.com/DataDog/datadog-agent/pkg/security/model"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
 )
 
 const (
@@ -161,12 +162,52 @@ func (ev *Event) ResolveContainerID(e *model.ContainerContext) string {
 
 // ResolveContainerTags resolves the container tags of the event
 func (ev *Event) ResolveContainerTags(e *model.ContainerContext) []string {
-	if len(e.Tags) == 0 && e.ID != "" {
+	if len(e.Tags) == 0 && ev.ResolveContainerID(e) != "" {
 		e.Tags = ev.resolvers.TagsResolver.Resolve(e.ID)
 	}
 	return e.Tags
 }
 
+// ResolveContainerImage resolves the image name of the container of the event
+func (ev *Event) ResolveContainerImage(e *model.ContainerContext) string {
+	if len(e.Image) == 0 {
+		e.Image = utils.GetTagValue("image_name", ev.ResolveContainerTags(e))
+	}
+	return e.Image
+}
+
+// ResolveKubePodName resolves the name of the Kubernetes pod of the event
+func (ev *Event) ResolveKubePodName(e *model.KubePodContext) string {
+	if len(e.Name) == 0 {
+		e.Name = utils.GetTagValue("pod_name", ev.ResolveContainerTags(&ev.ContainerContext))
+	}
+	return e.Name
+}
+
+// ResolveKubePodNamespace resolves the namespace of the Kubernetes pod of the event
+func (ev *Event) ResolveKubePodNamespace(e *model.KubePodContext) string {
+	if len(e.Namespace) == 0 {
+		e.Namespace = utils.GetTagValue("kube_namespace", ev.ResolveContainerTags(&ev.ContainerContext))
+	}
+	return e.Namespace
+}
+
+// ResolveKubePodLabel resolves the value of a label of the Kubernetes pod of the event
+func (ev *Event) ResolveKubePodLabel(e *model.KubePodContext, label string) string {
+	if value, found := e.Labels[label]; found {
+		return value
+	}
+
+	value := ev.resolvers.TagsResolver.GetPodLabelValue(label, ev.ResolveContainerTags(&ev.ContainerContext))
+	if value != "" {
+		if e.Labels == nil {
+			e.Labels = make(map[string]string)
+		}
+		e.Labels[label] = value
+	}
+	return value
+}
+
 // UnmarshalProcess unmarshal a Process
 func (ev *Event) UnmarshalProcess(data []byte) (int, error) {
 	// reset the process cache entry of the current event
@@ -415,6 +456,11 @@ func ExtractEventInfo(data []byte) (uint64, uint64, error) {
 	return model.ByteOrder.Uint64(data[0:8]), model.ByteOrder.Uint64(data[8:16]), nil
 }
 
+// GetTimestamp returns the date of the event, resolved from the kernel timestamp
+func (ev *Event) GetTimestamp() time.Time {
+	return ev.ResolveEventTimestamp()
+}
+
 // ResolveEventTimestamp resolves the monolitic kernel event timestamp to an absolute time
 func (ev *Event) ResolveEventTimestamp() time.Time {
 	if ev.Timestamp.IsZero() {
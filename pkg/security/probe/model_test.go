@@ -11,9 +11,12 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"unsafe"
 
+	"github.com/DataDog/datadog-agent/pkg/security/config"
 	"github.com/DataDog/datadog-agent/pkg/security/model"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 )
 
 func TestPathValidation(t *testing.T) {
@@ -204,3 +207,88 @@ func TestExecArgsOptions(t *testing.T) {
 		t.Errorf("expected 5 options, got %d", len(options))
 	}
 }
+
+type testTagger struct {
+	nullTagger
+	tags map[string][]string
+}
+
+func (t *testTagger) Tag(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+	return t.tags[entity], nil
+}
+
+func TestKubeFields(t *testing.T) {
+	tagsResolver := NewTagsResolver(&config.Config{
+		PodLabelsAsTags: map[string]string{
+			"App":  "kube_app",
+			"tier": "%%label%%",
+		},
+	})
+	tagsResolver.tagger = &testTagger{
+		tags: map[string][]string{
+			"container_id://abc": {
+				"image_name:nginx",
+				"kube_namespace:default",
+				"pod_name:nginx-1234",
+				"kube_app:web",
+				"tier:frontend",
+			},
+		},
+	}
+
+	e := Event{
+		Event: model.Event{
+			ContainerContext: model.ContainerContext{
+				ID: "abc",
+			},
+		},
+		resolvers: &Resolvers{
+			TagsResolver: tagsResolver,
+		},
+	}
+
+	expected := map[string]string{
+		"container.image":      "nginx",
+		"kube.pod.name":        "nginx-1234",
+		"kube.pod.namespace":   "default",
+		"kube.pod.labels.app":  "web",
+		"kube.pod.labels.tier": "frontend",
+		"kube.pod.labels.team": "",
+	}
+
+	for field, value := range expected {
+		result, err := e.GetFieldValue(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != value {
+			t.Errorf("expected %s to be `%s`, got `%v`", field, value, result)
+		}
+	}
+
+	evaluator, err := (&Model{}).GetEvaluator("kube.pod.labels.app", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := evaluator.Eval(eval.NewContext(unsafe.Pointer(&e))); result != "web" {
+		t.Errorf("expected kube.pod.labels.app to be `web`, got `%v`", result)
+	}
+
+	if eventType, err := e.GetFieldEventType("kube.pod.labels.app"); err != nil || eventType != "*" {
+		t.Errorf("expected kube.pod.labels.app to be available for all event types, got `%s`: %v", eventType, err)
+	}
+}
+
+func TestGetUnmappedPodLabels(t *testing.T) {
+	tagsResolver := NewTagsResolver(&config.Config{
+		PodLabelsAsTags: map[string]string{
+			"App":    "kube_app",
+			"team-*": "%%label%%",
+		},
+	})
+
+	fields := []eval.Field{"kube.pod.name", "kube.pod.labels.app", "kube.pod.labels.team-payments", "kube.pod.labels.tier", "process.file.path"}
+	if labels := tagsResolver.GetUnmappedPodLabels(fields); !reflect.DeepEqual(labels, []string{"tier"}) {
+		t.Errorf("expected only the `tier` label to be unmapped, got %v", labels)
+	}
+}
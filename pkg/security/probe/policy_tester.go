@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// PolicyTester evaluates events against the rules of policies directories,
+// without loading the probe
+type PolicyTester struct {
+	ruleSet   *rules.RuleSet
+	approvers map[eval.EventType]rules.Approvers
+}
+
+// EventReport describes the evaluation of an event by the rules of the
+// policies, and whether the event would be approved by the kernel filters
+type EventReport struct {
+	*rules.EvaluationReport
+	Approved bool `json:"approved"`
+}
+
+// RuleTestResult describes the result of a test of a rule
+type RuleTestResult struct {
+	RuleID   rules.RuleID   `json:"rule_id"`
+	Name     string         `json:"name,omitempty"`
+	Expected bool           `json:"expected"`
+	Passed   bool           `json:"passed"`
+	Error    string         `json:"error,omitempty"`
+	Events   []*EventReport `json:"events,omitempty"`
+}
+
+// NewPolicyTester returns a policy tester for the policies of the directories,
+// loaded in order
+func NewPolicyTester(policiesDirs []string, opts *rules.Opts) (*PolicyTester, error) {
+	m := &model.Model{}
+	ruleSet := rules.NewRuleSet(m, m.NewEvent, opts)
+	if err := rules.LoadPoliciesFromDirs(policiesDirs, ruleSet); err.ErrorOrNil() != nil {
+		return nil, err
+	}
+
+	approvers, err := ruleSet.GetApprovers(GetCapababilities())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PolicyTester{
+		ruleSet:   ruleSet,
+		approvers: approvers,
+	}, nil
+}
+
+// Evaluate evaluates the event against the rules of the policies
+func (pt *PolicyTester) Evaluate(event *model.Event) *EventReport {
+	return &EventReport{
+		EvaluationReport: pt.ruleSet.EvaluateWithReport(event),
+		Approved:         pt.isApproved(event),
+	}
+}
+
+// isApproved returns whether the event would pass the approvers pushed to the
+// kernel. Without approvers for its event type, every event is passed. The
+// values are compared the way the kernel filters of the field do: by basename,
+// as a mask of flags, or by equality.
+func (pt *PolicyTester) isApproved(event *model.Event) bool {
+	approvers, exists := pt.approvers[event.GetType()]
+	if !exists || len(approvers) == 0 {
+		return true
+	}
+
+	capabilities := allCapabilities[event.GetType()]
+	for field, values := range approvers {
+		fieldValue, err := event.GetFieldValue(field)
+		if err != nil {
+			continue
+		}
+
+		policyFlags := capabilities[field].PolicyFlags
+		for _, value := range values {
+			switch v := value.Value.(type) {
+			case string:
+				s, ok := fieldValue.(string)
+				if !ok {
+					continue
+				}
+				if policyFlags&PolicyFlagBasename != 0 {
+					if path.Base(s) == path.Base(v) {
+						return true
+					}
+				} else if s == v {
+					return true
+				}
+			case int:
+				i, ok := fieldValue.(int)
+				if !ok {
+					continue
+				}
+				if policyFlags&PolicyFlagFlags != 0 {
+					if i&v != 0 {
+						return true
+					}
+				} else if i == v {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// RunRuleTests runs the tests defined by the rules of the policies, sorted by
+// rule ID. The events of a test are evaluated in order, from a clean state of
+// the sequence and threshold rules, and the test passes when the rule matches
+// one of them, approved by the kernel filters, as expected by the test.
+func (pt *PolicyTester) RunRuleTests() []*RuleTestResult {
+	var ruleIDs []rules.RuleID
+	for id, rule := range pt.ruleSet.GetRules() {
+		if rule.Definition != nil && len(rule.Definition.Tests) > 0 {
+			ruleIDs = append(ruleIDs, id)
+		}
+	}
+	sort.Strings(ruleIDs)
+
+	var results []*RuleTestResult
+	for _, id := range ruleIDs {
+		for i, test := range pt.ruleSet.GetRules()[id].Definition.Tests {
+			result := &RuleTestResult{
+				RuleID:   id,
+				Name:     test.Name,
+				Expected: test.Match,
+			}
+			if result.Name == "" {
+				result.Name = fmt.Sprintf("test #%d", i+1)
+			}
+
+			events, err := pt.loadTestEvents(test)
+			if err != nil {
+				result.Error = err.Error()
+				results = append(results, result)
+				continue
+			}
+
+			pt.ruleSet.ResetState()
+
+			var matched bool
+			for _, event := range events {
+				report := pt.Evaluate(event)
+				result.Events = append(result.Events, report)
+
+				if report.matches(id) {
+					matched = true
+				}
+			}
+			result.Passed = matched == test.Match
+
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func (pt *PolicyTester) loadTestEvents(test *rules.RuleTestDefinition) ([]*model.Event, error) {
+	switch {
+	case test.Fixture != "" && test.Event != nil:
+		return nil, errors.New("a test can't define both an event and a fixture")
+	case test.Fixture != "":
+		data, err := ioutil.ReadFile(test.Fixture)
+		if err != nil {
+			return nil, err
+		}
+		return UnmarshalEventFixtures(data)
+	case test.Event != nil:
+		data, err := json.Marshal(test.Event)
+		if err != nil {
+			return nil, err
+		}
+		return UnmarshalEventFixtures(data)
+	default:
+		return nil, errors.New("a test must define an event or a fixture")
+	}
+}
+
+// matches returns whether the rule matched the event, and the event was
+// approved, as it would then be sent by the agent
+func (r *EventReport) matches(id rules.RuleID) bool {
+	if !r.Approved {
+		return false
+	}
+	for _, rule := range r.Rules {
+		if rule.ID == id && rule.Match {
+			return true
+		}
+	}
+	return false
+}
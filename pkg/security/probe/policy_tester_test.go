@@ -0,0 +1,262 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package probe
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/model"
+	"github.com/DataDog/datadog-agent/pkg/security/rules"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+const testPolicy = `---
+version: 1.2.3
+macros:
+  - id: shadow_readers
+    expression: '["passwd", "chage"]'
+rules:
+  - id: shadow_open
+    expression: open.file.path == "/etc/shadow" && process.file.name not in shadow_readers
+    tests:
+      - name: cat
+        match: true
+        event:
+          evt:
+            name: open
+          file:
+            path: /etc/shadow
+            flags: ["O_RDONLY"]
+          process:
+            executable:
+              path: /usr/bin/cat
+      - name: passwd
+        match: false
+        event:
+          evt:
+            name: open
+          file:
+            path: /etc/shadow
+          process:
+            executable:
+              path: /usr/bin/passwd
+      - name: fixture
+        match: false
+        fixture: fixtures/open.json
+  - id: log_unlink
+    expression: unlink.file.path =~ "/var/log/*"
+    tests:
+      - match: true
+        fixture: fixtures/missing.json
+`
+
+const testFixture = `[
+  {
+    "evt": {"name": "open", "category": "File Activity", "outcome": "Refused"},
+    "file": {"path": "/etc/hosts", "flags": ["O_WRONLY", "O_CREAT"], "destination": {"mode": 420}},
+    "process": {
+      "pid": 42,
+      "executable_path": "/usr/bin/vim",
+      "credentials": {"uid": 1000, "euid": 0, "cap_effective": ["CAP_CHOWN"]},
+      "ancestors": [{"pid": 1, "executable_path": "/sbin/init"}]
+    },
+    "container": {"id": "abc"},
+    "date": "2021-03-04T05:06:07.000000008Z"
+  },
+  {
+    "evt": {"name": "unlink"},
+    "file": {"path": "/var/log/syslog"}
+  }
+]`
+
+const testSequencePolicy = `---
+rules:
+  - id: shadow_then_unlink
+    sequence:
+      key: process.file.path
+      within: 1m
+      steps:
+        - open.file.path == "/etc/shadow"
+        - unlink.file.path == "/var/log/auth.log"
+    tests:
+      - name: sequence
+        match: true
+        fixture: fixtures/sequence.json
+      - name: other process
+        match: false
+        fixture: fixtures/other.json
+      - name: too late
+        match: false
+        fixture: fixtures/late.json
+`
+
+const testSequenceFixture = `[
+  {"evt": {"name": "open"}, "file": {"path": "/etc/shadow"}, "process": {"executable_path": "/usr/bin/%s"}, "date": "2021-03-04T05:06:07Z"},
+  {"evt": {"name": "unlink"}, "file": {"path": "/var/log/auth.log"}, "process": {"executable_path": "/usr/bin/vim"}, "date": "%s"}
+]`
+
+func newTestPolicyTester(t *testing.T) *PolicyTester {
+	return newTestPolicyTesterWithFiles(t, map[string]string{
+		"test.policy":        testPolicy,
+		"fixtures/open.json": testFixture,
+	})
+}
+
+func newTestPolicyTesterWithFiles(t *testing.T, files map[string]string) *PolicyTester {
+	dir, err := ioutil.TempDir("", "policy-tester")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "fixtures"), 0755))
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	enabled := map[eval.EventType]bool{"*": true}
+	opts := rules.NewOptsWithParams(model.SECLConstants, SupportedDiscarders, enabled, AllCustomRuleIDs(), model.SECLLegacyAttributes)
+
+	tester, err := NewPolicyTester([]string{dir}, opts)
+	require.NoError(t, err)
+	return tester
+}
+
+func TestUnmarshalEventFixtures(t *testing.T) {
+	events, err := UnmarshalEventFixtures([]byte(testFixture))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	open := events[0]
+	assert.Equal(t, "open", open.GetType())
+	assert.Equal(t, -int64(syscall.EACCES), open.Open.Retval)
+	assert.Equal(t, "/etc/hosts", open.Open.File.PathnameStr)
+	assert.Equal(t, "hosts", open.Open.File.BasenameStr)
+	assert.Equal(t, uint32(syscall.O_WRONLY|syscall.O_CREAT), open.Open.Flags)
+	assert.Equal(t, uint32(420), open.Open.Mode)
+	assert.Equal(t, "abc", open.ContainerContext.ID)
+	assert.Equal(t, int64(1614834367000000008), open.Timestamp.UnixNano())
+
+	process := open.ProcessContext.Process
+	assert.Equal(t, uint32(42), process.Pid)
+	assert.Equal(t, "vim", process.BasenameStr)
+	assert.Equal(t, uint32(1000), process.Credentials.UID)
+	assert.Equal(t, model.KernelCapabilityConstants["CAP_CHOWN"], process.Credentials.CapEffective)
+	require.NotNil(t, open.ProcessContext.Ancestor)
+	assert.Equal(t, "/sbin/init", open.ProcessContext.Ancestor.PathnameStr)
+
+	assert.Equal(t, "/var/log/syslog", events[1].Unlink.File.PathnameStr)
+
+	_, err = UnmarshalEventFixtures([]byte(`{"evt": {"name": "unknown"}}`))
+	assert.Error(t, err)
+	_, err = UnmarshalEventFixtures([]byte(`{"file": {"path": "/etc/hosts"}}`))
+	assert.Error(t, err)
+	_, err = UnmarshalEventFixtures([]byte(`{"evt": {"name": "open"}, "file": {"flags": ["O_UNKNOWN"]}}`))
+	assert.Error(t, err)
+}
+
+func TestPolicyTesterEvaluate(t *testing.T) {
+	tester := newTestPolicyTester(t)
+
+	events, err := UnmarshalEventFixtures([]byte(testFixture))
+	require.NoError(t, err)
+
+	// the open events are approved by basename
+	report := tester.Evaluate(events[0])
+	assert.False(t, report.Approved)
+	require.Len(t, report.Rules, 1)
+	assert.Equal(t, "shadow_open", report.Rules[0].ID)
+	assert.False(t, report.Rules[0].Match)
+	assert.Equal(t, `open.file.path == "/etc/shadow" && process.file.name not in (["passwd", "chage"])`, report.Rules[0].ExpandedExpression)
+
+	// there is no approver for the unlink events
+	report = tester.Evaluate(events[1])
+	assert.True(t, report.Approved)
+	require.Len(t, report.Rules, 1)
+	assert.True(t, report.Rules[0].Match)
+}
+
+func TestPolicyTesterRunRuleTests(t *testing.T) {
+	tester := newTestPolicyTester(t)
+
+	results := tester.RunRuleTests()
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "log_unlink", results[0].RuleID)
+	assert.Equal(t, "test #1", results[0].Name)
+	assert.False(t, results[0].Passed)
+	assert.NotEmpty(t, results[0].Error)
+
+	for _, result := range results[1:] {
+		assert.Equal(t, "shadow_open", result.RuleID)
+		assert.True(t, result.Passed, "test %s should pass", result.Name)
+		assert.Empty(t, result.Error)
+	}
+	assert.Len(t, results[3].Events, 2)
+}
+
+func TestPolicyTesterSequence(t *testing.T) {
+	tester := newTestPolicyTesterWithFiles(t, map[string]string{
+		"test.policy":            testSequencePolicy,
+		"fixtures/sequence.json": fmt.Sprintf(testSequenceFixture, "vim", "2021-03-04T05:06:37Z"),
+		"fixtures/other.json":    fmt.Sprintf(testSequenceFixture, "cat", "2021-03-04T05:06:37Z"),
+		// the duration of the sequence is measured with the dates of the events
+		"fixtures/late.json": fmt.Sprintf(testSequenceFixture, "vim", "2021-03-04T05:08:07Z"),
+	})
+
+	results := tester.RunRuleTests()
+	require.Len(t, results, 3)
+
+	for _, result := range results {
+		assert.True(t, result.Passed, "test %s should pass", result.Name)
+		require.Len(t, result.Events, 2)
+	}
+
+	// only the last step matches, once the sequence is completed
+	steps := results[0].Events[0].Rules
+	require.Len(t, steps, 1)
+	assert.Equal(t, 1, steps[0].Step)
+	assert.False(t, steps[0].Match)
+	assert.True(t, results[0].Events[1].Rules[0].Match)
+}
+
+func TestPolicyTesterIsApproved(t *testing.T) {
+	tester := &PolicyTester{
+		approvers: map[eval.EventType]rules.Approvers{
+			"open": {
+				"open.file.path": rules.FilterValues{{Value: "/etc/shadow"}},
+				"open.flags":     rules.FilterValues{{Value: syscall.O_CREAT | syscall.O_TRUNC}},
+				"process.uid":    rules.FilterValues{{Value: 1000}},
+			},
+		},
+	}
+
+	newOpenEvent := func(path string, flags int, uid uint32) *model.Event {
+		event := &model.Event{}
+		event.Type = uint64(model.FileOpenEventType)
+		event.Open.File.PathnameStr = path
+		event.Open.Flags = uint32(flags)
+		event.ProcessContext.Process.Credentials.UID = uid
+		return event
+	}
+
+	// the paths are compared by basename
+	assert.True(t, tester.isApproved(newOpenEvent("/tmp/shadow", syscall.O_RDONLY, 0)))
+	// the flags are compared as a mask
+	assert.True(t, tester.isApproved(newOpenEvent("/tmp/hosts", syscall.O_WRONLY|syscall.O_CREAT, 0)))
+	assert.False(t, tester.isApproved(newOpenEvent("/tmp/hosts", syscall.O_WRONLY, 0)))
+	// the other values are compared by equality
+	assert.True(t, tester.isApproved(newOpenEvent("/tmp/hosts", syscall.O_RDONLY, 1000)))
+	assert.False(t, tester.isApproved(newOpenEvent("/tmp/hosts", syscall.O_RDONLY, 1001)))
+}
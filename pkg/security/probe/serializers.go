@@ -153,7 +153,21 @@ type ProcessCacheEntrySerializer struct {
 // ContainerContextSerializer serializes a container context to JSON
 // easyjson:json
 type ContainerContextSerializer struct {
-	ID string `json:"id,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Image string `json:"image,omitempty"`
+}
+
+// KubePodContextSerializer serializes the Kubernetes pod context of an event to JSON
+// easyjson:json
+type KubePodContextSerializer struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// KubeContextSerializer serializes the Kubernetes context of an event to JSON
+// easyjson:json
+type KubeContextSerializer struct {
+	Pod *KubePodContextSerializer `json:"pod,omitempty"`
 }
 
 // FileEventSerializer serializes a file event to JSON
@@ -218,6 +232,7 @@ type EventSerializer struct {
 	UserContextSerializer      UserContextSerializer       `json:"usr,omitempty"`
 	ProcessContextSerializer   *ProcessContextSerializer   `json:"process,omitempty"`
 	ContainerContextSerializer *ContainerContextSerializer `json:"container,omitempty"`
+	KubeContextSerializer      *KubeContextSerializer      `json:"kube,omitempty"`
 	Date                       time.Time                   `json:"date,omitempty"`
 }
 
@@ -483,7 +498,17 @@ func NewEventSerializer(event *Event) *EventSerializer {
 
 	if id := event.ResolveContainerID(&event.ContainerContext); id != "" {
 		s.ContainerContextSerializer = &ContainerContextSerializer{
-			ID: id,
+			ID:    id,
+			Image: event.ResolveContainerImage(&event.ContainerContext),
+		}
+	}
+
+	if namespace := event.ResolveKubePodNamespace(&event.KubeContext.Pod); namespace != "" {
+		s.KubeContextSerializer = &KubeContextSerializer{
+			Pod: &KubePodContextSerializer{
+				Name:      event.ResolveKubePodName(&event.KubeContext.Pod),
+				Namespace: namespace,
+			},
 		}
 	}
 
@@ -9,12 +9,17 @@ package probe
 
 import (
 	"context"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
 	"github.com/DataDog/datadog-agent/pkg/security/utils"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
+	taggerutils "github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/gobwas/glob"
 )
 
 // Tagger defines a Tagger for the Tags Resolver
@@ -38,9 +43,14 @@ func (n *nullTagger) Tag(entity string, cardinality collectors.TagCardinality) (
 	return nil, nil
 }
 
+// kubePodLabelsPrefix is the prefix of the fields accessing the pod labels
+const kubePodLabelsPrefix = "kube.pod.labels."
+
 // TagsResolver represents a cache resolver
 type TagsResolver struct {
-	tagger Tagger
+	tagger          Tagger
+	podLabelsAsTags map[string]string
+	podLabelsGlobs  map[string]glob.Glob
 }
 
 // Start the resolver
@@ -70,6 +80,33 @@ func (t *TagsResolver) GetValue(id string, tag string) string {
 	return utils.GetTagValue(tag, t.Resolve(id))
 }
 
+// GetPodLabelValue returns the value of a pod label from the tags of its containers, the
+// label being converted to a tag by the tagger as configured by `kubernetes_pod_labels_as_tags`
+func (t *TagsResolver) GetPodLabelValue(label string, tags []string) string {
+	for _, tagName := range taggerutils.GetMetadataAsTagNames(label, t.podLabelsAsTags, t.podLabelsGlobs) {
+		if value := utils.GetTagValue(tagName, tags); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// GetUnmappedPodLabels returns the pod labels accessed by the given fields that
+// aren't converted to tags, and thus always resolve to an empty value
+func (t *TagsResolver) GetUnmappedPodLabels(fields []eval.Field) []string {
+	var labels []string
+	for _, field := range fields {
+		if !strings.HasPrefix(field, kubePodLabelsPrefix) {
+			continue
+		}
+		label := strings.TrimPrefix(field, kubePodLabelsPrefix)
+		if len(taggerutils.GetMetadataAsTagNames(label, t.podLabelsAsTags, t.podLabelsGlobs)) == 0 {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
 // Stop the resolver
 func (t *TagsResolver) Stop() error {
 	return t.tagger.Stop()
@@ -84,7 +121,16 @@ func NewTagsResolver(config *config.Config) *TagsResolver {
 		tagger = &nullTagger{}
 	}
 
-	return &TagsResolver{
+	// copy the mapping as it is modified in place
+	podLabelsAsTags := make(map[string]string, len(config.PodLabelsAsTags))
+	for label, tagName := range config.PodLabelsAsTags {
+		podLabelsAsTags[label] = tagName
+	}
+
+	resolver := &TagsResolver{
 		tagger: tagger,
 	}
+	resolver.podLabelsAsTags, resolver.podLabelsGlobs = taggerutils.InitMetadataAsTags(podLabelsAsTags)
+
+	return resolver
 }
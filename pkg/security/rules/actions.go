@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severities of the rules, from the lowest to the highest
+const (
+	SeverityInfo     = "info"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severities = []string{SeverityInfo, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// DefaultKillSignal is the signal sent by the kill actions without signal
+const DefaultKillSignal = "SIGKILL"
+
+// KillSignals lists the signals the kill actions can send
+var KillSignals = []string{"SIGKILL", "SIGTERM", "SIGINT", "SIGQUIT", "SIGHUP", "SIGSTOP", "SIGUSR1", "SIGUSR2"}
+
+// ActionDefinition holds the definition of an action taken when a rule matches.
+// Exactly one kind of action must be defined.
+type ActionDefinition struct {
+	RateLimit *RateLimitDefinition `yaml:"rate_limit"`
+	Context   []string             `yaml:"context"`
+	Kill      *KillDefinition      `yaml:"kill"`
+	Metric    *MetricDefinition    `yaml:"metric"`
+}
+
+// RateLimitDefinition holds the definition of the rate limit of the events of a
+// rule, replacing the default limit
+type RateLimitDefinition struct {
+	Limit int `yaml:"limit"`
+	Burst int `yaml:"burst"`
+}
+
+// KillDefinition holds the definition of an action sending a signal to the
+// process of the event
+type KillDefinition struct {
+	Signal string `yaml:"signal"`
+}
+
+// MetricDefinition holds the definition of an action incrementing a custom
+// count metric
+type MetricDefinition struct {
+	Name string   `yaml:"name"`
+	Tags []string `yaml:"tags"`
+}
+
+// Check returns an error if the action is not valid
+func (ad *ActionDefinition) Check() error {
+	var kinds int
+	if ad.RateLimit != nil {
+		kinds++
+		if ad.RateLimit.Limit <= 0 || ad.RateLimit.Burst <= 0 {
+			return errors.New("the limit and the burst of a rate limit must be positive")
+		}
+	}
+	if ad.Context != nil {
+		kinds++
+		if len(ad.Context) == 0 {
+			return errors.New("no field defined for the context")
+		}
+	}
+	if ad.Kill != nil {
+		kinds++
+		if ad.Kill.Signal != "" && !containsString(KillSignals, ad.Kill.Signal) {
+			return fmt.Errorf("unsupported signal `%s`, supported signals are %s", ad.Kill.Signal, strings.Join(KillSignals, ", "))
+		}
+	}
+	if ad.Metric != nil {
+		kinds++
+		if ad.Metric.Name == "" {
+			return errors.New("no name defined for the metric")
+		}
+	}
+
+	switch kinds {
+	case 0:
+		return errors.New("no action defined")
+	case 1:
+		return nil
+	default:
+		return errors.New("multiple actions defined in the same entry")
+	}
+}
+
+// GetSignal returns the signal sent by a kill action
+func (kd *KillDefinition) GetSignal() string {
+	if kd.Signal == "" {
+		return DefaultKillSignal
+	}
+	return kd.Signal
+}
+
+// checkSeverity returns an error if the severity is set and unknown
+func checkSeverity(severity string) error {
+	if severity != "" && !containsString(severities, severity) {
+		return fmt.Errorf("unknown severity `%s`, supported severities are %s", severity, strings.Join(severities, ", "))
+	}
+	return nil
+}
+
+// checkFilter returns an error if the filter isn't a valid `key:pattern` tag
+// filter
+func checkFilter(filter string) error {
+	parts := strings.SplitN(filter, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid filter `%s`, the filters must be in the `key:pattern` format", filter)
+	}
+	if _, err := path.Match(parts[1], ""); err != nil {
+		return fmt.Errorf("invalid filter `%s`: %s", filter, err)
+	}
+	return nil
+}
+
+// RuleFilter describes a filter deciding whether the rules are loaded
+type RuleFilter interface {
+	IsRuleAccepted(rule *RuleDefinition) bool
+}
+
+// HostTagsFilter accepts the rules whose filters all match a tag of the host.
+// A filter is a tag whose value is a pattern, like `env:prod-*`.
+type HostTagsFilter struct {
+	Tags []string
+}
+
+// IsRuleAccepted returns whether every filter of the rule matches a host tag
+func (f *HostTagsFilter) IsRuleAccepted(rule *RuleDefinition) bool {
+	for _, filter := range rule.Filters {
+		parts := strings.SplitN(filter, ":", 2)
+		if len(parts) != 2 {
+			return false
+		}
+
+		var matched bool
+		for _, tag := range f.Tags {
+			tagParts := strings.SplitN(tag, ":", 2)
+			if len(tagParts) != 2 || tagParts[0] != parts[0] {
+				continue
+			}
+			if matched, _ = path.Match(parts[1], tagParts[1]); matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Combine policies of the macros and the rules defined with the ID of a macro
+// or a rule of a previous policy
+const (
+	// OverridePolicy replaces the fields set by the definition
+	OverridePolicy = "override"
+	// MergePolicy appends the values of the definition to the values of a macro
+	MergePolicy = "merge"
+)
+
+// GetExpression returns the expression of the macro, a list of its values for
+// the macros defined by their values
+func (md *MacroDefinition) GetExpression() string {
+	if len(md.Values) == 0 {
+		return md.Expression
+	}
+
+	values := make([]string, 0, len(md.Values))
+	for _, value := range md.Values {
+		values = append(values, strconv.Quote(value))
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// combine applies the definition of a macro with the same ID, according to its
+// combine policy
+func (md *MacroDefinition) combine(macroDef *MacroDefinition) error {
+	switch macroDef.Combine {
+	case MergePolicy:
+		if len(md.Values) == 0 {
+			return errors.New("only the values of a macro can be merged")
+		}
+		for _, value := range macroDef.Values {
+			if !containsString(md.Values, value) {
+				md.Values = append(md.Values, value)
+			}
+		}
+	case OverridePolicy:
+		md.Expression, md.Values = macroDef.Expression, macroDef.Values
+	default:
+		return fmt.Errorf("unknown combine policy `%s`", macroDef.Combine)
+	}
+	return nil
+}
+
+// override replaces the fields of the rule set by the definition of a rule with
+// the same ID. The tags are merged, and an override can only disable a rule.
+func (rd *RuleDefinition) override(ruleDef *RuleDefinition) {
+	if ruleDef.Expression != "" {
+		rd.Expression, rd.Sequence = ruleDef.Expression, nil
+	}
+	if ruleDef.Sequence != nil {
+		rd.Expression, rd.Sequence = "", ruleDef.Sequence
+	}
+	if ruleDef.Threshold != nil {
+		rd.Threshold = ruleDef.Threshold
+	}
+	if ruleDef.Description != "" {
+		rd.Description = ruleDef.Description
+	}
+	if len(ruleDef.Tags) > 0 {
+		tags := make(map[string]string, len(rd.Tags)+len(ruleDef.Tags))
+		for k, v := range rd.Tags {
+			tags[k] = v
+		}
+		for k, v := range ruleDef.Tags {
+			tags[k] = v
+		}
+		rd.Tags = tags
+	}
+	if ruleDef.Severity != "" {
+		rd.Severity = ruleDef.Severity
+	}
+	if ruleDef.Actions != nil {
+		rd.Actions = ruleDef.Actions
+	}
+	if ruleDef.Filters != nil {
+		rd.Filters = ruleDef.Filters
+	}
+	if ruleDef.Tests != nil {
+		rd.Tests = ruleDef.Tests
+	}
+	if ruleDef.Disabled {
+		rd.Disabled = true
+	}
+
+	rd.OverriddenBy = append(rd.OverriddenBy, ruleDef.Policy)
+}
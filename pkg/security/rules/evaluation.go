@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// EvaluationReport describes the evaluation of an event by a ruleset
+type EvaluationReport struct {
+	EventType  eval.EventType    `json:"event_type"`
+	Rules      []*RuleEvaluation `json:"rules"`
+	Discarders []eval.Field      `json:"discarders,omitempty"`
+}
+
+// RuleEvaluation describes the evaluation of an event by a rule, or by a step,
+// numbered from 1, of a sequence rule. A sequence or a threshold rule only
+// matches the event completing the sequence or reaching the threshold.
+type RuleEvaluation struct {
+	ID                 RuleID           `json:"id"`
+	Step               int              `json:"step,omitempty"`
+	Match              bool             `json:"match"`
+	ExpandedExpression string           `json:"expanded_expression"`
+	Macros             map[MacroID]bool `json:"macros,omitempty"`
+}
+
+// evaluationRecorder records the rules matching an event and its discarders
+type evaluationRecorder struct {
+	matches    map[RuleID]bool
+	discarders []eval.Field
+}
+
+func (r *evaluationRecorder) RuleMatch(rule *Rule, event eval.Event) {
+	r.matches[rule.ID] = true
+}
+
+func (r *evaluationRecorder) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+	r.discarders = append(r.discarders, field)
+}
+
+// EvaluateWithReport evaluates the event like Evaluate, the listeners of the
+// ruleset being notified, and reports the evaluation of every rule of the event
+// type, with the values of the boolean macros they use. It must not be called
+// concurrently with other evaluations.
+func (rs *RuleSet) EvaluateWithReport(event eval.Event) *EvaluationReport {
+	recorder := &evaluationRecorder{matches: make(map[RuleID]bool)}
+	rs.listeners = append(rs.listeners, recorder)
+	rs.Evaluate(event)
+	rs.listeners = rs.listeners[:len(rs.listeners)-1]
+
+	report := &EvaluationReport{
+		EventType:  event.GetType(),
+		Discarders: recorder.discarders,
+	}
+
+	bucket, exists := rs.eventRuleBuckets[event.GetType()]
+	if !exists {
+		return report
+	}
+
+	ctx := rs.pool.Get(event.GetPointer())
+	defer rs.pool.Put(ctx)
+
+	for _, rule := range bucket.rules {
+		expanded, macros := rs.ExpandMacros(rule.Expression)
+
+		evaluation := &RuleEvaluation{
+			ID:                 rule.Definition.ID,
+			Match:              recorder.matches[rule.Definition.ID],
+			ExpandedExpression: expanded,
+		}
+		if rule.state != nil && rule.state.sequence != nil {
+			evaluation.Step = rule.state.step + 1
+		}
+
+		for _, id := range macros {
+			evaluator, ok := rs.opts.Macros[id].GetEvaluator().Value.(*eval.BoolEvaluator)
+			if !ok {
+				continue
+			}
+			if evaluation.Macros == nil {
+				evaluation.Macros = make(map[MacroID]bool)
+			}
+			if evaluator.EvalFnc != nil {
+				evaluation.Macros[id] = evaluator.EvalFnc(ctx)
+			} else {
+				evaluation.Macros[id] = evaluator.Value
+			}
+		}
+
+		report.Rules = append(report.Rules, evaluation)
+	}
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].ID != report.Rules[j].ID {
+			return report.Rules[i].ID < report.Rules[j].ID
+		}
+		return report.Rules[i].Step < report.Rules[j].Step
+	})
+
+	return report
+}
+
+// ExpandMacros returns the expression with the macros it uses, directly or not,
+// replaced by their expression, and the IDs of these macros
+func (rs *RuleSet) ExpandMacros(expression string) (string, []MacroID) {
+	var macros []MacroID
+	used := make(map[MacroID]bool)
+
+	// macros can only use the macros defined before them, so can't be recursive
+	var expand func(expression string) string
+	expand = func(expression string) string {
+		var expanded strings.Builder
+
+		for i := 0; i < len(expression); {
+			c := expression[i]
+
+			switch {
+			case c == '"':
+				// strings, patterns and regexps are copied as is
+				j := i + 1
+				for j < len(expression) && expression[j] != '"' {
+					if expression[j] == '\\' {
+						j++
+					}
+					j++
+				}
+				if j < len(expression) {
+					j++
+				}
+				expanded.WriteString(expression[i:j])
+				i = j
+			case isIdentStart(c):
+				j := i + 1
+				for j < len(expression) && isIdentChar(expression[j]) {
+					j++
+				}
+				ident := expression[i:j]
+
+				if macro, isMacro := rs.opts.Macros[ident]; isMacro {
+					if !used[ident] {
+						used[ident] = true
+						macros = append(macros, ident)
+					}
+					expanded.WriteString("(" + expand(macro.Expression) + ")")
+				} else {
+					expanded.WriteString(ident)
+				}
+				i = j
+			default:
+				expanded.WriteByte(c)
+				i++
+			}
+		}
+
+		return expanded.String()
+	}
+
+	return expand(expression), macros
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c == '.' || c == '[' || c == ']' || (c >= '0' && c <= '9')
+}
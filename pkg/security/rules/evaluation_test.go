@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+func newEvaluationRuleSet(t *testing.T) *RuleSet {
+	enabled := map[eval.EventType]bool{"*": true}
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, NewOptsWithParams(testConstants, testSupportedDiscarders, enabled, nil, nil))
+
+	macros := []*MacroDefinition{
+		{ID: "root", Expression: `process.uid == 0`},
+		{ID: "shells", Expression: `["bash", "sh"]`},
+		{ID: "root_shell", Expression: `root && process.name in shells`},
+	}
+	for _, macro := range macros {
+		if _, err := rs.AddMacro(macro); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	addRuleExpr(t, rs,
+		`root_shell && open.filename == "/etc/shadow"`,
+		`open.filename == "/etc/hosts" && process.uid != 0`,
+		`mkdir.filename == "/tmp/root"`,
+	)
+
+	return rs
+}
+
+func TestExpandMacros(t *testing.T) {
+	rs := newEvaluationRuleSet(t)
+
+	expanded, macros := rs.ExpandMacros(`root_shell && open.filename == "/etc/root_shell" && root`)
+
+	expected := `((process.uid == 0) && process.name in (["bash", "sh"])) && open.filename == "/etc/root_shell" && (process.uid == 0)`
+	if expanded != expected {
+		t.Errorf("unexpected expanded expression: %s", expanded)
+	}
+	if !reflect.DeepEqual(macros, []MacroID{"root_shell", "root", "shells"}) {
+		t.Errorf("unexpected macros: %v", macros)
+	}
+}
+
+func TestEvaluateWithReport(t *testing.T) {
+	rs := newEvaluationRuleSet(t)
+
+	handler := &testHandler{model: &testModel{}, filters: make(map[string]testFieldValues)}
+	rs.AddListener(handler)
+
+	event := &testEvent{
+		kind: "open",
+		process: testProcess{
+			name: "bash",
+			uid:  0,
+		},
+		open: testOpen{
+			filename: "/etc/shadow",
+		},
+	}
+
+	report := rs.EvaluateWithReport(event)
+	if report.EventType != "open" || len(report.Rules) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	if rule := report.Rules[0]; rule.ID != "ID0" || !rule.Match || !reflect.DeepEqual(rule.Macros, map[MacroID]bool{"root": true, "root_shell": true}) {
+		t.Errorf("unexpected evaluation of ID0: %+v", rule)
+	}
+	if rule := report.Rules[1]; rule.ID != "ID1" || rule.Match || rule.Macros != nil {
+		t.Errorf("unexpected evaluation of ID1: %+v", rule)
+	}
+	if len(report.Discarders) != 0 {
+		t.Errorf("unexpected discarders: %v", report.Discarders)
+	}
+
+	// the recorder of the report isn't kept as a listener
+	if len(rs.listeners) != 1 {
+		t.Errorf("unexpected listeners: %v", rs.listeners)
+	}
+
+	event.open.filename = "/etc/fstab"
+	event.process.uid = 1000
+
+	report = rs.EvaluateWithReport(event)
+	if report.Rules[0].Match || report.Rules[1].Match {
+		t.Errorf("unexpected match: %+v", report.Rules)
+	}
+	if !reflect.DeepEqual(report.Rules[0].Macros, map[MacroID]bool{"root": false, "root_shell": false}) {
+		t.Errorf("unexpected macros: %v", report.Rules[0].Macros)
+	}
+	if !reflect.DeepEqual(report.Discarders, []eval.Field{"open.filename"}) {
+		t.Errorf("unexpected discarders: %v", report.Discarders)
+	}
+	if _, found := handler.filters["open"]["open.filename"]; !found {
+		t.Error("the listeners of the ruleset should be notified")
+	}
+}
@@ -17,6 +17,8 @@ import (
 
 type testProcess struct {
 	name   string
+	pid    int
+	ppid   int
 	uid    int
 	gid    int
 	isRoot bool
@@ -33,6 +35,10 @@ type testMkdir struct {
 	mode     int
 }
 
+type testExec struct {
+	filename string
+}
+
 type testEvent struct {
 	id   string
 	kind string
@@ -40,6 +46,7 @@ type testEvent struct {
 	process testProcess
 	open    testOpen
 	mkdir   testMkdir
+	exec    testExec
 }
 
 type testModel struct {
@@ -94,6 +101,20 @@ func (m *testModel) GetEvaluator(key string, regID eval.RegisterID) (eval.Evalua
 			Field:   key,
 		}, nil
 
+	case "process.pid":
+
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int { return (*testEvent)(ctx.Object).process.pid },
+			Field:   key,
+		}, nil
+
+	case "process.ppid":
+
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int { return (*testEvent)(ctx.Object).process.ppid },
+			Field:   key,
+		}, nil
+
 	case "process.uid":
 
 		return &eval.IntEvaluator{
@@ -150,6 +171,13 @@ func (m *testModel) GetEvaluator(key string, regID eval.RegisterID) (eval.Evalua
 			Field:   key,
 		}, nil
 
+	case "exec.filename":
+
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string { return (*testEvent)(ctx.Object).exec.filename },
+			Field:   key,
+		}, nil
+
 	}
 
 	return nil, &eval.ErrFieldNotFound{Field: key}
@@ -162,6 +190,14 @@ func (e *testEvent) GetFieldValue(key string) (interface{}, error) {
 
 		return e.process.name, nil
 
+	case "process.pid":
+
+		return e.process.pid, nil
+
+	case "process.ppid":
+
+		return e.process.ppid, nil
+
 	case "process.uid":
 
 		return e.process.uid, nil
@@ -194,6 +230,10 @@ func (e *testEvent) GetFieldValue(key string) (interface{}, error) {
 
 		return e.mkdir.mode, nil
 
+	case "exec.filename":
+
+		return e.exec.filename, nil
+
 	}
 
 	return nil, &eval.ErrFieldNotFound{Field: key}
@@ -206,6 +246,14 @@ func (e *testEvent) GetFieldEventType(key string) (string, error) {
 
 		return "*", nil
 
+	case "process.pid":
+
+		return "*", nil
+
+	case "process.ppid":
+
+		return "*", nil
+
 	case "process.uid":
 
 		return "*", nil
@@ -238,6 +286,10 @@ func (e *testEvent) GetFieldEventType(key string) (string, error) {
 
 		return "mkdir", nil
 
+	case "exec.filename":
+
+		return "exec", nil
+
 	}
 
 	return "", &eval.ErrFieldNotFound{Field: key}
@@ -251,6 +303,16 @@ func (e *testEvent) SetFieldValue(key string, value interface{}) error {
 		e.process.name = value.(string)
 		return nil
 
+	case "process.pid":
+
+		e.process.pid = value.(int)
+		return nil
+
+	case "process.ppid":
+
+		e.process.ppid = value.(int)
+		return nil
+
 	case "process.uid":
 
 		e.process.uid = value.(int)
@@ -291,6 +353,11 @@ func (e *testEvent) SetFieldValue(key string, value interface{}) error {
 		e.mkdir.mode = value.(int)
 		return nil
 
+	case "exec.filename":
+
+		e.exec.filename = value.(string)
+		return nil
+
 	}
 
 	return &eval.ErrFieldNotFound{Field: key}
@@ -303,6 +370,14 @@ func (e *testEvent) GetFieldType(key string) (reflect.Kind, error) {
 
 		return reflect.String, nil
 
+	case "process.pid":
+
+		return reflect.Int, nil
+
+	case "process.ppid":
+
+		return reflect.Int, nil
+
 	case "process.uid":
 
 		return reflect.Int, nil
@@ -335,6 +410,10 @@ func (e *testEvent) GetFieldType(key string) (reflect.Kind, error) {
 
 		return reflect.Int, nil
 
+	case "exec.filename":
+
+		return reflect.String, nil
+
 	}
 
 	return reflect.Invalid, &eval.ErrFieldNotFound{Field: key}
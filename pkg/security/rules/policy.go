@@ -21,10 +21,12 @@ import (
 
 // Policy represents a policy file which is composed of a list of rules and macros
 type Policy struct {
-	Name    string
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Name     string
+	Source   string
+	Version  string             `yaml:"version"`
+	Severity string             `yaml:"severity"`
+	Rules    []*RuleDefinition  `yaml:"rules"`
+	Macros   []*MacroDefinition `yaml:"macros"`
 }
 
 var ruleIDPattern = `^([a-zA-Z0-9]*_*)*$`
@@ -40,6 +42,10 @@ func (p *Policy) GetValidMacroAndRules() ([]*MacroDefinition, []*RuleDefinition,
 	var macros []*MacroDefinition
 	var rules []*RuleDefinition
 
+	if err := checkSeverity(p.Severity); err != nil {
+		result = multierror.Append(result, &ErrPolicyLoad{Name: p.Name, Err: err})
+	}
+
 	for _, macroDef := range p.Macros {
 		if macroDef.ID == "" {
 			result = multierror.Append(result, &ErrMacroLoad{Err: fmt.Errorf("no ID defined for macro with expression `%s`", macroDef.Expression)})
@@ -50,8 +56,8 @@ func (p *Policy) GetValidMacroAndRules() ([]*MacroDefinition, []*RuleDefinition,
 			continue
 		}
 
-		if macroDef.Expression == "" {
-			result = multierror.Append(result, &ErrMacroLoad{Definition: macroDef, Err: errors.New("no expression defined")})
+		if err := checkMacroDefinition(macroDef); err != nil {
+			result = multierror.Append(result, &ErrMacroLoad{Definition: macroDef, Err: err})
 			continue
 		}
 		macros = append(macros, macroDef)
@@ -69,17 +75,82 @@ func (p *Policy) GetValidMacroAndRules() ([]*MacroDefinition, []*RuleDefinition,
 			continue
 		}
 
-		if ruleDef.Expression == "" {
+		if ruleDef.Expression == "" && ruleDef.Sequence == nil && ruleDef.Combine != OverridePolicy {
 			result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: errors.New("no expression defined")})
 			continue
 		}
 
+		if err := checkRuleDefinition(ruleDef); err != nil {
+			result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: err})
+			continue
+		}
+
 		rules = append(rules, ruleDef)
 	}
 
 	return macros, rules, result
 }
 
+// checkMacroDefinition returns an error if the macro doesn't define either an
+// expression or values, or if its combine policy is unknown
+func checkMacroDefinition(macroDef *MacroDefinition) error {
+	switch {
+	case macroDef.Expression == "" && len(macroDef.Values) == 0:
+		return errors.New("no expression defined")
+	case macroDef.Expression != "" && len(macroDef.Values) != 0:
+		return errors.New("both an expression and values defined")
+	}
+
+	switch macroDef.Combine {
+	case "", OverridePolicy:
+	case MergePolicy:
+		if len(macroDef.Values) == 0 {
+			return errors.New("only the values of a macro can be merged")
+		}
+	default:
+		return fmt.Errorf("unknown combine policy `%s`", macroDef.Combine)
+	}
+
+	return nil
+}
+
+// checkRuleDefinition returns an error if the severity, the actions, the
+// filters, the sequence or the combine policy of the rule are invalid
+func checkRuleDefinition(ruleDef *RuleDefinition) error {
+	if err := checkSeverity(ruleDef.Severity); err != nil {
+		return err
+	}
+
+	if err := checkSequenceDefinition(ruleDef); err != nil {
+		return err
+	}
+
+	if ruleDef.Combine != "" && ruleDef.Combine != OverridePolicy {
+		return fmt.Errorf("unknown combine policy `%s`", ruleDef.Combine)
+	}
+
+	var rateLimits int
+	for i, action := range ruleDef.Actions {
+		if err := action.Check(); err != nil {
+			return errors.Wrapf(err, "invalid action #%d", i+1)
+		}
+		if action.RateLimit != nil {
+			rateLimits++
+		}
+	}
+	if rateLimits > 1 {
+		return errors.New("multiple rate limits defined")
+	}
+
+	for _, filter := range ruleDef.Filters {
+		if err := checkFilter(filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LoadPolicy loads a YAML file and returns a new policy
 func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 	policy := &Policy{Name: name}
@@ -92,16 +163,17 @@ func LoadPolicy(r io.Reader, name string) (*Policy, error) {
 	return policy, nil
 }
 
-// LoadPolicies loads the policies listed in the configuration and apply them to the given ruleset
-func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
+// loadPoliciesDir loads and parses the policy files of a directory, sorted by
+// name. The fixtures of the rule tests are made relative to the directory.
+func loadPoliciesDir(policiesDir string, ruleSet *RuleSet) ([]*Policy, *multierror.Error) {
 	var (
 		result   *multierror.Error
-		allRules []*RuleDefinition
+		policies []*Policy
 	)
 
 	policyFiles, err := ioutil.ReadDir(policiesDir)
 	if err != nil {
-		return multierror.Append(result, ErrPoliciesLoad{Name: policiesDir, Err: err})
+		return nil, multierror.Append(result, ErrPoliciesLoad{Name: policiesDir, Err: err})
 	}
 	sort.Slice(policyFiles, func(i, j int) bool { return policyFiles[i].Name() < policyFiles[j].Name() })
 
@@ -129,30 +201,114 @@ func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
 			result = multierror.Append(result, err)
 			continue
 		}
+		policy.Source = policiesDir
+
+		for _, rule := range policy.Rules {
+			for _, test := range rule.Tests {
+				if test.Fixture != "" && !filepath.IsAbs(test.Fixture) {
+					test.Fixture = filepath.Join(policiesDir, test.Fixture)
+				}
+			}
+		}
 
 		// Add policy version for logging purposes
 		ruleSet.AddPolicyVersion(filename, policy.Version)
 
-		macros, rules, mErr := policy.GetValidMacroAndRules()
+		policies = append(policies, policy)
+	}
+
+	return policies, result
+}
+
+// LoadPolicies loads the policies listed in the configuration and apply them to the given ruleset
+func LoadPolicies(policiesDir string, ruleSet *RuleSet) *multierror.Error {
+	return LoadPoliciesFromDirs([]string{policiesDir}, ruleSet)
+}
+
+// LoadPoliciesFromDirs loads the policies of the directories and apply them to
+// the given ruleset. The directories are loaded in order, so that their policies
+// can override the rules and the macros of the previous policies, using
+// `combine: override`, or extend the values of their macros, using
+// `combine: merge`.
+func LoadPoliciesFromDirs(policiesDirs []string, ruleSet *RuleSet) *multierror.Error {
+	var (
+		result *multierror.Error
+		macros []*MacroDefinition
+		rules  []*RuleDefinition
+	)
+
+	macroIndex := make(map[MacroID]*MacroDefinition)
+	ruleIndex := make(map[RuleID]*RuleDefinition)
+
+	for _, policiesDir := range policiesDirs {
+		policies, mErr := loadPoliciesDir(policiesDir, ruleSet)
 		if mErr.ErrorOrNil() != nil {
 			result = multierror.Append(result, mErr)
 		}
 
-		if len(macros) > 0 {
-			// Add the macros to the ruleset and generate macros evaluators
-			if err := ruleSet.AddMacros(macros); err != nil {
-				result = multierror.Append(result, err)
+		for _, policy := range policies {
+			policyMacros, policyRules, mErr := policy.GetValidMacroAndRules()
+			if mErr.ErrorOrNil() != nil {
+				result = multierror.Append(result, mErr)
+			}
+
+			for _, macroDef := range policyMacros {
+				existing, exists := macroIndex[macroDef.ID]
+				switch {
+				case !exists && macroDef.Combine != "":
+					result = multierror.Append(result, &ErrMacroLoad{Definition: macroDef, Err: fmt.Errorf("no macro to %s", macroDef.Combine)})
+				case !exists:
+					macroIndex[macroDef.ID] = macroDef
+					macros = append(macros, macroDef)
+				case macroDef.Combine == "":
+					result = multierror.Append(result, &ErrMacroLoad{Definition: macroDef, Err: errors.New("multiple definition with the same ID")})
+				default:
+					if err := existing.combine(macroDef); err != nil {
+						result = multierror.Append(result, &ErrMacroLoad{Definition: macroDef, Err: err})
+					}
+				}
 			}
+
+			for _, ruleDef := range policyRules {
+				existing, exists := ruleIndex[ruleDef.ID]
+				switch {
+				case !exists && ruleDef.Combine != "":
+					result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: errors.New("no rule to override")})
+				case !exists:
+					ruleIndex[ruleDef.ID] = ruleDef
+					rules = append(rules, ruleDef)
+				case ruleDef.Combine == "":
+					result = multierror.Append(result, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict})
+				default:
+					existing.override(ruleDef)
+				}
+			}
+		}
+	}
+
+	// Add the macros to the ruleset and generate macros evaluators
+	for _, macroDef := range macros {
+		if _, err := ruleSet.AddMacro(macroDef); err != nil {
+			result = multierror.Append(result, err)
 		}
+	}
 
-		// aggregates them as we may need to have all the macro before compiling
-		if len(rules) > 0 {
-			allRules = append(allRules, rules...)
+	// the rules are added once all the macros are, and the rules overridden
+	var enabledRules []*RuleDefinition
+	for _, ruleDef := range rules {
+		if ruleDef.Disabled {
+			ruleSet.logger.Debugf("rule `%s` disabled", ruleDef.ID)
+			continue
+		}
+		if ruleSet.isRuleFiltered(ruleDef) {
+			ruleSet.logger.Debugf("rule `%s` filtered out on this host", ruleDef.ID)
+			continue
 		}
+		enabledRules = append(enabledRules, ruleDef)
 	}
 
 	// Add rules to the ruleset and generate rules evaluators
-	if err := ruleSet.AddRules(allRules); err.ErrorOrNil() != nil {
+	if err := ruleSet.AddRules(enabledRules); err.ErrorOrNil() != nil {
 		result = multierror.Append(result, err)
 	}
 
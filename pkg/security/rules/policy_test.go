@@ -0,0 +1,309 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+const testActionsPolicy = `---
+version: 1.0.0
+severity: medium
+rules:
+  - id: shadow
+    expression: open.filename == "/etc/shadow"
+    severity: critical
+    actions:
+      - rate_limit:
+          limit: 1
+          burst: 5
+      - context: ["process.name"]
+      - kill:
+          signal: SIGTERM
+      - metric:
+          name: custom.shadow.open
+          tags: ["team:sec"]
+  - id: hosts
+    expression: open.filename == "/etc/hosts"
+  - id: disabled
+    expression: open.filename == "/etc/passwd"
+    disabled: true
+  - id: prod
+    expression: mkdir.filename == "/tmp/prod"
+    filters: ["env:prod-*"]
+  - id: invalid_severity
+    expression: open.filename == "/etc/group"
+    severity: urgent
+  - id: invalid_action
+    expression: open.filename == "/etc/group"
+    actions:
+      - kill: {}
+        metric:
+          name: custom.group.open
+  - id: invalid_signal
+    expression: open.filename == "/etc/group"
+    actions:
+      - kill:
+          signal: SIGSEGV
+  - id: invalid_filter
+    expression: open.filename == "/etc/group"
+    filters: ["prod"]
+`
+
+func loadTestPolicy(t *testing.T, content string) *Policy {
+	policy, err := LoadPolicy(strings.NewReader(content), "test.policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestPolicyRuleActions(t *testing.T) {
+	policy := loadTestPolicy(t, testActionsPolicy)
+
+	_, rules, merr := policy.GetValidMacroAndRules()
+
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	if strings.Join(ids, ",") != "shadow,hosts,disabled,prod" {
+		t.Errorf("unexpected valid rules: %v", ids)
+	}
+
+	if merr == nil || len(merr.Errors) != 4 {
+		t.Fatalf("expected 4 errors, got %v", merr)
+	}
+	for i, id := range []string{"invalid_severity", "invalid_action", "invalid_signal", "invalid_filter"} {
+		if err, ok := merr.Errors[i].(*ErrRuleLoad); !ok || err.Definition.ID != id {
+			t.Errorf("unexpected error %d: %s", i, merr.Errors[i])
+		}
+	}
+
+	shadow, hosts := rules[0], rules[1]
+	if shadow.GetSeverity() != SeverityCritical || hosts.GetSeverity() != SeverityMedium {
+		t.Errorf("unexpected severities: %s, %s", shadow.GetSeverity(), hosts.GetSeverity())
+	}
+	if rl := shadow.GetRateLimit(); rl == nil || rl.Limit != 1 || rl.Burst != 5 {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+	if hosts.GetRateLimit() != nil {
+		t.Error("no rate limit expected")
+	}
+	if fields := shadow.GetContextFields(); len(fields) != 1 || fields[0] != "process.name" {
+		t.Errorf("unexpected context fields: %v", fields)
+	}
+	if signal := shadow.Actions[2].Kill.GetSignal(); signal != "SIGTERM" {
+		t.Errorf("unexpected signal: %s", signal)
+	}
+}
+
+func TestPolicyInvalidSeverity(t *testing.T) {
+	policy := loadTestPolicy(t, `---
+severity: urgent
+rules:
+  - id: hosts
+    expression: open.filename == "/etc/hosts"
+`)
+
+	if _, rules, merr := policy.GetValidMacroAndRules(); merr.ErrorOrNil() == nil || len(rules) != 1 {
+		t.Errorf("the policy severity should be invalid: %v", merr)
+	}
+}
+
+func TestRuleContextFields(t *testing.T) {
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil))
+
+	valid := &RuleDefinition{
+		ID:         "valid",
+		Expression: `open.filename == "/etc/hosts"`,
+		Actions:    []*ActionDefinition{{Context: []string{"process.name", "process.uid"}}},
+	}
+	if _, err := rs.AddRule(valid); err != nil {
+		t.Error(err)
+	}
+
+	invalid := &RuleDefinition{
+		ID:         "invalid",
+		Expression: `open.filename == "/etc/shadow"`,
+		Actions:    []*ActionDefinition{{Context: []string{"process.unknown"}}},
+	}
+	if _, err := rs.AddRule(invalid); err == nil {
+		t.Error("the context field should be invalid")
+	}
+}
+
+func TestHostTagsFilter(t *testing.T) {
+	filter := &HostTagsFilter{Tags: []string{"env:prod-eu", "team:sec"}}
+
+	tests := []struct {
+		filters  []string
+		accepted bool
+	}{
+		{nil, true},
+		{[]string{"env:prod-*"}, true},
+		{[]string{"env:prod-*", "team:sec"}, true},
+		{[]string{"env:prod-*", "team:web"}, false},
+		{[]string{"env:staging"}, false},
+		{[]string{"region:*"}, false},
+	}
+
+	for _, test := range tests {
+		if accepted := filter.IsRuleAccepted(&RuleDefinition{Filters: test.filters}); accepted != test.accepted {
+			t.Errorf("filters %v: expected %v, got %v", test.filters, test.accepted, accepted)
+		}
+	}
+}
+
+func TestLoadPoliciesFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	policy := `---
+rules:
+  - id: hosts
+    expression: open.filename == "/etc/hosts"
+  - id: prod
+    expression: mkdir.filename == "/tmp/prod"
+    filters: ["env:prod"]
+  - id: disabled
+    expression: open.filename == "/etc/passwd"
+    disabled: true
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "test.policy"), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for env, expected := range map[string]int{"prod": 2, "staging": 1} {
+		opts := NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil)
+		opts.RuleFilters = []RuleFilter{&HostTagsFilter{Tags: []string{"env:" + env}}}
+		rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, opts)
+
+		if err := LoadPolicies(dir, rs); err.ErrorOrNil() != nil {
+			t.Fatal(err)
+		}
+		if len(rs.GetRules()) != expected {
+			t.Errorf("env %s: expected %d rules, got %v", env, expected, rs.ListRuleIDs())
+		}
+	}
+}
+
+func TestLoadPoliciesFromDirs(t *testing.T) {
+	policies := map[string]string{
+		"default": `---
+macros:
+  - id: shells
+    values: ["bash", "sh"]
+  - id: root
+    expression: process.uid == 0
+rules:
+  - id: shell_shadow
+    expression: open.filename == "/etc/shadow" && process.name in shells
+    tags:
+      team: sec
+  - id: hosts
+    expression: open.filename == "/etc/hosts"
+  - id: tmp
+    expression: mkdir.filename == "/tmp/root" && root
+`,
+		"override": `---
+macros:
+  - id: shells
+    values: ["zsh", "sh"]
+    combine: merge
+  - id: root
+    expression: process.uid == 1
+    combine: override
+rules:
+  - id: shell_shadow
+    expression: open.filename == "/etc/gshadow" && process.name in shells
+    combine: override
+    tags:
+      env: prod
+  - id: hosts
+    combine: override
+    disabled: true
+  - id: tmp
+    expression: mkdir.filename == "/tmp/other"
+  - id: unknown
+    combine: override
+    disabled: true
+`,
+	}
+
+	root, err := ioutil.TempDir("", "policies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	var dirs []string
+	for _, name := range []string{"default", "override"} {
+		dir := filepath.Join(root, name)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".policy"), []byte(policies[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil)
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, opts)
+
+	merr := LoadPoliciesFromDirs(dirs, rs)
+	if merr == nil || len(merr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", merr)
+	}
+	for i, id := range []string{"tmp", "unknown"} {
+		if err, ok := merr.Errors[i].(*ErrRuleLoad); !ok || err.Definition.ID != id {
+			t.Errorf("unexpected error %d: %s", i, merr.Errors[i])
+		}
+	}
+
+	if len(rs.GetRules()) != 2 || rs.GetRules()["hosts"] != nil {
+		t.Fatalf("unexpected rules: %v", rs.ListRuleIDs())
+	}
+
+	shellShadow := rs.GetRules()["shell_shadow"].Definition
+	if shellShadow.Expression != `open.filename == "/etc/gshadow" && process.name in shells` {
+		t.Errorf("unexpected expression: %s", shellShadow.Expression)
+	}
+	if len(shellShadow.Tags) != 2 {
+		t.Errorf("unexpected tags: %v", shellShadow.Tags)
+	}
+	if shellShadow.Policy.Source != dirs[0] || len(shellShadow.OverriddenBy) != 1 || shellShadow.OverriddenBy[0].Source != dirs[1] {
+		t.Errorf("unexpected origin: %s, %v", shellShadow.Policy.Source, shellShadow.OverriddenBy)
+	}
+
+	event := &testEvent{
+		kind:    "open",
+		process: testProcess{name: "zsh", uid: 1},
+		open:    testOpen{filename: "/etc/gshadow"},
+	}
+	if report := rs.EvaluateWithReport(event); len(report.Rules) != 1 || !report.Rules[0].Match {
+		t.Errorf("the merged macro should match: %+v", report.Rules)
+	}
+
+	event = &testEvent{
+		kind:    "mkdir",
+		process: testProcess{uid: 1},
+		mkdir:   testMkdir{filename: "/tmp/root"},
+	}
+	if report := rs.EvaluateWithReport(event); len(report.Rules) != 1 || !report.Rules[0].Match {
+		t.Errorf("the overridden macro should match: %+v", report.Rules)
+	}
+}
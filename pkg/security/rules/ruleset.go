@@ -20,8 +20,10 @@ type MacroID = string
 
 // MacroDefinition holds the definition of a macro
 type MacroDefinition struct {
-	ID         MacroID `yaml:"id"`
-	Expression string  `yaml:"expression"`
+	ID         MacroID  `yaml:"id"`
+	Expression string   `yaml:"expression"`
+	Values     []string `yaml:"values"`
+	Combine    string   `yaml:"combine"`
 }
 
 // Macro describes a macro of a ruleset
@@ -35,11 +37,31 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID          RuleID            `yaml:"id"`
-	Expression  string            `yaml:"expression"`
-	Description string            `yaml:"description"`
-	Tags        map[string]string `yaml:"tags"`
+	ID          RuleID                `yaml:"id"`
+	Expression  string                `yaml:"expression"`
+	Description string                `yaml:"description"`
+	Tags        map[string]string     `yaml:"tags"`
+	Severity    string                `yaml:"severity"`
+	Actions     []*ActionDefinition   `yaml:"actions"`
+	Disabled    bool                  `yaml:"disabled"`
+	Filters     []string              `yaml:"filters"`
+	Sequence    *SequenceDefinition   `yaml:"sequence"`
+	Threshold   *ThresholdDefinition  `yaml:"threshold"`
+	Tests       []*RuleTestDefinition `yaml:"tests"`
+	Combine     string                `yaml:"combine"`
 	Policy      *Policy
+	// OverriddenBy lists the policies overriding the rule, in order
+	OverriddenBy []*Policy `yaml:"-"`
+}
+
+// RuleTestDefinition holds the definition of a test of a rule: an event, in the
+// format of the events sent by the agent, and whether the rule should match it.
+// The path of the fixture is relative to the directory of the policy.
+type RuleTestDefinition struct {
+	Name    string                 `yaml:"name"`
+	Match   bool                   `yaml:"match"`
+	Event   map[string]interface{} `yaml:"event"`
+	Fixture string                 `yaml:"fixture"`
 }
 
 // GetTags returns the tags associated to a rule
@@ -53,10 +75,57 @@ func (rd *RuleDefinition) GetTags() []string {
 	return tags
 }
 
+// GetExpression returns the expression of the rule, or the expressions of the
+// steps of a sequence rule
+func (rd *RuleDefinition) GetExpression() string {
+	if rd.Sequence != nil {
+		expressions := make([]string, 0, len(rd.Sequence.Steps))
+		for _, step := range rd.Sequence.Steps {
+			expressions = append(expressions, step.Expression)
+		}
+		return strings.Join(expressions, " then ")
+	}
+	return rd.Expression
+}
+
+// GetSeverity returns the severity of the rule, defaulting to the severity of
+// its policy
+func (rd *RuleDefinition) GetSeverity() string {
+	if rd.Severity == "" && rd.Policy != nil {
+		return rd.Policy.Severity
+	}
+	return rd.Severity
+}
+
+// GetRateLimit returns the rate limit defined by the actions of the rule, if any
+func (rd *RuleDefinition) GetRateLimit() *RateLimitDefinition {
+	for _, action := range rd.Actions {
+		if action.RateLimit != nil {
+			return action.RateLimit
+		}
+	}
+	return nil
+}
+
+// GetContextFields returns the fields the actions of the rule add to its events
+func (rd *RuleDefinition) GetContextFields() []eval.Field {
+	var fields []eval.Field
+	for _, action := range rd.Actions {
+		fields = append(fields, action.Context...)
+	}
+	return fields
+}
+
 // Rule describes a rule of a ruleset
 type Rule struct {
 	*eval.Rule
 	Definition *RuleDefinition
+
+	// state is the state of a threshold rule or of a step of a sequence
+	state *ruleState
+	// steps are the rules of the steps of a sequence rule, which isn't
+	// evaluated itself
+	steps []*Rule
 }
 
 // RuleSetListener describes the methods implemented by an object used to be
@@ -72,7 +141,11 @@ type Opts struct {
 	SupportedDiscarders map[eval.Field]bool
 	ReservedRuleIDs     []RuleID
 	EventTypeEnabled    map[eval.EventType]bool
-	Logger              Logger
+	RuleFilters         []RuleFilter
+	// StateMaxKeys is the number of keys for which the state of each sequence
+	// and threshold rule is kept, eval.DefaultStateMaxKeys if not set
+	StateMaxKeys int
+	Logger       Logger
 }
 
 // NewOptsWithParams initializes a new Opts instance with Debug and Constants parameters
@@ -156,7 +229,7 @@ func (rs *RuleSet) AddMacro(macroDef *MacroDefinition) (*eval.Macro, error) {
 	macro := &Macro{
 		Macro: &eval.Macro{
 			ID:         macroDef.ID,
-			Expression: macroDef.Expression,
+			Expression: macroDef.GetExpression(),
 		},
 		Definition: macroDef,
 	}
@@ -203,15 +276,70 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 		return nil, &ErrRuleLoad{Definition: ruleDef, Err: ErrDefinitionIDConflict}
 	}
 
+	// an override may have combined a sequence with a threshold
+	if err := checkSequenceDefinition(ruleDef); err != nil {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
 	var tags []string
 	for k, v := range ruleDef.Tags {
 		tags = append(tags, k+":"+v)
 	}
 
+	if fields := ruleDef.GetContextFields(); len(fields) > 0 {
+		event := rs.eventCtor()
+		for _, field := range fields {
+			if _, err := event.GetFieldType(field); err != nil {
+				return nil, &ErrRuleLoad{Definition: ruleDef, Err: errors.Wrapf(err, "invalid context field `%s`", field)}
+			}
+		}
+	}
+
+	if ruleDef.Sequence != nil {
+		rule, err := rs.addSequenceRule(ruleDef, tags)
+		if err != nil {
+			return nil, err
+		}
+		rs.rules[ruleDef.ID] = rule
+		return rule.Rule, nil
+	}
+
+	rule, err := rs.compileRule(ruleDef, ruleDef.ID, ruleDef.Expression, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if threshold := ruleDef.Threshold; threshold != nil {
+		if _, err := rs.checkStateKey(threshold.Key); err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+		}
+
+		t, err := eval.NewThreshold(threshold.Count, threshold.Period, rs.getStateMaxKeys())
+		if err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+		}
+		rule.state = &ruleState{
+			rule:      rule,
+			key:       threshold.Key,
+			threshold: t,
+		}
+	}
+
+	if err := rs.addToBuckets(rule); err != nil {
+		return nil, err
+	}
+
+	rs.rules[ruleDef.ID] = rule
+
+	return rule.Rule, nil
+}
+
+// compileRule parses the expression of a rule and generates its evaluator
+func (rs *RuleSet) compileRule(ruleDef *RuleDefinition, id RuleID, expression string, tags []string) (*Rule, error) {
 	rule := &Rule{
 		Rule: &eval.Rule{
-			ID:         ruleDef.ID,
-			Expression: ruleDef.Expression,
+			ID:         id,
+			Expression: expression,
 			Tags:       tags,
 		},
 		Definition: ruleDef,
@@ -244,6 +372,11 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 		}
 	}
 
+	return rule, nil
+}
+
+// addToBuckets adds a compiled rule to the buckets of its events
+func (rs *RuleSet) addToBuckets(rule *Rule) error {
 	for _, event := range rule.GetEvaluator().EventTypes {
 		bucket, exists := rs.eventRuleBuckets[event]
 		if !exists {
@@ -252,16 +385,14 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 		}
 
 		if err := bucket.AddRule(rule); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	// Merge the fields of the new rule with the existing list of fields of the ruleset
 	rs.AddFields(rule.GetEvaluator().GetFields())
 
-	rs.rules[ruleDef.ID] = rule
-
-	return rule.Rule, nil
+	return nil
 }
 
 // NotifyRuleMatch notifies all the ruleset listeners that an event matched a rule
@@ -333,10 +464,12 @@ func (rs *RuleSet) GetEventApprovers(eventType eval.EventType, fieldCaps FieldCa
 func (rs *RuleSet) GetFieldValues(field eval.Field) []eval.FieldValue {
 	var values []eval.FieldValue
 
-	for _, rule := range rs.rules {
-		rv := rule.GetFieldValues(field)
-		if len(rv) > 0 {
-			values = append(values, rv...)
+	for _, bucket := range rs.eventRuleBuckets {
+		for _, rule := range bucket.rules {
+			rv := rule.GetFieldValues(field)
+			if len(rv) > 0 {
+				values = append(values, rv...)
+			}
 		}
 	}
 
@@ -385,8 +518,18 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 		if rule.GetEvaluator().Eval(ctx) {
 			rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 
-			rs.NotifyRuleMatch(rule, event)
+			// the events matching a step of a sequence or counted by a
+			// threshold can't be discarded
 			result = true
+
+			if rule.state != nil {
+				if !rule.state.matches(ctx, event) {
+					continue
+				}
+				rule = rule.state.rule
+			}
+
+			rs.NotifyRuleMatch(rule, event)
 		}
 	}
 
@@ -426,6 +569,11 @@ func (rs *RuleSet) GetEventTypes() []eval.EventType {
 	return eventTypes
 }
 
+// GetFields returns the fields used by the rules of the ruleset
+func (rs *RuleSet) GetFields() []eval.Field {
+	return rs.fields
+}
+
 // AddFields merges the provided set of fields with the existing set of fields of the ruleset
 func (rs *RuleSet) AddFields(fields []eval.EventType) {
 NewFields:
@@ -459,6 +607,16 @@ func (rs *RuleSet) AddPolicyVersion(filename string, version string) {
 	rs.loadedPolicies[strings.ReplaceAll(filename, ".", "_")] = version
 }
 
+// isRuleFiltered returns whether a filter of the ruleset rejects the rule
+func (rs *RuleSet) isRuleFiltered(ruleDef *RuleDefinition) bool {
+	for _, filter := range rs.opts.RuleFilters {
+		if !filter.IsRuleAccepted(ruleDef) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewRuleSet returns a new ruleset for the specified data model
 func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts) *RuleSet {
 	return &RuleSet{
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+// SequenceDefinition holds the definition of a sequence rule: the rule matches
+// when events of the same key match each step, in order, within the duration.
+//
+// Sequences and thresholds are defined next to the expressions rather than in
+// SECL: an expression is evaluated against a single event, and is compiled to
+// the approvers and discarders pushed to the kernel, which a stateful operator
+// spanning several events would defeat. Each step remains a plain SECL
+// expression, filtered in kernel like any other rule.
+type SequenceDefinition struct {
+	Key    eval.Field     `yaml:"key"`
+	Within time.Duration  `yaml:"within"`
+	Steps  []SequenceStep `yaml:"steps"`
+}
+
+// SequenceStep holds a step of a sequence, either written as a plain
+// expression, or with its own key when the events of the steps identify the
+// same entity with different fields, like `process.pid` for a file written by
+// a process and `process.ppid` for the command it then executes
+type SequenceStep struct {
+	Expression string     `yaml:"expression"`
+	Key        eval.Field `yaml:"key"`
+}
+
+// UnmarshalYAML decodes a step written as a plain expression or as a mapping
+func (s *SequenceStep) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		s.Expression = value.Value
+		return nil
+	}
+
+	type step SequenceStep
+	return value.Decode((*step)(s))
+}
+
+// keyOf returns the key of a step, defaulting to the key of the sequence
+func (seq *SequenceDefinition) keyOf(step int) eval.Field {
+	if key := seq.Steps[step].Key; key != "" {
+		return key
+	}
+	return seq.Key
+}
+
+// ThresholdDefinition holds the definition of the threshold of a rule: the rule
+// matches when its expression matches count events of the same key during the
+// period
+type ThresholdDefinition struct {
+	Key    eval.Field    `yaml:"key"`
+	Count  int           `yaml:"count"`
+	Period time.Duration `yaml:"period"`
+}
+
+// checkSequenceDefinition returns an error if the sequence or the threshold of
+// the rule are invalid
+func checkSequenceDefinition(ruleDef *RuleDefinition) error {
+	if seq := ruleDef.Sequence; seq != nil {
+		switch {
+		case ruleDef.Expression != "":
+			return errors.New("both an expression and a sequence defined")
+		case ruleDef.Threshold != nil:
+			return errors.New("both a sequence and a threshold defined")
+		case len(seq.Steps) < 2:
+			return errors.New("a sequence requires at least two steps")
+		case seq.Within < 0:
+			return errors.New("the duration of a sequence can't be negative")
+		}
+		for i, step := range seq.Steps {
+			if step.Expression == "" {
+				return fmt.Errorf("no expression defined for the step #%d", i+1)
+			}
+			if (seq.keyOf(i) == "") != (seq.keyOf(0) == "") {
+				return fmt.Errorf("no key defined for the step #%d while other steps have one", i+1)
+			}
+		}
+	}
+
+	if threshold := ruleDef.Threshold; threshold != nil {
+		if threshold.Count < 1 || threshold.Period <= 0 {
+			return errors.New("the count and the period of a threshold must be positive")
+		}
+	}
+
+	return nil
+}
+
+// timestampedEvent is implemented by the events that know when they occurred
+type timestampedEvent interface {
+	GetTimestamp() time.Time
+}
+
+// eventTime returns the date of the event, so that the durations of the
+// sequences and thresholds don't depend on when the events are evaluated. The
+// current time is used for the events without a date.
+func eventTime(ctx *eval.Context, event eval.Event) time.Time {
+	if e, ok := event.(timestampedEvent); ok {
+		if t := e.GetTimestamp(); !t.IsZero() {
+			return t
+		}
+	}
+	return ctx.Now()
+}
+
+// ruleState holds the state shared by the rules evaluating a sequence or a
+// threshold. The rules of the steps of a sequence notify the rule of the
+// sequence.
+type ruleState struct {
+	rule      *Rule
+	key       eval.Field
+	step      int
+	sequence  *eval.Sequence
+	threshold *eval.Threshold
+}
+
+// matches updates the state with an event matching the rule and returns whether
+// the sequence was completed or the threshold reached
+func (s *ruleState) matches(ctx *eval.Context, event eval.Event) bool {
+	var key string
+	if s.key != "" {
+		value, err := event.GetFieldValue(s.key)
+		if err != nil {
+			return false
+		}
+		key = fmt.Sprint(value)
+	}
+
+	now := eventTime(ctx, event)
+	if s.sequence != nil {
+		return s.sequence.Match(key, s.step, now)
+	}
+	return s.threshold.Match(key, now)
+}
+
+// reset drops the state
+func (s *ruleState) reset() {
+	if s.sequence != nil {
+		s.sequence.Reset()
+	} else {
+		s.threshold.Reset()
+	}
+}
+
+// checkStateKey returns the type of the key, or an error if the key isn't a
+// field of the events
+func (rs *RuleSet) checkStateKey(key eval.Field) (reflect.Kind, error) {
+	if key == "" {
+		return reflect.Invalid, nil
+	}
+	kind, err := rs.eventCtor().GetFieldType(key)
+	if err != nil {
+		return reflect.Invalid, errors.Wrapf(err, "invalid key `%s`", key)
+	}
+	return kind, nil
+}
+
+// addSequenceRule compiles the steps of a sequence rule. The steps are added to
+// the buckets in reverse order so that an event can't match two consecutive
+// steps.
+func (rs *RuleSet) addSequenceRule(ruleDef *RuleDefinition, tags []string) (*Rule, error) {
+	seq := ruleDef.Sequence
+
+	// the keys of the steps are compared with each other
+	var keyKind reflect.Kind
+	for i := range seq.Steps {
+		kind, err := rs.checkStateKey(seq.keyOf(i))
+		if err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: errors.Wrapf(err, "step #%d", i+1)}
+		}
+		if i > 0 && kind != keyKind {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: fmt.Errorf("the key of the step #%d doesn't have the type of the key of the first step", i+1)}
+		}
+		keyKind = kind
+	}
+
+	sequence, err := eval.NewSequence(len(seq.Steps), seq.Within, rs.getStateMaxKeys())
+	if err != nil {
+		return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+	}
+
+	rule := &Rule{
+		Rule: &eval.Rule{
+			ID:         ruleDef.ID,
+			Expression: ruleDef.GetExpression(),
+			Tags:       tags,
+		},
+		Definition: ruleDef,
+	}
+
+	for i, seqStep := range seq.Steps {
+		step, err := rs.compileRule(ruleDef, fmt.Sprintf("%s[%d]", ruleDef.ID, i), seqStep.Expression, tags)
+		if err != nil {
+			return nil, errors.Wrapf(err, "step #%d", i+1)
+		}
+		step.state = &ruleState{
+			rule:     rule,
+			key:      seq.keyOf(i),
+			step:     i,
+			sequence: sequence,
+		}
+		rule.steps = append(rule.steps, step)
+	}
+
+	for i := len(rule.steps) - 1; i >= 0; i-- {
+		if err := rs.addToBuckets(rule.steps[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+// getStateMaxKeys returns the number of keys for which the state of a sequence
+// or a threshold is kept
+func (rs *RuleSet) getStateMaxKeys() int {
+	if rs.opts.StateMaxKeys > 0 {
+		return rs.opts.StateMaxKeys
+	}
+	return eval.DefaultStateMaxKeys
+}
+
+// ResetState drops the state of the sequence and threshold rules
+func (rs *RuleSet) ResetState() {
+	for _, rule := range rs.rules {
+		if rule.state != nil {
+			rule.state.reset()
+		}
+		if len(rule.steps) > 0 {
+			rule.steps[0].state.reset()
+		}
+	}
+}
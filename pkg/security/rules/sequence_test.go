@@ -0,0 +1,234 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/eval"
+)
+
+const testSequencePolicy = `---
+rules:
+  - id: shadow_then_tmp
+    sequence:
+      key: process.name
+      within: 10s
+      steps:
+        - open.filename == "/etc/shadow"
+        - mkdir.filename == "/tmp/stage"
+        - open.filename == "/etc/shadow"
+  - id: hosts_burst
+    expression: open.filename == "/etc/hosts"
+    threshold:
+      key: process.uid
+      count: 3
+      period: 1m
+  - id: single_step
+    sequence:
+      steps:
+        - open.filename == "/etc/group"
+  - id: sequence_and_expression
+    expression: open.filename == "/etc/group"
+    sequence:
+      steps:
+        - open.filename == "/etc/group"
+        - open.filename == "/etc/group"
+  - id: invalid_threshold
+    expression: open.filename == "/etc/group"
+    threshold:
+      count: 0
+      period: 1m
+`
+
+func newTestSequenceRuleSet(t *testing.T) *RuleSet {
+	policy := loadTestPolicy(t, testSequencePolicy)
+
+	_, ruleDefs, merr := policy.GetValidMacroAndRules()
+	if merr == nil || len(merr.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %v", merr)
+	}
+	for i, id := range []string{"single_step", "sequence_and_expression", "invalid_threshold"} {
+		if err, ok := merr.Errors[i].(*ErrRuleLoad); !ok || err.Definition.ID != id {
+			t.Errorf("unexpected error %d: %s", i, merr.Errors[i])
+		}
+	}
+
+	if len(ruleDefs) != 2 || ruleDefs[0].Sequence.Within != 10*time.Second || ruleDefs[1].Threshold.Period != time.Minute {
+		t.Fatalf("unexpected rules: %+v", ruleDefs)
+	}
+
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil)
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, opts)
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	return rs
+}
+
+func matchesRule(report *EvaluationReport, id RuleID) bool {
+	for _, rule := range report.Rules {
+		if rule.ID == id && rule.Match {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSequenceRule(t *testing.T) {
+	rs := newTestSequenceRuleSet(t)
+
+	if rule := rs.GetRules()["shadow_then_tmp"]; rule == nil || len(rule.steps) != 3 {
+		t.Fatalf("the sequence rule should be loaded with its steps: %v", rs.ListRuleIDs())
+	}
+
+	shadow := &testEvent{kind: "open", process: testProcess{name: "cat"}, open: testOpen{filename: "/etc/shadow"}}
+	stage := &testEvent{kind: "mkdir", process: testProcess{name: "cat"}, mkdir: testMkdir{filename: "/tmp/stage"}}
+	otherStage := &testEvent{kind: "mkdir", process: testProcess{name: "vim"}, mkdir: testMkdir{filename: "/tmp/stage"}}
+
+	// the events of the steps can't be discarded
+	report := rs.EvaluateWithReport(shadow)
+	if matchesRule(report, "shadow_then_tmp") || len(report.Discarders) != 0 {
+		t.Errorf("the first step shouldn't match nor be discarded: %+v", report)
+	}
+	if len(report.Rules) != 3 || report.Rules[1].Step != 1 || report.Rules[2].Step != 3 {
+		t.Errorf("unexpected evaluations: %+v", report.Rules)
+	}
+
+	// an event of the first and the last step doesn't complete both steps
+	if matchesRule(rs.EvaluateWithReport(shadow), "shadow_then_tmp") {
+		t.Error("the sequence shouldn't be completed yet")
+	}
+
+	// the sequences are tracked per process name
+	if matchesRule(rs.EvaluateWithReport(otherStage), "shadow_then_tmp") {
+		t.Error("the sequence shouldn't be completed yet")
+	}
+	if matchesRule(rs.EvaluateWithReport(stage), "shadow_then_tmp") {
+		t.Error("the sequence shouldn't be completed yet")
+	}
+	if !matchesRule(rs.EvaluateWithReport(shadow), "shadow_then_tmp") {
+		t.Error("the sequence should be completed")
+	}
+
+	rs.EvaluateWithReport(shadow)
+	rs.EvaluateWithReport(stage)
+	rs.ResetState()
+	if matchesRule(rs.EvaluateWithReport(shadow), "shadow_then_tmp") {
+		t.Error("the state should have been reset")
+	}
+}
+
+const testStepKeysPolicy = `---
+rules:
+  - id: shadow_then_shell
+    sequence:
+      within: 10s
+      steps:
+        - expression: open.filename == "/etc/shadow" && open.flags & O_WRONLY > 0
+          key: process.pid
+        - expression: exec.filename == "/bin/sh"
+          key: process.ppid
+  - id: missing_step_key
+    sequence:
+      steps:
+        - expression: open.filename == "/etc/shadow"
+          key: process.pid
+        - exec.filename == "/bin/sh"
+`
+
+func TestSequenceRuleStepKeys(t *testing.T) {
+	policy := loadTestPolicy(t, testStepKeysPolicy)
+
+	_, ruleDefs, merr := policy.GetValidMacroAndRules()
+	if merr == nil || len(merr.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", merr)
+	}
+	if err, ok := merr.Errors[0].(*ErrRuleLoad); !ok || err.Definition.ID != "missing_step_key" {
+		t.Errorf("unexpected error: %s", merr.Errors[0])
+	}
+
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil)
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, opts)
+	if err := rs.AddRules(ruleDefs); err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	// a process writes /etc/shadow then spawns a shell
+	writeShadow := &testEvent{kind: "open", process: testProcess{name: "python", pid: 42, ppid: 1}, open: testOpen{filename: "/etc/shadow", flags: syscall.O_WRONLY}}
+	otherShell := &testEvent{kind: "exec", process: testProcess{name: "sh", pid: 44, ppid: 43}, exec: testExec{filename: "/bin/sh"}}
+	childShell := &testEvent{kind: "exec", process: testProcess{name: "sh", pid: 45, ppid: 42}, exec: testExec{filename: "/bin/sh"}}
+
+	if matchesRule(rs.EvaluateWithReport(writeShadow), "shadow_then_shell") {
+		t.Error("the sequence shouldn't be completed yet")
+	}
+	if matchesRule(rs.EvaluateWithReport(otherShell), "shadow_then_shell") {
+		t.Error("a shell spawned by another process shouldn't complete the sequence")
+	}
+	if !matchesRule(rs.EvaluateWithReport(childShell), "shadow_then_shell") {
+		t.Error("a shell spawned by the process should complete the sequence")
+	}
+}
+
+func TestThresholdRule(t *testing.T) {
+	rs := newTestSequenceRuleSet(t)
+
+	root := &testEvent{kind: "open", process: testProcess{uid: 0}, open: testOpen{filename: "/etc/hosts"}}
+	user := &testEvent{kind: "open", process: testProcess{uid: 1000}, open: testOpen{filename: "/etc/hosts"}}
+
+	for i, event := range []*testEvent{root, user, root, user, root, root} {
+		matched := matchesRule(rs.EvaluateWithReport(event), "hosts_burst")
+		if expected := i == 4; matched != expected {
+			t.Errorf("event %d: expected %v, got %v", i, expected, matched)
+		}
+	}
+}
+
+func TestSequenceRuleInvalidKey(t *testing.T) {
+	opts := NewOptsWithParams(testConstants, testSupportedDiscarders, map[eval.EventType]bool{"*": true}, nil, nil)
+	rs := NewRuleSet(&testModel{}, func() eval.Event { return &testEvent{} }, opts)
+
+	ruleDef := &RuleDefinition{
+		ID: "invalid_key",
+		Sequence: &SequenceDefinition{
+			Key:   "process.unknown",
+			Steps: []SequenceStep{{Expression: `open.filename == "/etc/shadow"`}, {Expression: `mkdir.filename == "/tmp/stage"`}},
+		},
+	}
+	if _, err := rs.AddRule(ruleDef); err == nil {
+		t.Error("the key should be invalid")
+	}
+
+	ruleDef = &RuleDefinition{
+		ID: "invalid_step",
+		Sequence: &SequenceDefinition{
+			Steps: []SequenceStep{{Expression: `open.filename == "/etc/shadow"`}, {Expression: `mkdir.filename ==`}},
+		},
+	}
+	if _, err := rs.AddRule(ruleDef); err == nil {
+		t.Error("the step should be invalid")
+	}
+
+	ruleDef = &RuleDefinition{
+		ID: "mismatching_keys",
+		Sequence: &SequenceDefinition{
+			Steps: []SequenceStep{
+				{Expression: `open.filename == "/etc/shadow"`, Key: "process.name"},
+				{Expression: `exec.filename == "/bin/sh"`, Key: "process.ppid"},
+			},
+		},
+	}
+	if _, err := rs.AddRule(ruleDef); err == nil {
+		t.Error("the keys of the steps should have the same type")
+	}
+
+	if len(rs.GetEventTypes()) != 0 {
+		t.Errorf("no step should have been added: %v", rs.GetEventTypes())
+	}
+}
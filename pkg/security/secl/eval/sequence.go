@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/pkg/errors"
+)
+
+// DefaultStateMaxKeys is the default number of keys, like processes or
+// containers, for which the state of a sequence or a threshold is kept
+const DefaultStateMaxKeys = 4096
+
+// Sequence tracks, per key, the progress of the events through ordered steps.
+// The least recently updated keys are evicted when the maximum number of keys
+// is reached.
+type Sequence struct {
+	steps  int
+	within time.Duration
+	states *simplelru.LRU
+}
+
+type sequenceState struct {
+	next  int
+	start time.Time
+}
+
+// NewSequence returns a sequence of the given number of steps, that must all
+// be matched within the given duration, if not zero
+func NewSequence(steps int, within time.Duration, maxKeys int) (*Sequence, error) {
+	if steps < 1 {
+		return nil, errors.New("a sequence requires at least one step")
+	}
+
+	states, err := simplelru.NewLRU(maxKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sequence{
+		steps:  steps,
+		within: within,
+		states: states,
+	}, nil
+}
+
+// Match records that an event of the key matched the given step, and returns
+// whether it completed the sequence. The first step always (re)starts the
+// sequence of the key, while the other steps are only taken into account when
+// they are the next expected step.
+func (s *Sequence) Match(key string, step int, now time.Time) bool {
+	if step == 0 {
+		if s.steps == 1 {
+			return true
+		}
+		s.states.Add(key, &sequenceState{next: 1, start: now})
+		return false
+	}
+
+	entry, exists := s.states.Get(key)
+	if !exists {
+		return false
+	}
+
+	state := entry.(*sequenceState)
+	if state.next != step {
+		return false
+	}
+
+	if s.within > 0 && now.Sub(state.start) > s.within {
+		s.states.Remove(key)
+		return false
+	}
+
+	if step == s.steps-1 {
+		s.states.Remove(key)
+		return true
+	}
+	state.next++
+
+	return false
+}
+
+// Len returns the number of keys with a sequence in progress
+func (s *Sequence) Len() int {
+	return s.states.Len()
+}
+
+// Reset drops the progress of every key
+func (s *Sequence) Reset() {
+	s.states.Purge()
+}
+
+// Threshold counts, per key, the events matched during a sliding period. The
+// least recently updated keys are evicted when the maximum number of keys is
+// reached.
+type Threshold struct {
+	count  int
+	period time.Duration
+	states *simplelru.LRU
+}
+
+// NewThreshold returns a threshold reached when count events of a key are
+// matched within the given period
+func NewThreshold(count int, period time.Duration, maxKeys int) (*Threshold, error) {
+	if count < 1 {
+		return nil, errors.New("the count of a threshold must be positive")
+	}
+	if period <= 0 {
+		return nil, errors.New("the period of a threshold must be positive")
+	}
+
+	states, err := simplelru.NewLRU(maxKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Threshold{
+		count:  count,
+		period: period,
+		states: states,
+	}, nil
+}
+
+// Match records an event of the key and returns whether the threshold was
+// reached. The count of the key starts over once the threshold is reached.
+func (t *Threshold) Match(key string, now time.Time) bool {
+	var times []time.Time
+	if entry, exists := t.states.Get(key); exists {
+		times = entry.([]time.Time)
+	}
+
+	// drop the events that are out of the period
+	first := 0
+	for first < len(times) && now.Sub(times[first]) > t.period {
+		first++
+	}
+	times = append(times[first:], now)
+
+	if len(times) >= t.count {
+		t.states.Remove(key)
+		return true
+	}
+	t.states.Add(key, times)
+
+	return false
+}
+
+// Len returns the number of keys with events in the current period
+func (t *Threshold) Len() int {
+	return t.states.Len()
+}
+
+// Reset drops the events of every key
+func (t *Threshold) Reset() {
+	t.states.Purge()
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package eval
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSequence(t *testing.T) {
+	seq, err := NewSequence(3, 10*time.Second, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	// steps out of order are ignored
+	if seq.Match("a", 1, at(0)) || seq.Match("a", 2, at(0)) {
+		t.Error("a sequence can't start with its last steps")
+	}
+
+	if seq.Match("a", 0, at(0)) || seq.Match("a", 2, at(1)) || seq.Match("a", 1, at(2)) {
+		t.Error("the sequence shouldn't be completed yet")
+	}
+	if seq.Match("b", 2, at(3)) {
+		t.Error("the sequences are tracked per key")
+	}
+	if !seq.Match("a", 2, at(3)) {
+		t.Error("the sequence should be completed")
+	}
+	if seq.Match("a", 2, at(4)) {
+		t.Error("a completed sequence should start over")
+	}
+
+	// too slow
+	seq.Match("a", 0, at(10))
+	seq.Match("a", 1, at(15))
+	if seq.Match("a", 2, at(21)) {
+		t.Error("the sequence should have expired")
+	}
+
+	// the first step restarts the sequence
+	seq.Match("a", 0, at(30))
+	seq.Match("a", 0, at(35))
+	seq.Match("a", 1, at(40))
+	if !seq.Match("a", 2, at(44)) {
+		t.Error("the sequence should have been restarted")
+	}
+
+	// the least recently used keys are evicted
+	for _, key := range []string{"a", "b", "c"} {
+		seq.Match(key, 0, at(50))
+	}
+	if seq.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", seq.Len())
+	}
+	if seq.Match("a", 1, at(51)) {
+		t.Error("the key should have been evicted")
+	}
+
+	seq.Reset()
+	if seq.Len() != 0 {
+		t.Error("the sequence should have been reset")
+	}
+
+	if _, err := NewSequence(0, 0, 2); err == nil {
+		t.Error("a sequence without step should be invalid")
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	threshold, err := NewThreshold(3, time.Minute, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	if threshold.Match("a", at(0)) || threshold.Match("a", at(10)) || threshold.Match("b", at(20)) {
+		t.Error("the threshold shouldn't be reached yet")
+	}
+	if !threshold.Match("a", at(30)) {
+		t.Error("the threshold should be reached")
+	}
+	if threshold.Match("a", at(31)) {
+		t.Error("the count should start over")
+	}
+
+	// sliding period
+	threshold.Reset()
+	threshold.Match("a", at(0))
+	threshold.Match("a", at(50))
+	if threshold.Match("a", at(70)) {
+		t.Error("the first event should be out of the period")
+	}
+	if !threshold.Match("a", at(80)) {
+		t.Error("the threshold should be reached")
+	}
+
+	threshold.Match("a", at(100))
+	threshold.Match("b", at(100))
+	threshold.Match("c", at(100))
+	if threshold.Len() != 2 {
+		t.Errorf("expected 2 keys, got %d", threshold.Len())
+	}
+
+	if _, err := NewThreshold(1, 0, 2); err == nil {
+		t.Error("a threshold without period should be invalid")
+	}
+}
@@ -50,6 +50,7 @@ type Module struct {
 	TargetPkg       string
 	BuildTags       []string
 	Fields          map[string]*structField
+	MapFields       map[string]*structField
 	Iterators       map[string]*structField
 	EventTypes      map[string]bool
 	Mock            bool
@@ -140,19 +141,26 @@ func handleField(astFile *ast.File, name, alias, prefix, aliasPrefix, pkgName st
 	return nil
 }
 
-func getFieldIdent(field *ast.Field) (ident *ast.Ident, isPointer, isArray bool) {
+func getFieldIdent(field *ast.Field) (ident *ast.Ident, isPointer, isArray, isMap bool) {
 	if fieldType, ok := field.Type.(*ast.Ident); ok {
-		return fieldType, false, false
+		return fieldType, false, false, false
 	} else if fieldType, ok := field.Type.(*ast.StarExpr); ok {
 		if ident, ok := fieldType.X.(*ast.Ident); ok {
-			return ident, true, false
+			return ident, true, false, false
 		}
 	} else if ft, ok := field.Type.(*ast.ArrayType); ok {
 		if ident, ok := ft.Elt.(*ast.Ident); ok {
-			return ident, false, true
+			return ident, false, true, false
+		}
+	} else if ft, ok := field.Type.(*ast.MapType); ok {
+		if key, ok := ft.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return nil, false, false, false
+		}
+		if ident, ok := ft.Value.(*ast.Ident); ok {
+			return ident, false, false, true
 		}
 	}
-	return nil, false, false
+	return nil, false, false, false
 }
 
 type seclField struct {
@@ -203,7 +211,7 @@ func handleSpec(astFile *ast.File, spec interface{}, prefix, aliasPrefix, event
 					}
 
 					var fields []seclField
-					fieldType, isPointer, isArray := getFieldIdent(field)
+					fieldType, isPointer, isArray, isMap := getFieldIdent(field)
 
 					var weight int64
 					if tags, err := structtag.Parse(string(tag)); err == nil && len(tags.Tags()) != 0 {
@@ -259,6 +267,30 @@ func handleSpec(astFile *ast.File, spec interface{}, prefix, aliasPrefix, event
 							fieldIterator = module.Iterators[alias]
 						}
 
+						// map fields are accessed with a wildcard, the key being the
+						// last part of the field, such as `kube.pod.labels.app`
+						if isMap {
+							if fieldType.Name != "string" {
+								log.Panicf("Unsupported map value type %s for %s", fieldType.Name, fieldName)
+							}
+
+							module.MapFields[alias] = &structField{
+								Prefix:     prefix,
+								Name:       fmt.Sprintf("%s.%s", prefix, fieldName),
+								BasicType:  fieldType.Name,
+								Struct:     typeSpec.Name.Name,
+								Handler:    seclField.handler,
+								ReturnType: fieldType.Name,
+								Event:      event,
+								OrigType:   fieldType.Name,
+								Weight:     weight,
+							}
+
+							module.EventTypes[event] = true
+
+							continue
+						}
+
 						if handler := seclField.handler; handler != "" {
 							if aliasPrefix != "" {
 								fieldAlias = aliasPrefix + "." + fieldAlias
@@ -379,6 +411,7 @@ func parseFile(filename string, pkgName string) (*Module, error) {
 		TargetPkg:  pkgName,
 		BuildTags:  buildTags,
 		Fields:     make(map[string]*structField),
+		MapFields:  make(map[string]*structField),
 		Iterators:  make(map[string]*structField),
 		EventTypes: make(map[string]bool),
 		Mock:       mock,
@@ -429,6 +462,7 @@ package {{.Name}}
 
 import (
 	"reflect"
+	{{if .MapFields}}"strings"{{end}}
 	"unsafe"
 
 	{{if ne $.SourcePkg $.TargetPkg}}"{{.SourcePkg}}"{{end}}
@@ -579,6 +613,29 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 	{{end}}
 	}
 
+	{{range $Name, $Field := .MapFields}}
+	if strings.HasPrefix(field, "{{$Name}}.") {
+		key := strings.TrimPrefix(field, "{{$Name}}.")
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				{{- if and (ne $Field.Handler "") (not $Mock)}}
+					return (*Event)(ctx.Object).{{$Field.Handler}}(&(*Event)(ctx.Object).{{$Field.Prefix}}, key)
+				{{- else}}
+					return (*Event)(ctx.Object).{{$Field.Name}}[key]
+				{{- end}}
+			},
+			Field: field,
+			{{- if and $Field.Handler (gt $Field.Weight 0)}}
+				Weight: {{$Field.Weight}},
+			{{else if $Field.Handler}}
+				Weight: eval.HandlerWeight,
+			{{else}}
+				Weight: eval.FunctionWeight,
+			{{end}}
+		}, nil
+	}
+	{{end}}
+
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -665,6 +722,17 @@ func (e *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		{{end}}
 		}
 
+		{{range $Name, $Field := .MapFields}}
+		if strings.HasPrefix(field, "{{$Name}}.") {
+			key := strings.TrimPrefix(field, "{{$Name}}.")
+			{{- if and (ne $Field.Handler "") (not $Mock)}}
+				return e.{{$Field.Handler}}(&e.{{$Field.Prefix}}, key), nil
+			{{- else}}
+				return e.{{$Field.Name}}[key], nil
+			{{- end}}
+		}
+		{{end}}
+
 		return nil, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -676,6 +744,12 @@ func (e *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	{{end}}
 	}
 
+	{{range $Name, $Field := .MapFields}}
+	if strings.HasPrefix(field, "{{$Name}}.") {
+		return "{{$Field.Event}}", nil
+	}
+	{{end}}
+
 	return "", &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -694,6 +768,12 @@ func (e *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		{{end}}
 		}
 
+		{{range $Name, $Field := .MapFields}}
+		if strings.HasPrefix(field, "{{$Name}}.") {
+			return reflect.String, nil
+		}
+		{{end}}
+
 		return reflect.Invalid, &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -737,6 +817,20 @@ func (e *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		{{end}}
 		}
 
+		{{range $Name, $Field := .MapFields}}
+		if strings.HasPrefix(field, "{{$Name}}.") {
+			str, ok := value.(string)
+			if !ok {
+				return &eval.ErrValueTypeMismatch{Field: "{{$Field.Name}}"}
+			}
+			if e.{{$Field.Name}} == nil {
+				e.{{$Field.Name}} = make(map[string]{{$Field.OrigType}})
+			}
+			e.{{$Field.Name}}[strings.TrimPrefix(field, "{{$Name}}.")] = str
+			return nil
+		}
+		{{end}}
+
 		return &eval.ErrFieldNotFound{Field: field}
 }
 
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// ruleSourcePrefix prefixes the source of the tags added or removed by a rule
+	ruleSourcePrefix = "rule:"
+)
+
+// TagRuleConfig is the configuration of a tagger rule, from the tagger_rules option
+type TagRuleConfig struct {
+	Name        string            `mapstructure:"name" json:"name"`
+	EntityType  string            `mapstructure:"entity_type" json:"entity_type"`
+	Match       map[string]string `mapstructure:"match" json:"match"`
+	AddTags     []string          `mapstructure:"add_tags" json:"add_tags"`
+	RemoveTags  []string          `mapstructure:"remove_tags" json:"remove_tags"`
+	Cardinality string            `mapstructure:"cardinality" json:"cardinality"`
+}
+
+// TagRule adds or removes tags on the entities matching all its conditions.
+// A condition matches if the entity has a tag with the given name, and a value
+// matching the pattern, where `*` matches any characters.
+type TagRule struct {
+	Name        string
+	Source      string
+	Cardinality TagCardinality
+	AddTags     []string
+	RemoveTags  []string
+
+	entityType string
+	conditions map[string]*regexp.Regexp
+}
+
+// LoadTagRules returns the rules of the tagger_rules option. Invalid rules are
+// logged and skipped.
+func LoadTagRules() []*TagRule {
+	var configs []TagRuleConfig
+	if err := config.Datadog.UnmarshalKey("tagger_rules", &configs); err != nil {
+		log.Errorf("Could not parse tagger_rules: %s", err)
+		return nil
+	}
+
+	var rules []*TagRule
+	names := make(map[string]struct{})
+	for i, cfg := range configs {
+		rule, err := NewTagRule(cfg)
+		if err != nil {
+			log.Errorf("Ignoring tagger rule #%d: %s", i+1, err)
+			continue
+		}
+		if _, found := names[rule.Name]; found {
+			log.Errorf("Ignoring tagger rule #%d: duplicate name %s", i+1, rule.Name)
+			continue
+		}
+		names[rule.Name] = struct{}{}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// NewTagRule validates a rule configuration and returns the rule
+func NewTagRule(cfg TagRuleConfig) (*TagRule, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+	if len(cfg.Match) == 0 && cfg.EntityType == "" {
+		return nil, fmt.Errorf("rule %s: missing match conditions", cfg.Name)
+	}
+	if len(cfg.AddTags) == 0 && len(cfg.RemoveTags) == 0 {
+		return nil, fmt.Errorf("rule %s: no tag to add or remove", cfg.Name)
+	}
+	for _, tag := range cfg.AddTags {
+		if !strings.Contains(tag, ":") {
+			return nil, fmt.Errorf("rule %s: invalid tag %q, expected <name>:<value>", cfg.Name, tag)
+		}
+	}
+
+	rule := &TagRule{
+		Name:        cfg.Name,
+		Source:      ruleSourcePrefix + cfg.Name,
+		AddTags:     cfg.AddTags,
+		RemoveTags:  cfg.RemoveTags,
+		entityType:  cfg.EntityType,
+		conditions:  make(map[string]*regexp.Regexp, len(cfg.Match)),
+		Cardinality: LowCardinality,
+	}
+
+	switch strings.ToLower(cfg.Cardinality) {
+	case "", "low":
+	case "orchestrator":
+		rule.Cardinality = OrchestratorCardinality
+	case "high":
+		rule.Cardinality = HighCardinality
+	default:
+		return nil, fmt.Errorf("rule %s: invalid cardinality %q", cfg.Name, cfg.Cardinality)
+	}
+
+	for name, pattern := range cfg.Match {
+		rule.conditions[name] = globToRegexp(pattern)
+	}
+
+	return rule, nil
+}
+
+// globToRegexp compiles a pattern where `*` matches any characters
+func globToRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// Matches returns whether the entity, with the given tags, matches the rule
+func (r *TagRule) Matches(entity string, tags []string) bool {
+	if r.entityType != "" {
+		prefix, _ := containers.SplitEntityName(entity)
+		if prefix != r.entityType {
+			return false
+		}
+	}
+
+	for name, pattern := range r.conditions {
+		matched := false
+		for _, tag := range tags {
+			parts := strings.SplitN(tag, ":", 2)
+			if len(parts) == 2 && parts[0] == name && pattern.MatchString(parts[1]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// TagInfo returns the tags the rule adds to and removes from the entity
+func (r *TagRule) TagInfo(entity string) *TagInfo {
+	info := &TagInfo{
+		Source:      r.Source,
+		Entity:      entity,
+		RemovedTags: r.RemoveTags,
+	}
+
+	tags := append([]string(nil), r.AddTags...)
+	switch r.Cardinality {
+	case HighCardinality:
+		info.HighCardTags = tags
+	case OrchestratorCardinality:
+		info.OrchestratorCardTags = tags
+	default:
+		info.LowCardTags = tags
+	}
+	return info
+}
+
+// IsRemoved returns whether the tag is in the removed tags, either with its
+// name, to remove all the tags with this name, or as a full tag
+func IsRemoved(tag string, removedTags []string) bool {
+	name := strings.SplitN(tag, ":", 2)[0]
+	for _, removed := range removedTags {
+		if removed == tag || removed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRuleSource returns whether the tags of the source were added by a rule
+func IsRuleSource(source string) bool {
+	return strings.HasPrefix(source, ruleSourcePrefix)
+}
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestTagRuleMatches(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		cfg     TagRuleConfig
+		entity  string
+		tags    []string
+		matches bool
+	}{
+		{
+			desc:    "glob on image",
+			cfg:     TagRuleConfig{Match: map[string]string{"docker_image": "nginx:*"}},
+			entity:  "container_id://abc",
+			tags:    []string{"docker_image:nginx:1.19", "image_name:nginx"},
+			matches: true,
+		},
+		{
+			desc:    "glob doesn't match",
+			cfg:     TagRuleConfig{Match: map[string]string{"docker_image": "nginx:*"}},
+			entity:  "container_id://abc",
+			tags:    []string{"docker_image:library/nginx:1.19"},
+			matches: false,
+		},
+		{
+			desc:    "all conditions must match",
+			cfg:     TagRuleConfig{Match: map[string]string{"kube_namespace": "billing", "env": "prod"}},
+			entity:  "kubernetes_pod_uid://abc",
+			tags:    []string{"kube_namespace:billing", "env:staging"},
+			matches: false,
+		},
+		{
+			desc:    "entity type",
+			cfg:     TagRuleConfig{EntityType: "kubernetes_pod_uid", Match: map[string]string{"kube_namespace": "billing"}},
+			entity:  "container_id://abc",
+			tags:    []string{"kube_namespace:billing"},
+			matches: false,
+		},
+		{
+			desc:    "entity type only",
+			cfg:     TagRuleConfig{EntityType: "kubernetes_pod_uid"},
+			entity:  "kubernetes_pod_uid://abc",
+			matches: true,
+		},
+		{
+			desc:    "special characters are literal",
+			cfg:     TagRuleConfig{Match: map[string]string{"short_image": "app.v?"}},
+			entity:  "container_id://abc",
+			tags:    []string{"short_image:app-v1"},
+			matches: false,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			tt.cfg.Name = "test"
+			tt.cfg.AddTags = []string{"team:web"}
+			rule, err := NewTagRule(tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, rule.Matches(tt.entity, tt.tags))
+		})
+	}
+}
+
+func TestNewTagRuleErrors(t *testing.T) {
+	for _, cfg := range []TagRuleConfig{
+		{Match: map[string]string{"a": "b"}, AddTags: []string{"team:web"}},
+		{Name: "no conditions", AddTags: []string{"team:web"}},
+		{Name: "no tags", Match: map[string]string{"a": "b"}},
+		{Name: "invalid tag", Match: map[string]string{"a": "b"}, AddTags: []string{"team"}},
+		{Name: "invalid cardinality", Match: map[string]string{"a": "b"}, AddTags: []string{"team:web"}, Cardinality: "medium"},
+	} {
+		_, err := NewTagRule(cfg)
+		assert.Error(t, err, cfg.Name)
+	}
+}
+
+func TestTagRuleTagInfo(t *testing.T) {
+	rule, err := NewTagRule(TagRuleConfig{
+		Name:        "web",
+		Match:       map[string]string{"image_name": "nginx"},
+		AddTags:     []string{"team:web"},
+		RemoveTags:  []string{"team"},
+		Cardinality: "high",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &TagInfo{
+		Source:       "rule:web",
+		Entity:       "container_id://abc",
+		HighCardTags: []string{"team:web"},
+		RemovedTags:  []string{"team"},
+	}, rule.TagInfo("container_id://abc"))
+
+	assert.True(t, IsRemoved("team:api", rule.RemoveTags))
+	assert.False(t, IsRemoved("teams:api", rule.RemoveTags))
+	assert.True(t, IsRemoved("env:prod", []string{"env:prod"}))
+	assert.False(t, IsRemoved("env:staging", []string{"env:prod"}))
+}
+
+func TestLoadTagRules(t *testing.T) {
+	config.Datadog.Set("tagger_rules", []map[string]interface{}{
+		{"name": "web", "match": map[string]interface{}{"image_name": "nginx"}, "add_tags": []string{"team:web"}},
+		{"name": "web", "match": map[string]interface{}{"image_name": "redis"}, "add_tags": []string{"team:cache"}},
+		{"name": "invalid", "add_tags": []string{"team:web"}},
+	})
+	defer config.Datadog.Set("tagger_rules", nil)
+
+	rules := LoadTagRules()
+	require.Len(t, rules, 1)
+	assert.Equal(t, "rule:web", rules[0].Source)
+	assert.Equal(t, []string{"team:web"}, rules[0].AddTags)
+}
@@ -19,6 +19,7 @@ type TagInfo struct {
 	OrchestratorCardTags []string  // orchestrator cardinality tags that have as many combination as pods/tasks
 	LowCardTags          []string  // low cardinality tags safe for every pipeline
 	StandardTags         []string  // the discovered standard tags (env, version, service) for the entity
+	RemovedTags          []string  // tag names, or full tags, removed from the tags of the other sources by a tagger rule
 	DeleteEntity         bool      // true if the entity is to be deleted from the store
 	CacheMiss            bool      // true if the TagInfo is generated by a tag miss
 	ExpiryDate           time.Time // keep in cache until expiryDate
@@ -49,6 +50,7 @@ const (
 	NodeRuntime CollectorPriority = iota
 	NodeOrchestrator
 	ClusterOrchestrator
+	TagRules // tags added by the tagger rules override the collected ones
 )
 
 // TagCardinality indicates the cardinality-level of a tag.
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package local
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	pbutils "github.com/DataDog/datadog-agent/pkg/proto/utils"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// snapshotSource is the source of the tags restored from a snapshot. They
+	// are kept until they expire, so that the tags of the collectors that
+	// didn't report the entity yet aren't lost.
+	snapshotSource = "snapshot"
+	// snapshotPriority is the priority of the restored tags, lower than the
+	// ones of all the collectors so that the collected tags override them
+	snapshotPriority = collectors.NodeRuntime - 1
+)
+
+// snapshotConfig holds the tagger_snapshot options
+type snapshotConfig struct {
+	path       string
+	interval   time.Duration
+	expiration time.Duration
+}
+
+// loadSnapshotConfig returns the snapshot configuration, or nil if snapshots
+// are disabled
+func loadSnapshotConfig() *snapshotConfig {
+	if !config.Datadog.GetBool("tagger_snapshot.enabled") {
+		return nil
+	}
+
+	cfg := &snapshotConfig{
+		path:       config.Datadog.GetString("tagger_snapshot.path"),
+		interval:   time.Duration(config.Datadog.GetInt("tagger_snapshot.interval")) * time.Second,
+		expiration: time.Duration(config.Datadog.GetInt("tagger_snapshot.expiration")) * time.Second,
+	}
+	if cfg.path == "" {
+		// the binaries sharing the run path don't collect the same entities
+		cfg.path = filepath.Join(config.Datadog.GetString("run_path"), fmt.Sprintf("tagger-%s.snapshot", flavor.GetFlavor()))
+	}
+	if cfg.interval <= 0 || cfg.expiration <= 0 {
+		log.Warnf("Tagger snapshots are disabled, tagger_snapshot.interval and tagger_snapshot.expiration must be positive")
+		return nil
+	}
+	return cfg
+}
+
+// snapshot returns the tags of the entities of the store, in the format of the
+// tagger state of the dogstatsd captures, loaded by the replay tagger. Only
+// the collected tags are included, so that the ones restored from the previous
+// snapshot and not collected since expire.
+func (s *tagStore) snapshot() *pb.TaggerState {
+	s.RLock()
+	defer s.RUnlock()
+
+	state := &pb.TaggerState{
+		State: make(map[string]*pb.Entity, len(s.store)),
+	}
+
+	for entityID, storedTags := range s.store {
+		collected := newEntityTags(entityID)
+		for source, tags := range storedTags.sourceTags {
+			if source != snapshotSource {
+				collected.sourceTags[source] = tags
+			}
+		}
+		collected.cacheValid = false
+		collected.computeCache()
+		if len(collected.cachedSource) == 0 {
+			continue
+		}
+
+		id, err := pbutils.Tagger2PbEntityID(entityID)
+		if err != nil {
+			log.Debugf("Skipping entity %s in tagger snapshot: %s", entityID, err)
+			continue
+		}
+
+		entity := collected.toEntity()
+		state.State[entityID] = &pb.Entity{
+			Id:                          id,
+			HighCardinalityTags:         entity.HighCardinalityTags,
+			OrchestratorCardinalityTags: entity.OrchestratorCardinalityTags,
+			LowCardinalityTags:          entity.LowCardinalityTags,
+			StandardTags:                entity.StandardTags,
+		}
+	}
+
+	return state
+}
+
+// restore adds the entities of a snapshot to the store, with tags expiring at
+// the given date unless a collector sends the tags of the entity before
+func (s *tagStore) restore(state *pb.TaggerState, expiryDate time.Time) {
+	tagInfos := make([]*collectors.TagInfo, 0, len(state.State))
+	for id, entity := range state.State {
+		entityID, err := pbutils.Pb2TaggerEntityID(entity.Id)
+		if err != nil {
+			log.Debugf("Skipping entity %s of the tagger snapshot: %s", id, err)
+			continue
+		}
+
+		tagInfos = append(tagInfos, &collectors.TagInfo{
+			Source:               snapshotSource,
+			Entity:               entityID,
+			LowCardTags:          entity.LowCardinalityTags,
+			OrchestratorCardTags: entity.OrchestratorCardinalityTags,
+			HighCardTags:         entity.HighCardinalityTags,
+			StandardTags:         entity.StandardTags,
+			ExpiryDate:           expiryDate,
+		})
+	}
+
+	s.processTagInfo(tagInfos)
+}
+
+// writeSnapshot writes the snapshot of the store to the file. The file is
+// replaced atomically, so that it can't be read partially written.
+func (s *tagStore) writeSnapshot(path string) error {
+	data, err := proto.Marshal(s.snapshot())
+	if err != nil {
+		return fmt.Errorf("could not encode the tagger snapshot: %s", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not create the tagger snapshot: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("could not write the tagger snapshot: %s", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readSnapshot reads the snapshot file and adds its entities to the store.
+// A snapshot older than the expiration is ignored, the restored tags expire
+// once the expiration has passed since the snapshot was written.
+func (s *tagStore) readSnapshot(path string, expiration time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	expiryDate := info.ModTime().Add(expiration)
+	if expiryDate.Before(s.clock.Now()) {
+		return fmt.Errorf("the tagger snapshot %s is expired, it was written at %s", path, info.ModTime())
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	state := &pb.TaggerState{}
+	if err := proto.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("could not decode the tagger snapshot %s: %s", path, err)
+	}
+
+	s.restore(state, expiryDate)
+	log.Infof("Restored %d entities from the tagger snapshot %s", len(state.State), path)
+	return nil
+}
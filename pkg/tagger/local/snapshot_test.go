@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package local
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tagger-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tagger.snapshot")
+
+	store := newTagStore()
+	store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:               "docker",
+			Entity:               "container_id://abc",
+			LowCardTags:          []string{"image_name:nginx"},
+			OrchestratorCardTags: []string{"pod_name:web-1"},
+			HighCardTags:         []string{"container_id:abc"},
+			StandardTags:         []string{"service:web"},
+		},
+		{
+			Source:      "docker",
+			Entity:      "invalid",
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+	require.NoError(t, store.writeSnapshot(path))
+
+	restored := newTagStore()
+	clock := &fakeClock{now: time.Now()}
+	restored.clock = clock
+	require.NoError(t, restored.readSnapshot(path, 10*time.Minute))
+
+	// the invalid entity id can't be snapshotted
+	assert.Len(t, restored.store, 1)
+
+	tags, sources := restored.lookup("container_id://abc", collectors.HighCardinality)
+	assert.ElementsMatch(t, []string{"image_name:nginx", "pod_name:web-1", "container_id:abc"}, tags)
+	assert.Empty(t, sources, "the tags must still be fetched from the collectors")
+	standard, err := restored.lookupStandard("container_id://abc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"service:web"}, standard)
+
+	// the entities not collected since the restore aren't snapshotted again
+	assert.Empty(t, restored.snapshot().State)
+
+	// the restored tags expire
+	clock.now = clock.now.Add(11 * time.Minute)
+	restored.prune()
+	assert.Empty(t, restored.store)
+}
+
+func TestSnapshotOverriddenByCollectedTags(t *testing.T) {
+	store := newTagStore()
+	store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:      snapshotSource,
+			Entity:      "container_id://abc",
+			LowCardTags: []string{"image_name:nginx", "env:prod"},
+			ExpiryDate:  time.Now().Add(time.Minute),
+		},
+	})
+
+	// a cache miss doesn't replace the restored tags
+	store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:    "docker",
+			Entity:    "container_id://abc",
+			CacheMiss: true,
+		},
+	})
+	tags, _ := store.lookup("container_id://abc", collectors.LowCardinality)
+	assert.ElementsMatch(t, []string{"image_name:nginx", "env:prod"}, tags)
+
+	clock := &fakeClock{now: time.Now()}
+	store.clock = clock
+	store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "kubelet",
+			Entity:      "container_id://abc",
+			LowCardTags: []string{"image_name:redis"},
+		},
+	})
+
+	// the collected tags override the restored ones, which are kept until
+	// they expire for the collectors that didn't report the entity yet
+	tags, sources := store.lookup("container_id://abc", collectors.LowCardinality)
+	assert.ElementsMatch(t, []string{"image_name:redis", "env:prod"}, tags)
+	assert.ElementsMatch(t, []string{"docker", "kubelet"}, sources)
+
+	// only the collected tags are snapshotted
+	state := store.snapshot().State
+	require.Len(t, state, 1)
+	assert.Equal(t, []string{"image_name:redis"}, state["container_id://abc"].LowCardinalityTags)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	store.prune()
+	tags, _ = store.lookup("container_id://abc", collectors.LowCardinality)
+	assert.Equal(t, []string{"image_name:redis"}, tags)
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tagger-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newTagStore()
+	assert.Error(t, store.readSnapshot(filepath.Join(dir, "missing"), time.Minute))
+
+	invalid := filepath.Join(dir, "invalid")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("not a snapshot"), 0644))
+	assert.Error(t, store.readSnapshot(invalid, time.Minute))
+
+	expired := filepath.Join(dir, "expired")
+	require.NoError(t, store.writeSnapshot(expired))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(expired, old, old))
+	assert.Error(t, store.readSnapshot(expired, time.Minute))
+}
+
+func TestLoadSnapshotConfigDefaultPath(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("tagger_snapshot.enabled", true)
+	mockConfig.Set("run_path", "/opt/datadog-agent/run")
+
+	defer flavor.SetFlavor(flavor.GetFlavor())
+	flavor.SetFlavor(flavor.ClusterAgent)
+
+	cfg := loadSnapshotConfig()
+	require.NotNil(t, cfg)
+	assert.Equal(t, "/opt/datadog-agent/run/tagger-cluster_agent.snapshot", cfg.path)
+}
@@ -45,6 +45,7 @@ type Tagger struct {
 	pruneTicker     *time.Ticker
 	retryTicker     *time.Ticker
 	telemetryTicker *time.Ticker
+	snapshot        *snapshotConfig
 	stop            chan bool
 	health          *health.Handle
 }
@@ -90,6 +91,14 @@ func (t *Tagger) Init() error {
 	// Only register the health check when the tagger is started
 	t.health = health.RegisterLiveness("tagger")
 
+	// Restore the tags of the previous run while the collectors start
+	t.snapshot = loadSnapshotConfig()
+	if t.snapshot != nil {
+		if err := t.store.readSnapshot(t.snapshot.path, t.snapshot.expiration); err != nil {
+			log.Infof("Tagger snapshot not restored: %s", err)
+		}
+	}
+
 	// TODO(deps injection): add a context in the tagger struct to how the restart if the tagger
 	t.startCollectors(context.TODO())
 	go t.run() //nolint:errcheck
@@ -100,6 +109,14 @@ func (t *Tagger) Init() error {
 
 func (t *Tagger) run() error {
 	ctx, cancel := context.WithCancel(context.Background())
+
+	var snapshotC <-chan time.Time
+	if t.snapshot != nil {
+		snapshotTicker := time.NewTicker(t.snapshot.interval)
+		defer snapshotTicker.Stop()
+		snapshotC = snapshotTicker.C
+	}
+
 	for {
 		select {
 		case <-t.stop:
@@ -115,6 +132,7 @@ func (t *Tagger) run() error {
 			t.pruneTicker.Stop()
 			t.retryTicker.Stop()
 			t.telemetryTicker.Stop()
+			t.writeSnapshot()
 			t.health.Deregister() //nolint:errcheck
 			cancel()
 			return nil
@@ -131,10 +149,22 @@ func (t *Tagger) run() error {
 			t.store.prune()
 		case <-t.telemetryTicker.C:
 			t.store.collectTelemetry()
+		case <-snapshotC:
+			t.writeSnapshot()
 		}
 	}
 }
 
+// writeSnapshot writes the snapshot of the store, if enabled
+func (t *Tagger) writeSnapshot() {
+	if t.snapshot == nil {
+		return
+	}
+	if err := t.store.writeSnapshot(t.snapshot.path); err != nil {
+		log.Warnf("Error writing the tagger snapshot: %s", err)
+	}
+}
+
 // startCollectors iterates over the listener candidates and tries initializing them.
 // If the collector implements Retryer and return a FailWillRetry, we keep them in
 // the map and will retry at the next tick.
@@ -381,6 +411,13 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) response.TaggerList
 			tags = append(tags, sourceTags.orchestratorCardTags...)
 			tags = append(tags, sourceTags.highCardTags...)
 			entity.Tags[source] = tags
+
+			if len(sourceTags.removedTags) > 0 {
+				if entity.RemovedTags == nil {
+					entity.RemovedTags = make(map[string][]string)
+				}
+				entity.RemovedTags[source] = append([]string(nil), sourceTags.removedTags...)
+			}
 		}
 
 		r.Entities[entityID] = entity
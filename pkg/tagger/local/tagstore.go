@@ -49,6 +49,7 @@ type sourceTags struct {
 	orchestratorCardTags []string
 	highCardTags         []string
 	standardTags         []string
+	removedTags          []string
 	expiryDate           time.Time
 }
 
@@ -59,6 +60,7 @@ type tagStore struct {
 
 	store     map[string]*entityTags
 	telemetry map[string]map[string]float64
+	rules     []*collectors.TagRule
 
 	subscriber *subscriber.Subscriber
 
@@ -69,6 +71,7 @@ func newTagStore() *tagStore {
 	return &tagStore{
 		telemetry:  make(map[string]map[string]float64),
 		store:      make(map[string]*entityTags),
+		rules:      collectors.LoadTagRules(),
 		subscriber: subscriber.NewSubscriber(),
 		clock:      realClock{},
 	}
@@ -103,6 +106,12 @@ func (s *tagStore) processTagInfo(tagInfos []*collectors.TagInfo) {
 					st.expiryDate = s.clock.Now().Add(deletedTTL)
 					storedTags.sourceTags[info.Source] = st
 				}
+
+				// the entity may only be known from the snapshot
+				if st, ok := storedTags.sourceTags[snapshotSource]; ok {
+					st.expiryDate = s.clock.Now().Add(deletedTTL)
+					storedTags.sourceTags[snapshotSource] = st
+				}
 			}
 
 			continue
@@ -125,6 +134,9 @@ func (s *tagStore) processTagInfo(tagInfos []*collectors.TagInfo) {
 
 		telemetry.UpdatedEntities.Inc()
 		updateStoredTags(storedTags, info)
+		if !collectors.IsRuleSource(info.Source) {
+			s.applyRules(storedTags)
+		}
 
 		events = append(events, types.EntityEvent{
 			EventType: eventType,
@@ -144,10 +156,29 @@ func updateStoredTags(storedTags *entityTags, info *collectors.TagInfo) {
 		orchestratorCardTags: info.OrchestratorCardTags,
 		highCardTags:         info.HighCardTags,
 		standardTags:         info.StandardTags,
+		removedTags:          info.RemovedTags,
 		expiryDate:           info.ExpiryDate,
 	}
 }
 
+// applyRules updates the tags added and removed by the tagger rules, from the
+// tags collected for the entity
+func (s *tagStore) applyRules(storedTags *entityTags) {
+	if len(s.rules) == 0 {
+		return
+	}
+
+	tags, collected := storedTags.collectedTags()
+	for _, rule := range s.rules {
+		if collected && rule.Matches(storedTags.entityID, tags) {
+			updateStoredTags(storedTags, rule.TagInfo(storedTags.entityID))
+		} else if _, found := storedTags.sourceTags[rule.Source]; found {
+			delete(storedTags.sourceTags, rule.Source)
+			storedTags.cacheValid = false
+		}
+	}
+}
+
 func (s *tagStore) collectTelemetry() {
 	// our telemetry package does not seem to have a way to reset a Gauge,
 	// so we need to keep track of all the labels we use, and re-set them
@@ -220,6 +251,11 @@ func (s *tagStore) prune() {
 			}
 		}
 
+		// the tags added by rules depend on the remaining sources
+		if changed {
+			s.applyRules(storedTags)
+		}
+
 		// remove all sourceTags only if they're all empty
 		if storedTags.isEmpty() {
 			storedTags.sourceTags = nil
@@ -289,6 +325,23 @@ func (s *tagStore) getEntityTags(entityID string) (*entityTags, error) {
 	return storedTags, nil
 }
 
+// collectedTags returns the tags of the entity collected from the sources other
+// than the tagger rules, and whether there is any such source
+func (e *entityTags) collectedTags() ([]string, bool) {
+	var tags []string
+	collected := false
+	for source, st := range e.sourceTags {
+		if collectors.IsRuleSource(source) {
+			continue
+		}
+		collected = true
+		tags = append(tags, st.lowCardTags...)
+		tags = append(tags, st.orchestratorCardTags...)
+		tags = append(tags, st.highCardTags...)
+	}
+	return tags, collected
+}
+
 func (e *entityTags) getStandard() []string {
 	tags := []string{}
 	for _, t := range e.sourceTags {
@@ -332,13 +385,29 @@ func (e *entityTags) computeCache() {
 	}
 
 	var sources []string
+	var removedTags []string
 	tagPrioMapper := make(map[string][]tagPriority)
 
+	for _, tags := range e.sourceTags {
+		removedTags = append(removedTags, tags.removedTags...)
+	}
+
 	for source, tags := range e.sourceTags {
-		sources = append(sources, source)
-		insertWithPriority(tagPrioMapper, tags.lowCardTags, source, collectors.LowCardinality)
-		insertWithPriority(tagPrioMapper, tags.orchestratorCardTags, source, collectors.OrchestratorCardinality)
-		insertWithPriority(tagPrioMapper, tags.highCardTags, source, collectors.HighCardinality)
+		// only the collectors are reported as sources, so that the tagger
+		// fetches the tags of the entity from the other ones
+		if source != snapshotSource && !collectors.IsRuleSource(source) {
+			sources = append(sources, source)
+		}
+		low, orchestrator, high := tags.lowCardTags, tags.orchestratorCardTags, tags.highCardTags
+		// the tagger rules remove tags from the other sources only
+		if len(removedTags) > 0 && !collectors.IsRuleSource(source) {
+			low = filterRemoved(low, removedTags)
+			orchestrator = filterRemoved(orchestrator, removedTags)
+			high = filterRemoved(high, removedTags)
+		}
+		insertWithPriority(tagPrioMapper, low, source, collectors.LowCardinality)
+		insertWithPriority(tagPrioMapper, orchestrator, source, collectors.OrchestratorCardinality)
+		insertWithPriority(tagPrioMapper, high, source, collectors.HighCardinality)
 	}
 
 	var lowCardTags []string
@@ -403,7 +472,11 @@ func (st *sourceTags) isExpired(t time.Time) bool {
 
 func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, cardinality collectors.TagCardinality) {
 	priority, found := collectors.CollectorPriorities[source]
-	if !found {
+	if !found && collectors.IsRuleSource(source) {
+		priority = collectors.TagRules
+	} else if !found && source == snapshotSource {
+		priority = snapshotPriority
+	} else if !found {
 		log.Warnf("Tagger: %s collector has no defined priority, assuming low", source)
 		priority = collectors.NodeRuntime
 	}
@@ -417,3 +490,13 @@ func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, s
 		})
 	}
 }
+
+func filterRemoved(tags []string, removedTags []string) []string {
+	filtered := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if !collectors.IsRemoved(t, removedTags) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
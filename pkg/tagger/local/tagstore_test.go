@@ -278,6 +278,69 @@ func (s *StoreTestSuite) TestPrune__emptyEntries() {
 	assert.Len(s.T(), emptySource2, 0)
 }
 
+func (s *StoreTestSuite) TestRules() {
+	clock := &fakeClock{now: time.Now()}
+	s.store.clock = clock
+	for _, cfg := range []collectors.TagRuleConfig{
+		{Name: "web", Match: map[string]string{"image_name": "nginx*"}, AddTags: []string{"team:web"}, RemoveTags: []string{"team"}},
+		{Name: "billing", Match: map[string]string{"kube_namespace": "billing"}, AddTags: []string{"cost_center:42"}, Cardinality: "orchestrator"},
+	} {
+		rule, err := collectors.NewTagRule(cfg)
+		s.Require().NoError(err)
+		s.store.rules = append(s.store.rules, rule)
+	}
+
+	s.store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "docker",
+			Entity:      "container_id://web",
+			LowCardTags: []string{"image_name:nginx", "team:api"},
+		},
+		{
+			Source:               "kubelet",
+			Entity:               "container_id://web",
+			OrchestratorCardTags: []string{"pod_name:web-0"},
+		},
+		{
+			Source:      "kubelet",
+			Entity:      "container_id://billing",
+			LowCardTags: []string{"kube_namespace:billing", "team:billing"},
+		},
+	})
+
+	tags, sources := s.store.lookup("container_id://web", collectors.HighCardinality)
+	assert.ElementsMatch(s.T(), []string{"image_name:nginx", "team:web", "pod_name:web-0"}, tags)
+	// the rules aren't collectors the tags can be fetched from
+	assert.ElementsMatch(s.T(), []string{"docker", "kubelet"}, sources)
+
+	tags, _ = s.store.lookup("container_id://billing", collectors.LowCardinality)
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:billing", "team:billing"}, tags)
+	tags, _ = s.store.lookup("container_id://billing", collectors.OrchestratorCardinality)
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:billing", "team:billing", "cost_center:42"}, tags)
+
+	// the rule doesn't match anymore
+	s.store.processTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "docker",
+			Entity:      "container_id://web",
+			LowCardTags: []string{"image_name:redis", "team:api"},
+		},
+	})
+	tags, sources = s.store.lookup("container_id://web", collectors.HighCardinality)
+	assert.ElementsMatch(s.T(), []string{"image_name:redis", "team:api", "pod_name:web-0"}, tags)
+	assert.ElementsMatch(s.T(), []string{"docker", "kubelet"}, sources)
+
+	// the tags of the rules are pruned with the entity
+	s.store.processTagInfo([]*collectors.TagInfo{
+		{Source: "kubelet", Entity: "container_id://billing", DeleteEntity: true},
+	})
+	clock.now = clock.now.Add(10 * time.Minute)
+	s.store.prune()
+	tags, sources = s.store.lookup("container_id://billing", collectors.HighCardinality)
+	assert.Empty(s.T(), tags)
+	assert.Empty(s.T(), sources)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
@@ -38,8 +38,17 @@ func InitMetadataAsTags(metadataAsTags map[string]string) (map[string]string, ma
 
 // AddMetadataAsTags converts name and value into tags based on the metadata as tags configuration and patterns
 func AddMetadataAsTags(name, value string, metadataAsTags map[string]string, glob map[string]glob.Glob, tags *TagList) {
+	for _, tagName := range GetMetadataAsTagNames(name, metadataAsTags, glob) {
+		tags.AddAuto(tagName, value)
+	}
+}
+
+// GetMetadataAsTagNames returns the names of the tags a label or an annotation is converted to,
+// based on the metadata as tags configuration and patterns
+func GetMetadataAsTagNames(name string, metadataAsTags map[string]string, glob map[string]glob.Glob) []string {
+	var tagNames []string
+	n := strings.ToLower(name)
 	for pattern, tmpl := range metadataAsTags {
-		n := strings.ToLower(name)
 		if g, ok := glob[pattern]; ok {
 			if !g.Match(n) {
 				continue
@@ -47,8 +56,9 @@ func AddMetadataAsTags(name, value string, metadataAsTags map[string]string, glo
 		} else if pattern != n {
 			continue
 		}
-		tags.AddAuto(resolveTag(tmpl, name), value)
+		tagNames = append(tagNames, resolveTag(tmpl, name))
 	}
+	return tagNames
 }
 
 var templateVariables = map[string]struct{}{
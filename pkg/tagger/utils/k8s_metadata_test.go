@@ -88,6 +88,20 @@ func TestMetadataAsTags(t *testing.T) {
 	}
 }
 
+func TestGetMetadataAsTagNames(t *testing.T) {
+	m, g := InitMetadataAsTags(map[string]string{
+		"App":  "kube_app",
+		"tier": "%%label%%",
+		"team": "owner",
+		"te*":  "kube_%%label%%",
+	})
+
+	assert.Equal(t, []string{"kube_app"}, GetMetadataAsTagNames("app", m, g))
+	assert.Equal(t, []string{"tier"}, GetMetadataAsTagNames("tier", m, g))
+	assert.ElementsMatch(t, []string{"owner", "kube_team"}, GetMetadataAsTagNames("team", m, g))
+	assert.Empty(t, GetMetadataAsTagNames("version", m, g))
+}
+
 func TestResolveTag(t *testing.T) {
 	testCases := []struct {
 		tmpl, label, expected string
@@ -89,6 +89,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf.OTLPReceiver)
+	agnt.OTLPReceiver.Capture = agnt.Receiver.Capture
 	return agnt
 }
 
@@ -188,6 +189,8 @@ func (a *Agent) Process(p *api.Payload) {
 	ss := new(writer.SampledSpans)
 	var envtraces []stats.EnvTrace
 	a.PrioritySampler.CountClientDroppedP0s(p.ClientDroppedP0s)
+	byService := newServiceCounter()
+	defer byService.report()
 	for _, t := range p.Traces {
 		if len(t) == 0 {
 			log.Debugf("Skipping received empty trace")
@@ -208,6 +211,7 @@ func (a *Agent) Process(p *api.Payload) {
 
 		if !a.Blacklister.Allows(root) {
 			log.Debugf("Trace rejected by blacklister. root: %v", root)
+			byService.add(root.Service, outcomeFiltered)
 			atomic.AddInt64(&ts.TracesFiltered, 1)
 			atomic.AddInt64(&ts.SpansFiltered, tracen)
 			continue
@@ -215,6 +219,7 @@ func (a *Agent) Process(p *api.Payload) {
 
 		if filteredByTags(root, a.conf.RequireTags, a.conf.RejectTags) {
 			log.Debugf("Trace rejected as it fails to meet tag requirements. root: %v", root)
+			byService.add(root.Service, outcomeFiltered)
 			atomic.AddInt64(&ts.TracesFiltered, 1)
 			atomic.AddInt64(&ts.SpansFiltered, tracen)
 			continue
@@ -277,9 +282,12 @@ func (a *Agent) Process(p *api.Payload) {
 		}
 		// TODO(piochelepiotr): Maybe we can skip some computation if stats are computed in the tracer and the trace is droped.
 		if keep {
+			byService.add(root.Service, outcomeKept)
 			ss.Traces = append(ss.Traces, traceutil.APITrace(t))
 			ss.Size += t.Msgsize()
 			ss.SpanCount += int64(len(t))
+		} else {
+			byService.add(root.Service, outcomeDropped)
 		}
 		if len(events) > 0 {
 			ss.Events = append(ss.Events, events...)
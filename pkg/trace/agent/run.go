@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/local"
 	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
+	"github.com/DataDog/datadog-agent/pkg/trace/capture"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/flags"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
@@ -73,6 +74,20 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if flags.Capture > 0 {
+		path, err := capture.StartRemote(receiverURL(cfg), flags.Capture, "")
+		if err != nil {
+			osutil.Exitf("Failed to start capture: %s", err)
+		}
+		fmt.Printf("Capturing payloads for %s into %s\n", flags.Capture, path)
+		return
+	}
+
+	if flags.Replay != "" {
+		runReplay(ctx, cfg)
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		cfg.LogLevel,
@@ -127,6 +142,9 @@ func Run(ctx context.Context) {
 	if err != nil {
 		osutil.Exitf("cannot configure dogstatsd: %v", err)
 	}
+	if cfg.PrometheusEnabled {
+		metrics.EnablePrometheus()
+	}
 	defer metrics.Flush()
 	defer timing.Stop()
 
@@ -175,6 +193,30 @@ func Run(ctx context.Context) {
 	}
 }
 
+// receiverURL returns the base URL of the trace agent's receiver.
+func receiverURL(cfg *config.AgentConfig) string {
+	return fmt.Sprintf("http://%s:%d", cfg.ReceiverHost, cfg.ReceiverPort)
+}
+
+// runReplay replays the capture file specified by flags into the target agent.
+func runReplay(ctx context.Context, cfg *config.AgentConfig) {
+	opts := capture.ReplayOptions{
+		Target:   flags.ReplayTarget,
+		Realtime: flags.ReplayRealtime,
+	}
+	if opts.Target == "" {
+		opts.Target = receiverURL(cfg)
+		if cfg.OTLPReceiver != nil && cfg.OTLPReceiver.HTTPPort != 0 {
+			opts.OTLPTarget = fmt.Sprintf("http://%s:%d", cfg.ReceiverHost, cfg.OTLPReceiver.HTTPPort)
+		}
+	}
+	stats, err := capture.Replay(ctx, flags.Replay, opts)
+	fmt.Printf("Replayed %d payloads (%d failed, %d skipped)\n", stats.Sent, stats.Failed, stats.Skipped)
+	if err != nil {
+		osutil.Exitf("Failed to replay %s: %s", flags.Replay, err)
+	}
+}
+
 // runProfiling enables the profiler.
 func runProfiling(cfg *config.AgentConfig) {
 	if !coreconfig.Datadog.GetBool("apm_config.internal_profiling.enabled") {
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import "github.com/DataDog/datadog-agent/pkg/trace/metrics"
+
+// Outcomes of a trace, as reported by the per-service Prometheus metrics.
+const (
+	outcomeFiltered = "filtered" // rejected by the blacklister or by tag requirements
+	outcomeKept     = "kept"     // kept by the samplers
+	outcomeDropped  = "dropped"  // dropped by the samplers
+)
+
+// serviceCounter counts the traces of a payload by root service and outcome. These counts are
+// only exposed on the Prometheus endpoint, since their cardinality makes them too costly to send
+// to dogstatsd. The nil value is a no-op, used when the Prometheus endpoint is disabled.
+type serviceCounter map[serviceOutcome]int64
+
+type serviceOutcome struct{ service, outcome string }
+
+// newServiceCounter returns a new serviceCounter, or nil if the Prometheus endpoint is disabled.
+func newServiceCounter() serviceCounter {
+	if !metrics.PrometheusEnabled() {
+		return nil
+	}
+	return make(serviceCounter)
+}
+
+// add counts a trace having the given root service and outcome.
+func (c serviceCounter) add(service, outcome string) {
+	if c == nil {
+		return
+	}
+	c[serviceOutcome{service, outcome}]++
+}
+
+// report reports all counts.
+func (c serviceCounter) report() {
+	for k, n := range c {
+		metrics.CountLocal("datadog.trace_agent.receiver.service_traces", n, []string{"service:" + k.service, "outcome:" + k.outcome})
+	}
+}
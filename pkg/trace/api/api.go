@@ -21,6 +21,7 @@ import (
 	"net/http/pprof"
 	"os"
 	"runtime"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
@@ -35,6 +36,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/capture"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/config/features"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
@@ -61,6 +63,10 @@ func getBuffer() *bytes.Buffer {
 }
 
 func putBuffer(buffer *bytes.Buffer) {
+	if watchdog.CurrentStage() >= watchdog.StageShrinkBuffers {
+		// let the garbage collector reclaim it
+		return
+	}
 	bufferPool.Put(buffer)
 }
 
@@ -69,6 +75,7 @@ func putBuffer(buffer *bytes.Buffer) {
 type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
+	Capture     *capture.Recorder
 
 	out            chan *Payload
 	conf           *config.AgentConfig
@@ -80,6 +87,9 @@ type HTTPReceiver struct {
 	debug               bool
 	rateLimiterResponse int // HTTP status code when refusing
 
+	shedder   watchdog.LoadShedder // computes the load shedding stage; only used by the watchdog
+	oomChecks int                  // consecutive watchdog checks above the OOM kill threshold
+
 	wg   sync.WaitGroup // waits for all requests to be processed
 	exit chan struct{}
 }
@@ -97,6 +107,7 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 	return &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
+		Capture:     new(capture.Recorder),
 
 		out:            out,
 		statsProcessor: statsProcessor,
@@ -117,9 +128,16 @@ func (r *HTTPReceiver) buildMux() *http.ServeMux {
 	hash, infoHandler := r.makeInfoHandler()
 	r.attachDebugHandlers(mux)
 	for _, e := range endpoints {
-		mux.Handle(e.Pattern, replyWithVersion(hash, e.Handler(r)))
+		h := e.Handler(r)
+		if !e.SkipCapture {
+			h = r.captureHandler(h)
+		}
+		mux.Handle(e.Pattern, replyWithVersion(hash, h))
 	}
 	mux.HandleFunc("/info", infoHandler)
+	if h := metrics.PrometheusHandler(); h != nil {
+		mux.Handle("/metrics", h)
+	}
 
 	return mux
 }
@@ -229,6 +247,11 @@ func (r *HTTPReceiver) attachDebugHandlers(mux *http.ServeMux) {
 		runtime.SetBlockProfileRate(0)
 	})
 
+	if r.conf.CaptureEnabled {
+		// captures write files to disk, so they must be explicitly allowed
+		mux.HandleFunc("/debug/capture", r.handleCapture)
+	}
+
 	mux.Handle("/debug/vars", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// allow the GUI to call this endpoint so that the status can be reported
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+mainconfig.Datadog.GetString("GUI_port"))
@@ -281,6 +304,9 @@ func (r *HTTPReceiver) Stop() error {
 	<-r.exit
 
 	r.RateLimiter.Stop()
+	if err := r.Capture.Stop(); err != nil {
+		log.Errorf("Error stopping trace capture: %v", err)
+	}
 
 	expiry := time.Now().Add(5 * time.Second) // give it 5 seconds
 	ctx, cancel := context.WithDeadline(context.Background(), expiry)
@@ -457,6 +483,10 @@ func (r *HTTPReceiver) handleStats(w http.ResponseWriter, req *http.Request) {
 // handleTraces knows how to handle a bunch of traces
 func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.Request) {
 	ts := r.tagStats(v, req.Header)
+	if r.shedPayload(w) {
+		atomic.AddInt64(&ts.PayloadRefused, 1)
+		return
+	}
 	tracen, err := traceCount(req)
 	if err == nil && r.rateLimited(tracen) {
 		// this payload can not be accepted
@@ -469,20 +499,7 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 
 	traces, err := decodeTraces(v, req)
 	if err != nil {
-		httpDecodingError(err, []string{"handler:traces", fmt.Sprintf("v:%s", v)}, w)
-		switch err {
-		case apiutil.ErrLimitedReaderLimitReached:
-			atomic.AddInt64(&ts.TracesDropped.PayloadTooLarge, tracen)
-		case io.EOF, io.ErrUnexpectedEOF, msgp.ErrShortBytes:
-			atomic.AddInt64(&ts.TracesDropped.EOF, tracen)
-		default:
-			if err, ok := err.(net.Error); ok && err.Timeout() {
-				atomic.AddInt64(&ts.TracesDropped.Timeout, tracen)
-			} else {
-				atomic.AddInt64(&ts.TracesDropped.DecodingError, tracen)
-			}
-		}
-		log.Errorf("Cannot decode %s traces payload: %v", v, err)
+		r.decodingError(v, ts, tracen, err, w)
 		return
 	}
 	r.replyOK(v, w)
@@ -492,7 +509,7 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 	atomic.AddInt64(&ts.PayloadAccepted, 1)
 
 	cid := req.Header.Get(headerContainerID)
-	payload := &Payload{
+	r.send(&Payload{
 		Source:                 ts,
 		Traces:                 traces,
 		ContainerID:            cid,
@@ -500,10 +517,65 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 		ClientComputedTopLevel: req.Header.Get(headerComputedTopLevel) != "",
 		ClientComputedStats:    req.Header.Get(headerComputedStats) != "",
 		ClientDroppedP0s:       droppedTracesFromHeader(req.Header, ts),
+	})
+}
+
+// handleConvertedTraces handles requests containing traces encoded in a third-party format
+// (e.g. Zipkin or Jaeger), which are converted into Datadog traces using the given decode function.
+func (r *HTTPReceiver) handleConvertedTraces(decode func(*http.Request) (pb.Traces, error)) func(Version, http.ResponseWriter, *http.Request) {
+	return func(v Version, w http.ResponseWriter, req *http.Request) {
+		ts := r.tagStats(v, req.Header)
+		if r.shedPayload(w) {
+			atomic.AddInt64(&ts.PayloadRefused, 1)
+			return
+		}
+		traces, err := decode(req)
+		if err != nil {
+			r.decodingError(v, ts, 0, err, w)
+			return
+		}
+		if r.rateLimited(int64(len(traces))) {
+			w.WriteHeader(r.rateLimiterResponse)
+			atomic.AddInt64(&ts.PayloadRefused, 1)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+		atomic.AddInt64(&ts.TracesReceived, int64(len(traces)))
+		atomic.AddInt64(&ts.TracesBytes, req.Body.(*apiutil.LimitedReader).Count)
+		atomic.AddInt64(&ts.PayloadAccepted, 1)
+
+		r.send(&Payload{
+			Source:        ts,
+			Traces:        traces,
+			ContainerTags: getContainerTags(req.Header.Get(headerContainerID)),
+		})
+	}
+}
+
+// decodingError replies to a request for which decoding the payload failed with err, and
+// records the tracen traces which it contained as dropped in ts.
+func (r *HTTPReceiver) decodingError(v Version, ts *info.TagStats, tracen int64, err error, w http.ResponseWriter) {
+	httpDecodingError(err, []string{"handler:traces", fmt.Sprintf("v:%s", v)}, w)
+	switch err {
+	case apiutil.ErrLimitedReaderLimitReached:
+		atomic.AddInt64(&ts.TracesDropped.PayloadTooLarge, tracen)
+	case io.EOF, io.ErrUnexpectedEOF, msgp.ErrShortBytes:
+		atomic.AddInt64(&ts.TracesDropped.EOF, tracen)
+	default:
+		if err, ok := err.(net.Error); ok && err.Timeout() {
+			atomic.AddInt64(&ts.TracesDropped.Timeout, tracen)
+		} else {
+			atomic.AddInt64(&ts.TracesDropped.DecodingError, tracen)
+		}
 	}
+	log.Errorf("Cannot decode %s traces payload: %v", v, err)
+}
 
+// send sends the payload p down the out channel, without ever dropping it.
+func (r *HTTPReceiver) send(p *Payload) {
 	select {
-	case r.out <- payload:
+	case r.out <- p:
 		// ok
 	default:
 		// channel blocked, add a goroutine to ensure we never drop
@@ -514,7 +586,7 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 				r.wg.Done()
 				watchdog.LogOnPanic()
 			}()
-			r.out <- payload
+			r.out <- p
 		}()
 	}
 }
@@ -595,6 +667,11 @@ func (r *HTTPReceiver) loop() {
 		case now := <-t.C:
 			metrics.Gauge("datadog.trace_agent.heartbeat", 1, nil, 1)
 			metrics.Gauge("datadog.trace_agent.receiver.out_chan_fill", float64(len(r.out))/float64(cap(r.out)), nil, 1)
+			if metrics.PrometheusEnabled() {
+				for k, rate := range r.dynConf.RateByService.GetAll() {
+					metrics.GaugeLocal("datadog.trace_agent.sampler.rate_by_service", rate, strings.Split(k, ","))
+				}
+			}
 
 			// We update accStats with the new stats we collected
 			accStats.Acc(r.Stats)
@@ -629,22 +706,17 @@ var killProcess = func(format string, a ...interface{}) { osutil.Exitf(format, a
 // watchdog checks the trace-agent's heap and CPU usage and updates the rate limiter using a correct
 // sampling rate to maintain resource usage within set thresholds. These thresholds are defined by
 // the configuration MaxMemory and MaxCPU. If these values are 0, all limits are disabled and the rate
-// limiter will accept everything.
+// limiter will accept everything. As usage approaches or exceeds these thresholds, it additionally
+// moves the agent through the load shedding stages defined in the watchdog package.
 func (r *HTTPReceiver) watchdog(now time.Time) {
 	wi := watchdog.Info{
 		Mem: watchdog.Mem(),
 		CPU: watchdog.CPU(now),
 	}
+	var pressure float64 // highest ratio of resource usage to limit
 	rateMem := 1.0
 	if r.conf.MaxMemory > 0 {
-		if current, allowed := float64(wi.Mem.Alloc), r.conf.MaxMemory*1.5; current > allowed {
-			// This is a safety mechanism: if the agent is using more than 1.5x max. memory, there
-			// is likely a leak somewhere; we'll kill the process to avoid polluting host memory.
-			metrics.Count("datadog.trace_agent.receiver.oom_kill", 1, nil, 1)
-			metrics.Flush()
-			log.Criticalf("Killing process. Memory threshold exceeded: %.2fM / %.2fM", current/1024/1024, allowed/1024/1024)
-			killProcess("OOM")
-		}
+		pressure = float64(wi.Mem.Alloc) / r.conf.MaxMemory
 		rateMem = computeRateLimitingRate(r.conf.MaxMemory, float64(wi.Mem.Alloc), r.RateLimiter.RealRate())
 		if rateMem < 1 {
 			log.Warnf("Memory threshold exceeded (apm_config.max_memory: %.0f bytes): %d", r.conf.MaxMemory, wi.Mem.Alloc)
@@ -652,6 +724,7 @@ func (r *HTTPReceiver) watchdog(now time.Time) {
 	}
 	rateCPU := 1.0
 	if r.conf.MaxCPU > 0 {
+		pressure = math.Max(pressure, wi.CPU.UserAvg/r.conf.MaxCPU)
 		rateCPU = computeRateLimitingRate(r.conf.MaxCPU, wi.CPU.UserAvg, r.RateLimiter.RealRate())
 		if rateCPU < 1 {
 			log.Warnf("CPU threshold exceeded (apm_config.max_cpu_percent: %.0f): %.0f", r.conf.MaxCPU*100, wi.CPU.UserAvg)
@@ -660,6 +733,20 @@ func (r *HTTPReceiver) watchdog(now time.Time) {
 
 	r.RateLimiter.SetTargetRate(math.Min(rateCPU, rateMem))
 
+	shed, changed := r.shedder.Update(pressure, now)
+	if changed {
+		log.Warnf("Load shedding stage changed to %q (resource usage at %.0f%% of limits)", shed.Stage, pressure*100)
+		metrics.Count("datadog.trace_agent.watchdog.stage_change", 1, []string{"stage:" + shed.Stage.String()}, 1)
+	}
+	if shed.Stage >= watchdog.StageShrinkBuffers {
+		// return as much memory as possible to the OS, so that we can hopefully recover
+		debug.FreeOSMemory()
+	}
+	wi.Shedding = shed
+	if r.conf.MaxMemory > 0 {
+		r.checkOOM(float64(wi.Mem.Alloc))
+	}
+
 	stats := r.RateLimiter.Stats()
 
 	info.UpdateRateLimiter(*stats)
@@ -668,6 +755,54 @@ func (r *HTTPReceiver) watchdog(now time.Time) {
 	metrics.Gauge("datadog.trace_agent.heap_alloc", float64(wi.Mem.Alloc), nil, 1)
 	metrics.Gauge("datadog.trace_agent.cpu_percent", wi.CPU.UserAvg*100, nil, 1)
 	metrics.Gauge("datadog.trace_agent.receiver.ratelimit", stats.TargetRate, nil, 1)
+	metrics.Gauge("datadog.trace_agent.watchdog.pressure", pressure, nil, 1)
+	metrics.Gauge("datadog.trace_agent.watchdog.shedding_stage", float64(shed.Stage), nil, 1)
+}
+
+// oomKillChecks specifies the number of consecutive watchdog checks during which memory usage
+// has to stay above the kill threshold for the process to be killed.
+const oomKillChecks = 3
+
+// checkOOM kills the process if the allocated memory alloc stayed above 1.5x the maximum memory
+// for oomKillChecks consecutive checks, despite load shedding.
+func (r *HTTPReceiver) checkOOM(alloc float64) {
+	allowed := r.conf.MaxMemory * 1.5
+	if alloc <= allowed {
+		r.oomChecks = 0
+		return
+	}
+	r.oomChecks++
+	if r.oomChecks < oomKillChecks {
+		log.Warnf("Memory usage above kill threshold despite load shedding (check %d/%d): %.2fM / %.2fM", r.oomChecks, oomKillChecks, alloc/1024/1024, allowed/1024/1024)
+		return
+	}
+	// This is a safety mechanism: if the agent is still using more than 1.5x max. memory after shedding
+	// load, there is likely a leak somewhere; we'll kill the process to avoid polluting host memory.
+	metrics.Count("datadog.trace_agent.receiver.oom_kill", 1, nil, 1)
+	metrics.Flush()
+	log.Criticalf("Killing process. Memory threshold exceeded: %.2fM / %.2fM", alloc/1024/1024, allowed/1024/1024)
+	killProcess("OOM")
+}
+
+// shedPayload replies with 429 Too Many Requests and a Retry-After header and returns true if
+// the trace-agent is rejecting payloads to shed load.
+func (r *HTTPReceiver) shedPayload(w http.ResponseWriter) bool {
+	if watchdog.CurrentStage() < watchdog.StageRejectPayloads {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(r.conf.WatchdogInterval)))
+	w.WriteHeader(http.StatusTooManyRequests)
+	metrics.Count("datadog.trace_agent.receiver.payload_shed", 1, nil, 1)
+	return true
+}
+
+// retryAfterSeconds returns the number of seconds clients should wait before retrying a rejected
+// payload, which is the time until the next watchdog check.
+func retryAfterSeconds(interval time.Duration) int {
+	if s := int(math.Ceil(interval.Seconds())); s > 1 {
+		return s
+	}
+	return 1
 }
 
 // Languages returns the list of the languages used in the traces the agent receives.
@@ -33,6 +33,7 @@ func TestOOMKill(t *testing.T) {
 	conf.MaxMemory = 0.5 * 1000 * 1000 // 0.5M
 
 	r := newTestReceiverFromConfig(conf)
+	defer resetShedding(r)
 	r.Start()
 	defer r.Stop()
 	go func() {
@@ -25,9 +25,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 
 	"github.com/cihub/seelog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tinylib/msgp/msgp"
 	vmsgp "github.com/vmihailenco/msgpack/v4"
 )
@@ -1051,6 +1053,7 @@ func TestWatchdog(t *testing.T) {
 		conf.WatchdogInterval = time.Minute // we trigger manually
 
 		r := newTestReceiverFromConfig(conf)
+		defer resetShedding(r)
 		r.Start()
 		defer r.Stop()
 		go func() {
@@ -1103,6 +1106,7 @@ func TestWatchdog(t *testing.T) {
 			conf:        cfg,
 			RateLimiter: newRateLimiter(),
 		}
+		defer resetShedding(r)
 
 		cfg.MaxMemory = 0
 		cfg.MaxCPU = 0
@@ -1113,6 +1117,52 @@ func TestWatchdog(t *testing.T) {
 		r.watchdog(time.Now())
 		assert.NotEqual(t, 1.0, r.RateLimiter.TargetRate())
 	})
+
+	t.Run("shedding", func(t *testing.T) {
+		cfg := newTestReceiverConfig()
+		cfg.WatchdogInterval = 2500 * time.Millisecond
+		r := newTestReceiverFromConfig(cfg)
+		r.dynConf.RateByService.SetAll(map[sampler.ServiceSignature]float64{{Name: "svc", Env: "none"}: 0.8})
+		defer resetShedding(r)
+
+		post := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/v0.4/traces", bytes.NewReader(msgpTraces(t, pb.Traces{testutil.RandomTrace(1, 1)})))
+			req.Header.Set("Content-Type", "application/msgpack")
+			rec := httptest.NewRecorder()
+			r.handleWithVersion(v04, r.handleTraces).ServeHTTP(rec, req)
+			return rec
+		}
+		rates := func(rec *httptest.ResponseRecorder) map[string]float64 {
+			var resp traceResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			return resp.Rates
+		}
+
+		rec := post()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 0.8, rates(rec)["service:svc,env:none"])
+		<-r.out
+
+		r.shedder.Update(0.9, time.Now())
+		rec = post()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 0.4, rates(rec)["service:svc,env:none"])
+		<-r.out
+
+		r.shedder.Update(1.3, time.Now())
+		rec = post()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+		assert.Len(t, r.out, 0)
+	})
+}
+
+// resetShedding brings the receiver r, along with the process-wide load shedding stage, back to
+// the normal stage.
+func resetShedding(r *HTTPReceiver) {
+	for watchdog.CurrentStage() != watchdog.StageNormal {
+		r.shedder.Update(0, time.Now())
+	}
 }
 
 func msgpTraces(t *testing.T, traces pb.Traces) []byte {
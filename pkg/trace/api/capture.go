@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/capture"
+)
+
+// maxCaptureDuration specifies the maximum duration of a capture requested via /debug/capture.
+const maxCaptureDuration = 10 * time.Minute
+
+// captureHandler returns an http.Handler which records the requests handled by h into
+// the receiver's capture file while a capture is in progress.
+func (r *HTTPReceiver) captureHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := r.Capture.Active(); ok {
+			body := r.Capture.Wrap(capture.ProtocolDatadog, req)
+			// the server closes the original body, not this one, so we do it ourselves
+			defer body.Close()
+			req.Body = body
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
+// handleCapture reports the state of the capture on GET requests and starts a new capture on
+// POST requests. The "duration" query parameter specifies the duration of the capture (defaults
+// to one minute) and the optional "name" query parameter specifies the name of the capture file.
+// Capture files are always written next to the agent's log file.
+func (r *HTTPReceiver) handleCapture(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var resp capture.StatusResponse
+	switch req.Method {
+	case http.MethodGet:
+		resp.Path, resp.Active = r.Capture.Active()
+	case http.MethodPost:
+		if err := r.startCapture(req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			resp.Error = err.Error()
+		}
+		resp.Path, resp.Active = r.Capture.Active()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		resp.Error = "method not allowed"
+	}
+	json.NewEncoder(w).Encode(resp) //nolint:errcheck
+}
+
+// startCapture starts a capture based on the query parameters of req.
+func (r *HTTPReceiver) startCapture(req *http.Request) error {
+	d := time.Minute
+	if v := req.URL.Query().Get("duration"); v != "" {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid duration: %v", err)
+		}
+	}
+	if d <= 0 || d > maxCaptureDuration {
+		return fmt.Errorf("duration must be between 0 and %s", maxCaptureDuration)
+	}
+	name := filepath.Base(req.URL.Query().Get("name"))
+	if name == "." || name == string(filepath.Separator) {
+		name = fmt.Sprintf("trace-capture-%d.jsonl", time.Now().Unix())
+	}
+	return r.Capture.Start(filepath.Join(filepath.Dir(r.conf.LogFilePath), name), d)
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/capture"
+	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapture(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.LogFilePath = filepath.Join(t.TempDir(), "trace-agent.log")
+	conf.CaptureEnabled = true
+	r := newTestReceiverFromConfig(conf)
+	srv := httptest.NewServer(r.buildMux())
+	defer srv.Close()
+
+	status := func(method, query string) (int, capture.StatusResponse) {
+		req, err := http.NewRequest(method, srv.URL+"/debug/capture"+query, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var out capture.StatusResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return resp.StatusCode, out
+	}
+
+	code, st := status("GET", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, st.Active)
+
+	code, st = status("POST", "?duration=1h")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.NotEmpty(t, st.Error)
+
+	path, err := capture.StartRemote(srv.URL, time.Minute, "../test.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(filepath.Dir(conf.LogFilePath), "test.jsonl"), path)
+
+	payload := msgpTraces(t, testutil.GetTestTraces(1, 1, false))
+	req, err := http.NewRequest("POST", srv.URL+"/v0.4/traces", bytes.NewReader(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/msgpack")
+	req.Header.Set(headerLang, "go")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, r.Capture.Stop())
+	rd, err := capture.Open(path)
+	require.NoError(t, err)
+	defer rd.Close()
+	rec, err := rd.Next()
+	require.NoError(t, err)
+	assert.Equal(t, capture.ProtocolDatadog, rec.Protocol)
+	assert.Equal(t, "/v0.4/traces", rec.Path)
+	assert.Equal(t, "go", rec.Header.Get(headerLang))
+	assert.Equal(t, payload, rec.Body)
+	_, err = rd.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCaptureDisabled(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.LogFilePath = filepath.Join(t.TempDir(), "trace-agent.log")
+	r := newTestReceiverFromConfig(conf)
+	srv := httptest.NewServer(r.buildMux())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/debug/capture?duration=1m", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_, ok := r.Capture.Active()
+	assert.False(t, ok)
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb/otlppb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// This file holds the helpers shared by the conversions of third-party (Zipkin and Jaeger)
+// spans into Datadog spans. OpenTelemetry span kinds are used as the common denominator,
+// so that the same naming and typing rules apply as for OTLP.
+
+// spanKindFromName returns the span kind corresponding to the given case-insensitive name,
+// as found in Zipkin's "kind" field or in the "span.kind" tag.
+func spanKindFromName(name string) otlppb.Span_SpanKind {
+	switch strings.ToLower(name) {
+	case "server":
+		return otlppb.Span_SPAN_KIND_SERVER
+	case "client":
+		return otlppb.Span_SPAN_KIND_CLIENT
+	case "producer":
+		return otlppb.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return otlppb.Span_SPAN_KIND_CONSUMER
+	case "":
+		return otlppb.Span_SPAN_KIND_UNSPECIFIED
+	default:
+		return otlppb.Span_SPAN_KIND_INTERNAL
+	}
+}
+
+// spanEvent is the JSON representation of a span annotation or log, as stored in the
+// "events" tag. It matches the representation used for OTLP span events.
+type spanEvent struct {
+	TimeUnixNano uint64            `json:"time_unix_nano,omitempty"`
+	Name         string            `json:"name,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// marshalSpanEvents marshals events into JSON.
+func marshalSpanEvents(events []spanEvent) string {
+	out, err := json.Marshal(events)
+	if err != nil {
+		// should never happen
+		return ""
+	}
+	return string(out)
+}
+
+// finishConvertedSpan completes the conversion of span by computing its name, resource, type,
+// environment and error, based on the given kind and on the tags already set. The name is
+// prefixed by the name of the originating format (e.g. "zipkin.server"). Since third-party
+// clients only send sampled spans, they are all marked as kept; the keep flag marks the ones
+// which were forced to be kept (e.g. Zipkin's debug flag).
+func finishConvertedSpan(span *pb.Span, format string, kind otlppb.Span_SpanKind, keep bool) {
+	span.Name = format + "." + spanKindName(kind)
+	if r := resourceFromTags(span.Meta); r != "" {
+		span.Resource = r
+	}
+	span.Type = spanKind2Type(kind, span)
+	if _, ok := span.Meta["env"]; !ok {
+		if env := span.Meta[string(semconv.DeploymentEnvironmentKey)]; env != "" {
+			span.Meta["env"] = env
+		}
+	}
+	if v, ok := span.Meta["error"]; ok {
+		// both Zipkin and Jaeger mark errors using the "error" tag, which holds either
+		// "true" or an error message
+		if b, err := strconv.ParseBool(v); err != nil || b {
+			span.Error = 1
+			if err != nil && v != "" {
+				span.Meta["error.msg"] = v
+			}
+		}
+		delete(span.Meta, "error")
+	}
+	priority := sampler.PriorityAutoKeep
+	if keep {
+		priority = sampler.PriorityUserKeep
+	}
+	span.Metrics[sampler.KeySamplingPriority] = float64(priority)
+}
+
+// groupByTraceID groups the given spans into traces.
+func groupByTraceID(spans []*pb.Span) pb.Traces {
+	var (
+		traces pb.Traces
+		index  = make(map[uint64]int)
+	)
+	for _, span := range spans {
+		i, ok := index[span.TraceID]
+		if !ok {
+			i = len(traces)
+			index[span.TraceID] = i
+			traces = append(traces, pb.Trace{})
+		}
+		traces[i] = append(traces[i], span)
+	}
+	return traces
+}
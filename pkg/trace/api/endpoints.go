@@ -5,7 +5,11 @@
 
 package api
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
 
 // endpoint specifies an API endpoint definition.
 type endpoint struct {
@@ -18,6 +22,10 @@ type endpoint struct {
 	// Hidden reports whether this endpoint should be hidden in the /info
 	// discovery endpoint.
 	Hidden bool
+
+	// SkipCapture reports whether the payloads received by this endpoint should
+	// not be recorded when a capture is in progress.
+	SkipCapture bool
 }
 
 // endpoints specifies the list of endpoints registered for the trace-agent API.
@@ -73,15 +81,33 @@ var endpoints = []endpoint{
 		Handler: func(r *HTTPReceiver) http.Handler { return r.handleWithVersion(v05, r.handleTraces) },
 	},
 	{
-		Pattern: "/profiling/v1/input",
-		Handler: func(r *HTTPReceiver) http.Handler { return r.profileProxyHandler() },
+		Pattern:     "/profiling/v1/input",
+		Handler:     func(r *HTTPReceiver) http.Handler { return r.profileProxyHandler() },
+		SkipCapture: true,
 	},
 	{
 		Pattern: "/v0.6/stats",
 		Handler: func(r *HTTPReceiver) http.Handler { return http.HandlerFunc(r.handleStats) },
 	},
 	{
-		Pattern: "/appsec/proxy/",
-		Handler: func(r *HTTPReceiver) http.Handler { return http.StripPrefix("/appsec/proxy", r.appsecHandler) },
+		Pattern: "/api/v2/spans",
+		Handler: func(r *HTTPReceiver) http.Handler {
+			return r.handleWithVersion(zipkinV2, r.handleConvertedTraces(func(req *http.Request) (pb.Traces, error) {
+				return decodeZipkin(req, r.conf.MaxRequestBytes)
+			}))
+		},
+		Hidden: true,
+	},
+	{
+		Pattern: "/api/traces",
+		Handler: func(r *HTTPReceiver) http.Handler {
+			return r.handleWithVersion(jaegerThrift, r.handleConvertedTraces(decodeJaeger))
+		},
+		Hidden: true,
+	},
+	{
+		Pattern:     "/appsec/proxy/",
+		Handler:     func(r *HTTPReceiver) http.Handler { return http.StripPrefix("/appsec/proxy", r.appsecHandler) },
+		SkipCapture: true,
 	},
 }
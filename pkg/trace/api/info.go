@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/config/features"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 )
 
 // makeInfoHandler returns a new handler for handling the discovery endpoint.
@@ -60,7 +61,7 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		oconf.Redis = o.Redis.Enabled
 		oconf.Memcached = o.Memcached.Enabled
 	}
-	txt, err := json.MarshalIndent(struct {
+	type infoResponse struct {
 		Version       string        `json:"version"`
 		GitCommit     string        `json:"git_commit"`
 		BuildDate     string        `json:"build_date"`
@@ -68,7 +69,9 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		FeatureFlags  []string      `json:"feature_flags,omitempty"`
 		ClientDropP0s bool          `json:"client_drop_p0s"`
 		Config        reducedConfig `json:"config"`
-	}{
+		LoadShedding  string        `json:"load_shedding,omitempty"`
+	}
+	resp := infoResponse{
 		Version:       info.Version,
 		GitCommit:     info.GitCommit,
 		BuildDate:     info.BuildDate,
@@ -90,12 +93,22 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 			AnalyzedSpansByService: r.conf.AnalyzedSpansByService,
 			Obfuscation:            oconf,
 		},
-	}, "", "\t")
+	}
+	txt, err := json.MarshalIndent(resp, "", "\t")
 	if err != nil {
 		panic(fmt.Errorf("Error making /info handler: %v", err))
 	}
+	// the state hash only covers the static part of the response, the load shedding
+	// stage is added as an extra field when serving it
 	h := sha256.Sum256(txt)
+	stages := make(map[watchdog.Stage][]byte)
+	for stage := watchdog.StageNormal; stage <= watchdog.StageShrinkBuffers; stage++ {
+		resp.LoadShedding = stage.String()
+		if stages[stage], err = json.MarshalIndent(resp, "", "\t"); err != nil {
+			panic(fmt.Errorf("Error making /info handler: %v", err))
+		}
+	}
 	return fmt.Sprintf("%x", h), func(w http.ResponseWriter, _ *http.Request) {
-		fmt.Fprintf(w, "%s", txt)
+		fmt.Fprintf(w, "%s", stages[watchdog.CurrentStage()])
 	}
 }
@@ -6,15 +6,20 @@
 package api
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"log"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // TestInfoHandler ensures that the keys returned by the /info handler do not
@@ -154,10 +159,32 @@ func TestInfoHandler(t *testing.T) {
 			"redis": true,
 			"memcached": false
 		}
-	}
+	},
+	"load_shedding": "normal"
 }` {
 		t.Fatal("Output of /info has changed. Changing the keys "+
 			"is not allowed because the client rely on them and "+
 			"is considered a breaking change:\n\n%f", rec.Body.String())
 	}
 }
+
+func TestInfoHandlerLoadShedding(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	defer resetShedding(r)
+	hash, h := r.makeInfoHandler()
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+		return rec.Body.String()
+	}
+
+	body := get()
+	assert.Contains(t, body, `"load_shedding": "normal"`)
+	// the state hash doesn't include the load shedding stage
+	static := strings.Replace(body, ",\n\t\"load_shedding\": \"normal\"", "", 1)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte(static))), hash)
+
+	r.shedder.Update(1.3, time.Now())
+	assert.Contains(t, get(), `"load_shedding": "reject_payloads"`)
+}
@@ -0,0 +1,475 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// The types below hold the parts of Jaeger's Thrift model (jaeger.thrift) which are used when
+// converting spans. See https://github.com/jaegertracing/jaeger-idl/blob/master/thrift/jaeger.thrift.
+
+// jaegerBatch is a collection of spans reported by a single process.
+type jaegerBatch struct {
+	Process jaegerProcess
+	Spans   []jaegerSpan
+}
+
+// jaegerProcess describes the traced process.
+type jaegerProcess struct {
+	ServiceName string
+	Tags        []jaegerTag
+}
+
+// jaegerSpan is a Jaeger span. Times are in microseconds.
+type jaegerSpan struct {
+	TraceIDLow    int64
+	TraceIDHigh   int64
+	SpanID        int64
+	ParentSpanID  int64
+	OperationName string
+	References    []jaegerSpanRef
+	Flags         int32
+	StartTime     int64
+	Duration      int64
+	Tags          []jaegerTag
+	Logs          []jaegerLog
+}
+
+// jaegerSpanRef is a reference from a span to another one.
+type jaegerSpanRef struct {
+	RefType     int32 // 0: CHILD_OF, 1: FOLLOWS_FROM
+	TraceIDLow  int64
+	TraceIDHigh int64
+	SpanID      int64
+}
+
+// jaegerLog is a timed event with arbitrary fields.
+type jaegerLog struct {
+	Timestamp int64
+	Fields    []jaegerTag
+}
+
+// Jaeger tag value types.
+const (
+	jaegerTagString int32 = iota
+	jaegerTagDouble
+	jaegerTagBool
+	jaegerTagLong
+	jaegerTagBinary
+)
+
+// jaegerTag is a typed key/value pair.
+type jaegerTag struct {
+	Key     string
+	VType   int32
+	VStr    string
+	VDouble float64
+	VBool   bool
+	VLong   int64
+	VBinary []byte
+}
+
+// numeric reports whether the tag holds a number, returning it.
+func (t *jaegerTag) numeric() (float64, bool) {
+	switch t.VType {
+	case jaegerTagDouble:
+		return t.VDouble, true
+	case jaegerTagLong:
+		return float64(t.VLong), true
+	}
+	return 0, false
+}
+
+// String returns the string representation of the tag's value.
+func (t *jaegerTag) String() string {
+	switch t.VType {
+	case jaegerTagDouble:
+		return strconv.FormatFloat(t.VDouble, 'f', -1, 64)
+	case jaegerTagBool:
+		return strconv.FormatBool(t.VBool)
+	case jaegerTagLong:
+		return strconv.FormatInt(t.VLong, 10)
+	case jaegerTagBinary:
+		return base64.StdEncoding.EncodeToString(t.VBinary)
+	}
+	return t.VStr
+}
+
+// jaegerFlagDebug is the span flag marking spans which must be kept.
+const jaegerFlagDebug = 2
+
+// decodeJaeger decodes the Jaeger batch found in the body of req, encoded using the Thrift
+// binary protocol, and converts it into Datadog traces.
+func decodeJaeger(req *http.Request) (pb.Traces, error) {
+	switch mt := getMediaType(req); mt {
+	case "application/x-thrift", "application/vnd.apache.thrift.binary":
+	default:
+		return nil, fmt.Errorf("unsupported media type: %q", mt)
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var batch jaegerBatch
+	if err := unmarshalJaegerBatch(body, &batch); err != nil {
+		return nil, err
+	}
+	spans := make([]*pb.Span, 0, len(batch.Spans))
+	for i := range batch.Spans {
+		spans = append(spans, convertJaegerSpan(&batch.Process, &batch.Spans[i]))
+	}
+	return groupByTraceID(spans), nil
+}
+
+// convertJaegerSpan converts the Jaeger span in, reported by the process p, into a Datadog span.
+// Process tags and string or boolean span tags become span meta, while numeric span tags become
+// span metrics. Logs are stored as JSON in the "events" tag.
+func convertJaegerSpan(p *jaegerProcess, in *jaegerSpan) *pb.Span {
+	span := &pb.Span{
+		TraceID:  uint64(in.TraceIDLow),
+		SpanID:   uint64(in.SpanID),
+		ParentID: uint64(in.ParentSpanID),
+		Start:    in.StartTime * 1000,
+		Duration: in.Duration * 1000,
+		Service:  p.ServiceName,
+		Resource: in.OperationName,
+		Meta:     make(map[string]string, len(p.Tags)+len(in.Tags)),
+		Metrics:  make(map[string]float64, 1),
+	}
+	if span.ParentID == 0 {
+		for _, ref := range in.References {
+			if ref.RefType == 0 && ref.TraceIDLow == in.TraceIDLow {
+				span.ParentID = uint64(ref.SpanID)
+				break
+			}
+		}
+	}
+	for i := range p.Tags {
+		span.Meta[p.Tags[i].Key] = p.Tags[i].String()
+	}
+	for i := range in.Tags {
+		tag := &in.Tags[i]
+		if v, ok := tag.numeric(); ok {
+			span.Metrics[tag.Key] = v
+			continue
+		}
+		span.Meta[tag.Key] = tag.String()
+	}
+	if len(in.Logs) > 0 {
+		events := make([]spanEvent, len(in.Logs))
+		for i, l := range in.Logs {
+			e := spanEvent{
+				TimeUnixNano: uint64(l.Timestamp) * 1000,
+				Attributes:   make(map[string]string, len(l.Fields)),
+			}
+			for j := range l.Fields {
+				if l.Fields[j].Key == "event" {
+					e.Name = l.Fields[j].String()
+					continue
+				}
+				e.Attributes[l.Fields[j].Key] = l.Fields[j].String()
+			}
+			if e.Name == "error" {
+				setMetaIfEmpty(span, "error.msg", e.Attributes["message"])
+				setMetaIfEmpty(span, "error.msg", e.Attributes["error.object"])
+				setMetaIfEmpty(span, "error.type", e.Attributes["error.kind"])
+				setMetaIfEmpty(span, "error.stack", e.Attributes["stack"])
+			}
+			events[i] = e
+		}
+		span.Meta["events"] = marshalSpanEvents(events)
+	}
+	finishConvertedSpan(span, "jaeger", spanKindFromName(span.Meta["span.kind"]), in.Flags&jaegerFlagDebug != 0)
+	return span
+}
+
+// Thrift binary protocol field types.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// errThriftShort is returned when a Thrift message ends unexpectedly.
+var errThriftShort = errors.New("thrift: unexpected end of message")
+
+// maxThriftDepth specifies the maximum nesting depth of skipped Thrift values.
+const maxThriftDepth = 32
+
+// thriftReader reads values encoded using the Thrift binary protocol. The first error
+// encountered is kept in err, after which all reads return zero values.
+type thriftReader struct {
+	b   []byte
+	err error
+}
+
+func (r *thriftReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = errThriftShort
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) byte() byte {
+	if v := r.next(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (r *thriftReader) i16() int16 {
+	if v := r.next(2); v != nil {
+		return int16(binary.BigEndian.Uint16(v))
+	}
+	return 0
+}
+
+func (r *thriftReader) i32() int32 {
+	if v := r.next(4); v != nil {
+		return int32(binary.BigEndian.Uint32(v))
+	}
+	return 0
+}
+
+func (r *thriftReader) i64() int64 {
+	if v := r.next(8); v != nil {
+		return int64(binary.BigEndian.Uint64(v))
+	}
+	return 0
+}
+
+func (r *thriftReader) double() float64 { return math.Float64frombits(uint64(r.i64())) }
+
+func (r *thriftReader) binary() []byte { return r.next(int(r.i32())) }
+
+func (r *thriftReader) string() string { return string(r.binary()) }
+
+// list reads a list header and calls fn for each of its elements, provided that
+// they are of the type typ. Lists of other types are skipped.
+func (r *thriftReader) list(typ byte, fn func()) {
+	etyp := r.byte()
+	n := int(r.i32())
+	if r.err == nil && (n < 0 || n > len(r.b)) {
+		// each element takes at least one byte
+		r.err = errThriftShort
+	}
+	for i := 0; i < n && r.err == nil; i++ {
+		if etyp != typ {
+			r.skip(etyp, 0)
+			continue
+		}
+		fn()
+	}
+}
+
+// fields reads a struct, calling fn for each of its fields. fn must either read
+// the field's value or return false, in which case the value is skipped.
+func (r *thriftReader) fields(fn func(id int16, typ byte) bool) {
+	for r.err == nil {
+		typ := r.byte()
+		if typ == thriftStop {
+			return
+		}
+		id := r.i16()
+		if r.err != nil || !fn(id, typ) {
+			r.skip(typ, 0)
+		}
+	}
+}
+
+// skip skips a value of the given type.
+func (r *thriftReader) skip(typ byte, depth int) {
+	if depth > maxThriftDepth {
+		r.err = errors.New("thrift: maximum depth exceeded")
+		return
+	}
+	switch typ {
+	case thriftBool, thriftByte:
+		r.next(1)
+	case thriftI16:
+		r.next(2)
+	case thriftI32:
+		r.next(4)
+	case thriftDouble, thriftI64:
+		r.next(8)
+	case thriftString:
+		r.binary()
+	case thriftStruct:
+		for r.err == nil {
+			ftyp := r.byte()
+			if ftyp == thriftStop {
+				return
+			}
+			r.i16()
+			r.skip(ftyp, depth+1)
+		}
+	case thriftMap:
+		ktyp, vtyp, n := r.byte(), r.byte(), int(r.i32())
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip(ktyp, depth+1)
+			r.skip(vtyp, depth+1)
+		}
+	case thriftSet, thriftList:
+		etyp, n := r.byte(), int(r.i32())
+		for i := 0; i < n && r.err == nil; i++ {
+			r.skip(etyp, depth+1)
+		}
+	default:
+		r.err = fmt.Errorf("thrift: unknown type %d", typ)
+	}
+}
+
+// unmarshalJaegerBatch decodes the Thrift binary encoded Jaeger batch b into batch.
+func unmarshalJaegerBatch(b []byte, batch *jaegerBatch) error {
+	r := &thriftReader{b: b}
+	r.fields(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			r.fields(func(id int16, typ byte) bool {
+				switch {
+				case id == 1 && typ == thriftString:
+					batch.Process.ServiceName = r.string()
+				case id == 2 && typ == thriftList:
+					batch.Process.Tags = r.tags()
+				default:
+					return false
+				}
+				return true
+			})
+		case id == 2 && typ == thriftList:
+			r.list(thriftStruct, func() {
+				var span jaegerSpan
+				r.span(&span)
+				batch.Spans = append(batch.Spans, span)
+			})
+		default:
+			return false
+		}
+		return true
+	})
+	return r.err
+}
+
+// span reads a Jaeger span into span.
+func (r *thriftReader) span(span *jaegerSpan) {
+	r.fields(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftI64:
+			span.TraceIDLow = r.i64()
+		case id == 2 && typ == thriftI64:
+			span.TraceIDHigh = r.i64()
+		case id == 3 && typ == thriftI64:
+			span.SpanID = r.i64()
+		case id == 4 && typ == thriftI64:
+			span.ParentSpanID = r.i64()
+		case id == 5 && typ == thriftString:
+			span.OperationName = r.string()
+		case id == 6 && typ == thriftList:
+			r.list(thriftStruct, func() {
+				var ref jaegerSpanRef
+				r.fields(func(id int16, typ byte) bool {
+					switch {
+					case id == 1 && typ == thriftI32:
+						ref.RefType = r.i32()
+					case id == 2 && typ == thriftI64:
+						ref.TraceIDLow = r.i64()
+					case id == 3 && typ == thriftI64:
+						ref.TraceIDHigh = r.i64()
+					case id == 4 && typ == thriftI64:
+						ref.SpanID = r.i64()
+					default:
+						return false
+					}
+					return true
+				})
+				span.References = append(span.References, ref)
+			})
+		case id == 7 && typ == thriftI32:
+			span.Flags = r.i32()
+		case id == 8 && typ == thriftI64:
+			span.StartTime = r.i64()
+		case id == 9 && typ == thriftI64:
+			span.Duration = r.i64()
+		case id == 10 && typ == thriftList:
+			span.Tags = r.tags()
+		case id == 11 && typ == thriftList:
+			r.list(thriftStruct, func() {
+				var l jaegerLog
+				r.fields(func(id int16, typ byte) bool {
+					switch {
+					case id == 1 && typ == thriftI64:
+						l.Timestamp = r.i64()
+					case id == 2 && typ == thriftList:
+						l.Fields = r.tags()
+					default:
+						return false
+					}
+					return true
+				})
+				span.Logs = append(span.Logs, l)
+			})
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// tags reads a list of Jaeger tags.
+func (r *thriftReader) tags() []jaegerTag {
+	var tags []jaegerTag
+	r.list(thriftStruct, func() {
+		var t jaegerTag
+		r.fields(func(id int16, typ byte) bool {
+			switch {
+			case id == 1 && typ == thriftString:
+				t.Key = r.string()
+			case id == 2 && typ == thriftI32:
+				t.VType = r.i32()
+			case id == 3 && typ == thriftString:
+				t.VStr = r.string()
+			case id == 4 && typ == thriftDouble:
+				t.VDouble = r.double()
+			case id == 5 && typ == thriftBool:
+				t.VBool = r.byte() != 0
+			case id == 6 && typ == thriftI64:
+				t.VLong = r.i64()
+			case id == 7 && typ == thriftString:
+				t.VBinary = r.binary()
+			default:
+				return false
+			}
+			return true
+		})
+		tags = append(tags, t)
+	})
+	return tags
+}
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thriftWriter encodes values using the Thrift binary protocol.
+type thriftWriter struct{ bytes.Buffer }
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id)
+}
+
+func (w *thriftWriter) stop() { w.WriteByte(thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v)
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v)))
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, typ byte, n int) {
+	w.field(thriftList, id)
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, int32(n))
+}
+
+func (w *thriftWriter) tags(id int16, tags ...jaegerTag) {
+	w.list(id, thriftStruct, len(tags))
+	for _, t := range tags {
+		w.str(1, t.Key)
+		w.i32(2, t.VType)
+		switch t.VType {
+		case jaegerTagString:
+			w.str(3, t.VStr)
+		case jaegerTagDouble:
+			w.field(thriftDouble, 4)
+			binary.Write(w, binary.BigEndian, math.Float64bits(t.VDouble))
+		case jaegerTagBool:
+			w.field(thriftBool, 5)
+			if t.VBool {
+				w.WriteByte(1)
+			} else {
+				w.WriteByte(0)
+			}
+		case jaegerTagLong:
+			w.i64(6, t.VLong)
+		}
+		w.stop()
+	}
+}
+
+func strTag(k, v string) jaegerTag { return jaegerTag{Key: k, VType: jaegerTagString, VStr: v} }
+
+// jaegerTestPayload returns a Thrift encoded Jaeger batch holding two spans of the same trace.
+func jaegerTestPayload() []byte {
+	var w thriftWriter
+	// process
+	w.field(thriftStruct, 1)
+	w.str(1, "frontend")
+	w.tags(2, strTag("hostname", "host-a"))
+	w.stop()
+
+	w.list(2, thriftStruct, 2)
+	// server span
+	w.i64(1, 1)
+	w.i64(2, 0)
+	w.i64(3, 2)
+	w.i64(4, 0)
+	w.str(5, "get /api")
+	w.i32(7, 1)
+	w.i64(8, 1472470996199000)
+	w.i64(9, 207000)
+	w.tags(10,
+		strTag("span.kind", "server"),
+		strTag("http.method", "GET"),
+		jaegerTag{Key: "http.status_code", VType: jaegerTagLong, VLong: 200},
+		jaegerTag{Key: "sampler.param", VType: jaegerTagDouble, VDouble: 0.5},
+	)
+	w.field(thriftMap, 12) // unknown field, skipped
+	w.WriteByte(thriftString)
+	w.WriteByte(thriftI32)
+	binary.Write(&w, binary.BigEndian, int32(0))
+	w.stop()
+	// client span, with its parent set as a reference
+	w.i64(1, 1)
+	w.i64(3, 3)
+	w.str(5, "query")
+	w.list(6, thriftStruct, 1)
+	w.i32(1, 0)
+	w.i64(2, 1)
+	w.i64(3, 0)
+	w.i64(4, 2)
+	w.stop()
+	w.i32(7, 3)
+	w.i64(8, 1472470996238000)
+	w.i64(9, 100)
+	w.tags(10,
+		strTag("span.kind", "client"),
+		strTag("db.system", "redis"),
+		jaegerTag{Key: "error", VType: jaegerTagBool, VBool: true},
+	)
+	w.list(11, thriftStruct, 1)
+	w.i64(1, 1472470996238001)
+	w.tags(2, strTag("event", "error"), strTag("error.kind", "IOError"), strTag("message", "connection reset"))
+	w.stop()
+	w.stop()
+
+	w.stop()
+	return w.Bytes()
+}
+
+func TestConvertJaeger(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/traces", bytes.NewReader(jaegerTestPayload()))
+	req.Header.Set("Content-Type", "application/x-thrift")
+	traces, err := decodeJaeger(req)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0], 2)
+
+	assert.Equal(t, &pb.Span{
+		Service:  "frontend",
+		Name:     "jaeger.server",
+		Resource: "GET",
+		TraceID:  1,
+		SpanID:   2,
+		Start:    1472470996199000000,
+		Duration: 207000000,
+		Type:     "web",
+		Meta: map[string]string{
+			"hostname":    "host-a",
+			"span.kind":   "server",
+			"http.method": "GET",
+		},
+		Metrics: map[string]float64{
+			"http.status_code":          200,
+			"sampler.param":             0.5,
+			sampler.KeySamplingPriority: float64(sampler.PriorityAutoKeep),
+		},
+	}, traces[0][0])
+	assert.Equal(t, &pb.Span{
+		Service:  "frontend",
+		Name:     "jaeger.client",
+		Resource: "query",
+		TraceID:  1,
+		SpanID:   3,
+		ParentID: 2,
+		Start:    1472470996238000000,
+		Duration: 100000,
+		Error:    1,
+		Type:     "cache",
+		Meta: map[string]string{
+			"hostname":   "host-a",
+			"span.kind":  "client",
+			"db.system":  "redis",
+			"error.msg":  "connection reset",
+			"error.type": "IOError",
+			"events":     `[{"time_unix_nano":1472470996238001000,"name":"error","attributes":{"error.kind":"IOError","message":"connection reset"}}]`,
+		},
+		Metrics: map[string]float64{sampler.KeySamplingPriority: float64(sampler.PriorityUserKeep)},
+	}, traces[0][1])
+
+	t.Run("invalid", func(t *testing.T) {
+		payload := jaegerTestPayload()
+		for name, tt := range map[string]struct {
+			contentType string
+			body        []byte
+		}{
+			"media-type": {"application/json", payload},
+			"truncated":  {"application/x-thrift", payload[:len(payload)/2]},
+			"list-size":  {"application/x-thrift", []byte{thriftList, 0, 2, thriftStruct, 0x7f, 0xff, 0xff, 0xff}},
+			"type":       {"application/x-thrift", []byte{1, 0, 2}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/api/traces", bytes.NewReader(tt.body))
+				req.Header.Set("Content-Type", tt.contentType)
+				_, err := decodeJaeger(req)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestJaegerEndpoint(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(r.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/traces?format=jaeger.thrift", "application/x-thrift", bytes.NewReader(jaegerTestPayload()))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case p := <-r.out:
+		assert.Len(t, p.Traces, 1)
+		assert.Equal(t, "jaeger_thrift", p.Source.EndpointVersion)
+	case <-time.After(time.Second):
+		t.Fatal("no payload received")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/capture"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/config/features"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
@@ -42,11 +43,16 @@ const (
 	otlpProtocolHTTP = "http"
 	// otlpProtocolGRPC specifies that the incoming connection was made over gRPC.
 	otlpProtocolGRPC = "grpc"
+	// otlpHTTPPath specifies the path used by OTLP exporters for traces over plain HTTP.
+	otlpHTTPPath = "/v1/traces"
 )
 
 // OTLPReceiver implements an OpenTelemetry Collector receiver which accepts incoming
 // data on two ports for both plain HTTP and gRPC.
 type OTLPReceiver struct {
+	// Capture records incoming payloads while a capture is in progress. It may be nil.
+	Capture *capture.Recorder
+
 	wg      sync.WaitGroup  // waits for a graceful shutdown
 	httpsrv *http.Server    // the running HTTP server on a started receiver, if enabled
 	grpcsrv *grpc.Server    // the running GRPC server on a started receiver, if enabled
@@ -119,6 +125,17 @@ func (o *OTLPReceiver) Export(ctx context.Context, in *otlppb.ExportTraceService
 	defer timing.Since("datadog.trace_agent.otlp.process_grpc_request_ms", time.Now())
 	md, _ := metadata.FromIncomingContext(ctx)
 	metrics.Count("datadog.trace_agent.otlp.payload", 1, tagsFromHeaders(http.Header(md), otlpProtocolGRPC), 1)
+	if _, ok := o.Capture.Active(); ok {
+		// gRPC requests are recorded as their plain HTTP equivalent, so that they can be replayed
+		if slurp, err := proto.Marshal(in); err == nil {
+			h := make(http.Header, len(md)+1)
+			for k, v := range md {
+				h[http.CanonicalHeaderKey(k)] = v
+			}
+			h.Set("Content-Type", "application/x-protobuf")
+			o.Capture.Record(capture.ProtocolOTLP, otlpHTTPPath, h, slurp)
+		}
+	}
 	o.processRequest(otlpProtocolGRPC, http.Header(md), in)
 	return &otlppb.ExportTraceServiceResponse{}, nil
 }
@@ -147,6 +164,12 @@ func (o *OTLPReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	metrics.Count("datadog.trace_agent.otlp.bytes", int64(len(slurp)), mtags, 1)
+	if _, ok := o.Capture.Active(); ok {
+		// the body is recorded decompressed
+		h := req.Header.Clone()
+		h.Del("Content-Encoding")
+		o.Capture.Record(capture.ProtocolOTLP, req.URL.Path, h, slurp)
+	}
 	var in otlppb.ExportTraceServiceRequest
 	switch getMediaType(req) {
 	case "application/x-protobuf":
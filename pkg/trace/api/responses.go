@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -68,12 +69,22 @@ func httpOK(w http.ResponseWriter) {
 	io.WriteString(w, "OK\n")
 }
 
+// shedSamplingFactor specifies the factor applied to the sampling rates sent to tracers
+// while the trace-agent is shedding load.
+const shedSamplingFactor = 0.5
+
 // httpRateByService outputs, as a JSON, the recommended sampling rates for all services.
 func httpRateByService(w http.ResponseWriter, dynConf *sampler.DynamicConfig) {
 	w.Header().Set("Content-Type", "application/json")
 	response := traceResponse{
 		Rates: dynConf.RateByService.GetAll(), // this is thread-safe
 	}
+	if watchdog.CurrentStage() >= watchdog.StageReduceSampling {
+		// ask tracers to send less traces until resource usage gets back to normal
+		for k, v := range response.Rates {
+			response.Rates[k] = v * shedSamplingFactor
+		}
+	}
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
 		tags := []string{"error:response-error"}
@@ -69,4 +69,12 @@ const (
 	//
 	v05 Version = "v0.5"
 	v06 Version = "v0.6"
+
+	// zipkinV2 is the version of the endpoint receiving Zipkin v2 spans, encoded either
+	// as JSON or as protobuf (zipkin.proto3 ListOfSpans).
+	zipkinV2 Version = "zipkin_v2"
+
+	// jaegerThrift is the version of the endpoint receiving Jaeger batches encoded using
+	// the Thrift binary protocol, as sent over HTTP by Jaeger clients.
+	jaegerThrift Version = "jaeger_thrift"
 )
@@ -0,0 +1,328 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api/apiutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// zipkinSpan is a Zipkin v2 span, as defined by https://zipkin.io/zipkin-api/#/default/post_spans.
+// Spans decoded from protobuf (zipkin.proto3) are converted into this representation.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ParentID       string             `json:"parentId,omitempty"`
+	ID             string             `json:"id"`
+	Kind           string             `json:"kind,omitempty"`
+	Name           string             `json:"name,omitempty"`
+	Timestamp      uint64             `json:"timestamp,omitempty"` // microseconds
+	Duration       uint64             `json:"duration,omitempty"`  // microseconds
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint,omitempty"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint,omitempty"`
+	Annotations    []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags           map[string]string  `json:"tags,omitempty"`
+	Debug          bool               `json:"debug,omitempty"`
+}
+
+// zipkinEndpoint is the network context of a Zipkin span.
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        int    `json:"port,omitempty"`
+}
+
+// zipkinAnnotation is a Zipkin span annotation, associating an event with a timestamp.
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // microseconds
+	Value     string `json:"value"`
+}
+
+// decodeZipkin decodes the Zipkin v2 spans found in the body of req, encoded either as a JSON list
+// of spans or as a protobuf ListOfSpans, and converts them into Datadog traces. Compressed bodies
+// are limited to maxBytes once decompressed.
+func decodeZipkin(req *http.Request, maxBytes int64) (pb.Traces, error) {
+	var r io.Reader = req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipr.Close()
+		// the receiver only limits the size of the compressed body
+		r = apiutil.NewLimitedReader(gzipr, maxBytes)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var spans []zipkinSpan
+	switch getMediaType(req) {
+	case "application/x-protobuf":
+		if spans, err = unmarshalZipkinProto(body); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(body, &spans); err != nil {
+			return nil, err
+		}
+	}
+	converted := make([]*pb.Span, 0, len(spans))
+	for i := range spans {
+		span, err := convertZipkinSpan(&spans[i])
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, span)
+	}
+	return groupByTraceID(converted), nil
+}
+
+// convertZipkinSpan converts the Zipkin span in into a Datadog span. The remote endpoint is mapped to the
+// "peer.service", "out.host" and "out.port" tags and annotations are stored as JSON in the "events" tag.
+func convertZipkinSpan(in *zipkinSpan) (*pb.Span, error) {
+	traceID, err := parseZipkinID(in.TraceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trace ID %q: %v", in.TraceID, err)
+	}
+	spanID, err := parseZipkinID(in.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %v", in.ID, err)
+	}
+	var parentID uint64
+	if in.ParentID != "" {
+		if parentID, err = parseZipkinID(in.ParentID); err != nil {
+			return nil, fmt.Errorf("invalid parent ID %q: %v", in.ParentID, err)
+		}
+	}
+	span := &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Start:    int64(in.Timestamp) * 1000,
+		Duration: int64(in.Duration) * 1000,
+		Resource: in.Name,
+		Meta:     make(map[string]string, len(in.Tags)+4),
+		Metrics:  make(map[string]float64, 1),
+	}
+	for k, v := range in.Tags {
+		span.Meta[k] = v
+	}
+	if in.LocalEndpoint != nil {
+		span.Service = in.LocalEndpoint.ServiceName
+	}
+	if e := in.RemoteEndpoint; e != nil {
+		host := e.IPv4
+		if host == "" {
+			host = e.IPv6
+		}
+		setMetaIfEmpty(span, "peer.service", e.ServiceName)
+		setMetaIfEmpty(span, "out.host", host)
+		if e.Port != 0 {
+			setMetaIfEmpty(span, "out.port", strconv.Itoa(e.Port))
+		}
+	}
+	if in.Kind != "" {
+		span.Meta["span.kind"] = strings.ToLower(in.Kind)
+	}
+	if len(in.Annotations) > 0 {
+		events := make([]spanEvent, len(in.Annotations))
+		for i, a := range in.Annotations {
+			events[i] = spanEvent{TimeUnixNano: a.Timestamp * 1000, Name: a.Value}
+		}
+		span.Meta["events"] = marshalSpanEvents(events)
+	}
+	finishConvertedSpan(span, "zipkin", spanKindFromName(in.Kind), in.Debug)
+	return span, nil
+}
+
+// parseZipkinID parses the given 64 or 128-bit hex-encoded Zipkin ID. Only the lower 64 bits of
+// 128-bit IDs are kept.
+func parseZipkinID(id string) (uint64, error) {
+	if len(id) > 16 {
+		id = id[len(id)-16:]
+	}
+	return strconv.ParseUint(id, 16, 64)
+}
+
+// setMetaIfEmpty sets the tag k to v on span, unless v is empty or the tag is already set.
+func setMetaIfEmpty(span *pb.Span, k, v string) {
+	if v == "" {
+		return
+	}
+	if _, ok := span.Meta[k]; !ok {
+		span.Meta[k] = v
+	}
+}
+
+// zipkinProtoKinds maps the values of zipkin.proto3's Span.Kind enum to Zipkin's JSON kinds.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+// unmarshalZipkinProto decodes the protobuf encoded zipkin.proto3 ListOfSpans b.
+func unmarshalZipkinProto(b []byte) ([]zipkinSpan, error) {
+	var spans []zipkinSpan
+	err := consumeProtoFields(b, func(num protowire.Number, _ uint64, v []byte) error {
+		if num != 1 { // ListOfSpans.spans
+			return nil
+		}
+		var span zipkinSpan
+		if err := unmarshalZipkinProtoSpan(v, &span); err != nil {
+			return err
+		}
+		spans = append(spans, span)
+		return nil
+	})
+	return spans, err
+}
+
+// unmarshalZipkinProtoSpan decodes the protobuf encoded zipkin.proto3 Span b into span.
+func unmarshalZipkinProtoSpan(b []byte, span *zipkinSpan) error {
+	return consumeProtoFields(b, func(num protowire.Number, x uint64, v []byte) error {
+		switch num {
+		case 1:
+			span.TraceID = hex.EncodeToString(v)
+		case 2:
+			span.ParentID = hex.EncodeToString(v)
+		case 3:
+			span.ID = hex.EncodeToString(v)
+		case 4:
+			span.Kind = zipkinProtoKinds[x]
+		case 5:
+			span.Name = string(v)
+		case 6:
+			span.Timestamp = x
+		case 7:
+			span.Duration = x
+		case 8, 9:
+			e := new(zipkinEndpoint)
+			if err := unmarshalZipkinProtoEndpoint(v, e); err != nil {
+				return err
+			}
+			if num == 8 {
+				span.LocalEndpoint = e
+			} else {
+				span.RemoteEndpoint = e
+			}
+		case 10:
+			var a zipkinAnnotation
+			err := consumeProtoFields(v, func(num protowire.Number, x uint64, v []byte) error {
+				switch num {
+				case 1:
+					a.Timestamp = x
+				case 2:
+					a.Value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			span.Annotations = append(span.Annotations, a)
+		case 11:
+			var k, val string
+			err := consumeProtoFields(v, func(num protowire.Number, _ uint64, v []byte) error {
+				switch num {
+				case 1:
+					k = string(v)
+				case 2:
+					val = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if span.Tags == nil {
+				span.Tags = make(map[string]string)
+			}
+			span.Tags[k] = val
+		case 12:
+			span.Debug = x != 0
+		}
+		return nil
+	})
+}
+
+// unmarshalZipkinProtoEndpoint decodes the protobuf encoded zipkin.proto3 Endpoint b into e.
+func unmarshalZipkinProtoEndpoint(b []byte, e *zipkinEndpoint) error {
+	return consumeProtoFields(b, func(num protowire.Number, x uint64, v []byte) error {
+		switch num {
+		case 1:
+			e.ServiceName = string(v)
+		case 2:
+			if len(v) == net.IPv4len {
+				e.IPv4 = net.IP(v).String()
+			}
+		case 3:
+			if len(v) == net.IPv6len {
+				e.IPv6 = net.IP(v).String()
+			}
+		case 4:
+			e.Port = int(x)
+		}
+		return nil
+	})
+}
+
+// consumeProtoFields calls fn for each field of the protobuf encoded message b. The value of
+// varint and fixed-size fields is passed as x, while the value of length-delimited fields is
+// passed as v. Groups are skipped.
+func consumeProtoFields(b []byte, fn func(num protowire.Number, x uint64, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var (
+			x uint64
+			v []byte
+		)
+		switch typ {
+		case protowire.VarintType:
+			x, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			x, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var x32 uint32
+			x32, n = protowire.ConsumeFixed32(b)
+			x = uint64(x32)
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.StartGroupType {
+			continue
+		}
+		if err := fn(num, x, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
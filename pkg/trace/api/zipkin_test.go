@@ -0,0 +1,274 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const zipkinJSONPayload = `[
+  {
+    "traceId": "5af7183fb1d4cf5f0000000000000001",
+    "id": "0000000000000002",
+    "kind": "SERVER",
+    "name": "get /api",
+    "timestamp": 1472470996199000,
+    "duration": 207000,
+    "localEndpoint": {"serviceName": "frontend", "ipv4": "127.0.0.1"},
+    "tags": {"http.method": "GET", "http.route": "/api", "deployment.environment": "prod"}
+  },
+  {
+    "traceId": "0000000000000001",
+    "parentId": "0000000000000002",
+    "id": "0000000000000003",
+    "kind": "CLIENT",
+    "name": "query",
+    "timestamp": 1472470996238000,
+    "duration": 100,
+    "localEndpoint": {"serviceName": "frontend"},
+    "remoteEndpoint": {"serviceName": "postgres", "ipv4": "10.0.0.1", "port": 5432},
+    "annotations": [{"timestamp": 1472470996238001, "value": "ws"}],
+    "tags": {"db.system": "postgresql", "error": "connection reset"},
+    "debug": true
+  },
+  {
+    "traceId": "0000000000000004",
+    "id": "0000000000000005",
+    "name": "work",
+    "localEndpoint": {"serviceName": "worker"},
+    "tags": {"error": "false"}
+  }
+]`
+
+// maxTestBytes limits the size of the decompressed payloads in tests.
+const maxTestBytes = 10 * 1024 * 1024
+
+func TestConvertZipkin(t *testing.T) {
+	assertZipkinTraces := func(t *testing.T, traces pb.Traces) {
+		require.Len(t, traces, 2)
+		require.Len(t, traces[0], 2)
+		require.Len(t, traces[1], 1)
+
+		server, client, worker := traces[0][0], traces[0][1], traces[1][0]
+		assert.Equal(t, &pb.Span{
+			Service:  "frontend",
+			Name:     "zipkin.server",
+			Resource: "GET /api",
+			TraceID:  1,
+			SpanID:   2,
+			Start:    1472470996199000000,
+			Duration: 207000000,
+			Type:     "web",
+			Meta: map[string]string{
+				"http.method":            "GET",
+				"http.route":             "/api",
+				"deployment.environment": "prod",
+				"env":                    "prod",
+				"span.kind":              "server",
+			},
+			Metrics: map[string]float64{sampler.KeySamplingPriority: float64(sampler.PriorityAutoKeep)},
+		}, server)
+		assert.Equal(t, &pb.Span{
+			Service:  "frontend",
+			Name:     "zipkin.client",
+			Resource: "query",
+			TraceID:  1,
+			SpanID:   3,
+			ParentID: 2,
+			Start:    1472470996238000000,
+			Duration: 100000,
+			Error:    1,
+			Type:     "db",
+			Meta: map[string]string{
+				"db.system":    "postgresql",
+				"error.msg":    "connection reset",
+				"peer.service": "postgres",
+				"out.host":     "10.0.0.1",
+				"out.port":     "5432",
+				"span.kind":    "client",
+				"events":       `[{"time_unix_nano":1472470996238001000,"name":"ws"}]`,
+			},
+			Metrics: map[string]float64{sampler.KeySamplingPriority: float64(sampler.PriorityUserKeep)},
+		}, client)
+		assert.Equal(t, "zipkin.unspecified", worker.Name)
+		assert.Equal(t, "custom", worker.Type)
+		assert.Equal(t, int32(0), worker.Error)
+		assert.NotContains(t, worker.Meta, "error")
+	}
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewBufferString(zipkinJSONPayload))
+		req.Header.Set("Content-Type", "application/json")
+		traces, err := decodeZipkin(req, maxTestBytes)
+		require.NoError(t, err)
+		assertZipkinTraces(t, traces)
+	})
+
+	t.Run("json-gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(zipkinJSONPayload))
+		gz.Close()
+		req := httptest.NewRequest("POST", "/api/v2/spans", &buf)
+		req.Header.Set("Content-Encoding", "gzip")
+		traces, err := decodeZipkin(req, maxTestBytes)
+		require.NoError(t, err)
+		assertZipkinTraces(t, traces)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		id := func(v byte, n int) []byte {
+			b := make([]byte, n)
+			b[n-1] = v
+			return b
+		}
+		bytesField := func(b []byte, num protowire.Number, v []byte) []byte {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			return protowire.AppendBytes(b, v)
+		}
+		varintField := func(b []byte, num protowire.Number, v uint64) []byte {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			return protowire.AppendVarint(b, v)
+		}
+		fixedField := func(b []byte, num protowire.Number, v uint64) []byte {
+			b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+			return protowire.AppendFixed64(b, v)
+		}
+		tag := func(b []byte, k, v string) []byte {
+			return bytesField(b, 11, bytesField(bytesField(nil, 1, []byte(k)), 2, []byte(v)))
+		}
+		localEndpoint := func(b []byte, svc string) []byte {
+			return bytesField(b, 8, bytesField(nil, 1, []byte(svc)))
+		}
+
+		var s1 []byte
+		s1 = bytesField(s1, 1, append([]byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f}, id(1, 8)...))
+		s1 = bytesField(s1, 3, id(2, 8))
+		s1 = varintField(s1, 4, 2)
+		s1 = bytesField(s1, 5, []byte("get /api"))
+		s1 = fixedField(s1, 6, 1472470996199000)
+		s1 = varintField(s1, 7, 207000)
+		s1 = localEndpoint(s1, "frontend")
+		s1 = tag(s1, "http.method", "GET")
+		s1 = tag(s1, "http.route", "/api")
+		s1 = tag(s1, "deployment.environment", "prod")
+
+		var s2 []byte
+		s2 = bytesField(s2, 1, id(1, 8))
+		s2 = bytesField(s2, 2, id(2, 8))
+		s2 = bytesField(s2, 3, id(3, 8))
+		s2 = varintField(s2, 4, 1)
+		s2 = bytesField(s2, 5, []byte("query"))
+		s2 = fixedField(s2, 6, 1472470996238000)
+		s2 = varintField(s2, 7, 100)
+		s2 = localEndpoint(s2, "frontend")
+		var remote []byte
+		remote = bytesField(remote, 1, []byte("postgres"))
+		remote = bytesField(remote, 2, []byte{10, 0, 0, 1})
+		remote = varintField(remote, 4, 5432)
+		s2 = bytesField(s2, 9, remote)
+		s2 = bytesField(s2, 10, bytesField(fixedField(nil, 1, 1472470996238001), 2, []byte("ws")))
+		s2 = tag(s2, "db.system", "postgresql")
+		s2 = tag(s2, "error", "connection reset")
+		s2 = varintField(s2, 12, 1)
+
+		var s3 []byte
+		s3 = bytesField(s3, 1, id(4, 16))
+		s3 = bytesField(s3, 3, id(5, 8))
+		s3 = bytesField(s3, 5, []byte("work"))
+		s3 = localEndpoint(s3, "worker")
+		s3 = tag(s3, "error", "false")
+
+		var payload []byte
+		for _, s := range [][]byte{s1, s2, s3} {
+			payload = bytesField(payload, 1, s)
+		}
+		req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		traces, err := decodeZipkin(req, maxTestBytes)
+		require.NoError(t, err)
+		assertZipkinTraces(t, traces)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			contentType, body string
+		}{
+			"json":  {"application/json", `{"traceId": 1}`},
+			"id":    {"application/json", `[{"traceId": "xyz", "id": "1"}]`},
+			"proto": {"application/x-protobuf", "\x0a\xff"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewBufferString(tt.body))
+				req.Header.Set("Content-Type", tt.contentType)
+				_, err := decodeZipkin(req, maxTestBytes)
+				assert.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestZipkinGzipLimit(t *testing.T) {
+	conf := newTestReceiverConfig()
+	conf.MaxRequestBytes = 1024
+	r := newTestReceiverFromConfig(conf)
+	server := httptest.NewServer(r.buildMux())
+	defer server.Close()
+
+	// a highly compressible payload, much larger than the limit once decompressed
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("[" + strings.Repeat(" ", 100*1024) + "]"))
+	gz.Close()
+	require.True(t, int64(buf.Len()) < conf.MaxRequestBytes)
+
+	req, err := http.NewRequest("POST", server.URL+"/api/v2/spans", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestZipkinEndpoint(t *testing.T) {
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(r.buildMux())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/v2/spans", "application/json", bytes.NewBufferString(zipkinJSONPayload))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	select {
+	case p := <-r.out:
+		assert.Len(t, p.Traces, 2)
+		assert.Equal(t, "zipkin_v2", p.Source.EndpointVersion)
+		assert.EqualValues(t, 2, p.Source.TracesReceived)
+	case <-time.After(time.Second):
+		t.Fatal("no payload received")
+	}
+
+	resp, err = http.Post(server.URL+"/api/v2/spans", "application/json", bytes.NewBufferString("{"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
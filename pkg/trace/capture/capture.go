@@ -0,0 +1,305 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package capture implements recording of the raw payloads received by the trace-agent
+// into capture files, along with the ability to replay them into a running agent. It is
+// intended for reproducing sampling, normalization and obfuscation issues offline.
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// FormatVersion specifies the version of the capture file format.
+const FormatVersion = 1
+
+const (
+	// ProtocolDatadog marks records received on the Datadog trace API (e.g. /v0.4/traces).
+	ProtocolDatadog = "datadog"
+	// ProtocolOTLP marks records received by the OpenTelemetry receiver. Records received
+	// over gRPC are stored as their HTTP protobuf equivalent.
+	ProtocolOTLP = "otlp"
+)
+
+// sensitiveHeaders lists the headers which are never written into capture files, as they
+// may hold credentials.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Dd-Api-Key",
+	"Dd-Application-Key",
+	"X-Api-Key",
+}
+
+// redactHeader returns a copy of h without the sensitive headers.
+func redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range sensitiveHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+// Header is the first line of a capture file.
+type Header struct {
+	// Version specifies the version of the file format.
+	Version int `json:"version"`
+	// AgentVersion specifies the version of the agent which created the file.
+	AgentVersion string `json:"agent_version"`
+	// Created specifies the time at which the capture was started.
+	Created time.Time `json:"created"`
+}
+
+// Record holds a single captured request.
+type Record struct {
+	// Time specifies the time at which the request was received.
+	Time time.Time `json:"time"`
+	// Protocol specifies the protocol the request was received with. It is
+	// one of ProtocolDatadog or ProtocolOTLP.
+	Protocol string `json:"protocol"`
+	// Path specifies the path of the request (e.g. "/v0.4/traces").
+	Path string `json:"path"`
+	// Header holds the headers of the request.
+	Header http.Header `json:"header"`
+	// Body holds the raw body of the request.
+	Body []byte `json:"body"`
+}
+
+// Writer writes records into a capture file. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+	n   int // number of records written
+}
+
+// Create creates the capture file at path and writes its header.
+func Create(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	w := &Writer{f: f, buf: buf, enc: json.NewEncoder(buf)}
+	if err := w.enc.Encode(Header{
+		Version:      FormatVersion,
+		AgentVersion: info.Version,
+		Created:      time.Now(),
+	}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends rec to the capture file.
+func (w *Writer) Write(rec *Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(rec); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Count returns the number of records written so far.
+func (w *Writer) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+// Close flushes and closes the capture file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buf.Flush(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Reader reads records from a capture file.
+type Reader struct {
+	Header Header
+
+	f   *os.File
+	dec *json.Decoder
+}
+
+// Open opens the capture file at path and reads its header.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{f: f, dec: json.NewDecoder(bufio.NewReader(f))}
+	if err := r.dec.Decode(&r.Header); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid capture file header: %v", err)
+	}
+	if r.Header.Version != FormatVersion {
+		f.Close()
+		return nil, fmt.Errorf("unsupported capture file version %d", r.Header.Version)
+	}
+	return r, nil
+}
+
+// Next returns the next record in the file, or io.EOF when there are none left.
+func (r *Reader) Next() (*Record, error) {
+	var rec Record
+	if err := r.dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Close closes the capture file.
+func (r *Reader) Close() error { return r.f.Close() }
+
+// ErrAlreadyCapturing is returned when attempting to start a capture while another one
+// is in progress.
+var ErrAlreadyCapturing = errors.New("a capture is already in progress")
+
+// Recorder records incoming payloads into a capture file for a limited amount of time.
+// The zero value is ready to use. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.RWMutex
+	w     *Writer
+	path  string
+	timer *time.Timer
+}
+
+// Start starts recording into a new capture file at path for the duration d.
+func (r *Recorder) Start(path string, d time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w != nil {
+		return ErrAlreadyCapturing
+	}
+	w, err := Create(path)
+	if err != nil {
+		return err
+	}
+	r.w = w
+	r.path = path
+	r.timer = time.AfterFunc(d, func() {
+		if err := r.stop(w); err != nil {
+			log.Errorf("Error stopping trace capture: %v", err)
+		}
+	})
+	log.Infof("Started capturing trace payloads into %s for %s", path, d)
+	return nil
+}
+
+// Stop stops the capture in progress, if any.
+func (r *Recorder) Stop() error { return r.stop(nil) }
+
+// stop stops the capture in progress if it is writing to w, or regardless of the
+// writer if w is nil.
+func (r *Recorder) stop(w *Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.w == nil || (w != nil && r.w != w) {
+		return nil
+	}
+	r.timer.Stop()
+	n := r.w.Count()
+	err := r.w.Close()
+	log.Infof("Stopped capturing trace payloads into %s (%d payloads)", r.path, n)
+	r.w, r.path, r.timer = nil, "", nil
+	return err
+}
+
+// Active reports whether a capture is in progress. It returns the path of the capture
+// file when true.
+func (r *Recorder) Active() (path string, ok bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.path, r.w != nil
+}
+
+// Record records the request received using protocol at path with the given header and body,
+// if a capture is in progress. Sensitive headers, such as API keys, are not recorded.
+func (r *Recorder) Record(protocol, path string, header http.Header, body []byte) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.w == nil {
+		return
+	}
+	if err := r.w.Write(&Record{
+		Time:     time.Now(),
+		Protocol: protocol,
+		Path:     path,
+		Header:   redactHeader(header),
+		Body:     body,
+	}); err != nil {
+		log.Errorf("Error writing trace capture record: %v", err)
+	}
+}
+
+// Wrap returns a copy of the body of req which records the request once it was fully read and
+// closed. It returns the original body if no capture is in progress.
+func (r *Recorder) Wrap(protocol string, req *http.Request) io.ReadCloser {
+	if _, ok := r.Active(); !ok {
+		return req.Body
+	}
+	return &recordingBody{
+		ReadCloser: req.Body,
+		recorder:   r,
+		protocol:   protocol,
+		path:       req.URL.Path,
+		header:     req.Header.Clone(),
+	}
+}
+
+// recordingBody is an io.ReadCloser which keeps a copy of everything read and records it
+// when closed.
+type recordingBody struct {
+	io.ReadCloser
+	recorder *Recorder
+	protocol string
+	path     string
+	header   http.Header
+	buf      []byte
+	done     bool
+}
+
+// Read implements io.Reader.
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf = append(b.buf, p[:n]...)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *recordingBody) Close() error {
+	if !b.done {
+		b.done = true
+		b.recorder.Record(b.protocol, b.path, b.header, b.buf)
+	}
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package capture
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll returns all the records found in the capture file at path.
+func readAll(t *testing.T, path string) []*Record {
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Equal(t, FormatVersion, r.Header.Version)
+	var recs []*Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		require.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	w, err := Create(path)
+	require.NoError(t, err)
+	in := []*Record{
+		{Protocol: ProtocolDatadog, Path: "/v0.4/traces", Header: http.Header{"Content-Type": []string{"application/msgpack"}}, Body: []byte{0x91, 0x90}},
+		{Protocol: ProtocolOTLP, Path: "/v1/traces", Header: http.Header{}, Body: []byte("{}")},
+	}
+	for _, rec := range in {
+		require.NoError(t, w.Write(rec))
+	}
+	assert.Equal(t, 2, w.Count())
+	require.NoError(t, w.Close())
+
+	out := readAll(t, path)
+	require.Len(t, out, 2)
+	for i := range in {
+		assert.Equal(t, in[i].Protocol, out[i].Protocol)
+		assert.Equal(t, in[i].Path, out[i].Path)
+		assert.Equal(t, in[i].Header, out[i].Header)
+		assert.Equal(t, in[i].Body, out[i].Body)
+	}
+
+	_, err = Create(path)
+	assert.True(t, os.IsExist(err), "existing capture files must not be overwritten")
+}
+
+func TestOpenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version":99}`+"\n"), 0600))
+	_, err := Open(path)
+	assert.Error(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("nil", func(t *testing.T) {
+		var r *Recorder
+		_, ok := r.Active()
+		assert.False(t, ok)
+		r.Record(ProtocolDatadog, "/v0.4/traces", nil, nil) // no panic
+	})
+
+	t.Run("inactive", func(t *testing.T) {
+		var r Recorder
+		req := httptest.NewRequest("POST", "/v0.4/traces", strings.NewReader("abc"))
+		assert.Equal(t, req.Body, r.Wrap(ProtocolDatadog, req))
+		assert.NoError(t, r.Stop())
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		var r Recorder
+		path := filepath.Join(dir, "wrap.jsonl")
+		require.NoError(t, r.Start(path, time.Minute))
+		assert.Equal(t, ErrAlreadyCapturing, r.Start(filepath.Join(dir, "other.jsonl"), time.Minute))
+		active, ok := r.Active()
+		assert.True(t, ok)
+		assert.Equal(t, path, active)
+
+		req := httptest.NewRequest("POST", "/v0.5/traces", strings.NewReader("payload"))
+		req.Header.Set("Datadog-Meta-Lang", "go")
+		req.Header.Set("Dd-Api-Key", "secret")
+		req.Header.Set("Authorization", "Bearer secret")
+		body := r.Wrap(ProtocolDatadog, req)
+		slurp, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(slurp))
+		require.NoError(t, body.Close())
+		require.NoError(t, body.Close()) // recorded only once
+		require.NoError(t, r.Stop())
+
+		recs := readAll(t, path)
+		require.Len(t, recs, 1)
+		assert.Equal(t, "/v0.5/traces", recs[0].Path)
+		assert.Equal(t, "go", recs[0].Header.Get("Datadog-Meta-Lang"))
+		assert.Empty(t, recs[0].Header.Get("Dd-Api-Key"))
+		assert.Empty(t, recs[0].Header.Get("Authorization"))
+		assert.Equal(t, "secret", req.Header.Get("Dd-Api-Key"), "the request must not be altered")
+		assert.Equal(t, "payload", string(recs[0].Body))
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		var r Recorder
+		require.NoError(t, r.Start(filepath.Join(dir, "expires.jsonl"), 10*time.Millisecond))
+		assert.Eventually(t, func() bool {
+			_, ok := r.Active()
+			return !ok
+		}, time.Second, 5*time.Millisecond)
+	})
+}
@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package capture
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ReplayOptions specifies the options used when replaying a capture file.
+type ReplayOptions struct {
+	// Target specifies the base URL of the Datadog trace API which receives the
+	// replayed payloads (e.g. "http://localhost:8126").
+	Target string
+
+	// OTLPTarget specifies the base URL of the OpenTelemetry HTTP receiver which
+	// receives the replayed OTLP payloads. OTLP records are skipped when empty.
+	OTLPTarget string
+
+	// Realtime reports whether the original delays between payloads should be
+	// preserved. When false, payloads are sent as fast as possible.
+	Realtime bool
+
+	// Client specifies the HTTP client used to send payloads. A client with a
+	// 10 second timeout is used when nil.
+	Client *http.Client
+}
+
+// ReplayStats holds statistics about a replay.
+type ReplayStats struct {
+	// Sent specifies the number of payloads which were accepted by the target.
+	Sent int
+	// Skipped specifies the number of payloads which had no target to be sent to.
+	Skipped int
+	// Failed specifies the number of payloads which could not be sent or were
+	// rejected by the target.
+	Failed int
+}
+
+// Replay sends all the records found in the capture file at path to the targets specified
+// in opts. It stops early if ctx is cancelled.
+func Replay(ctx context.Context, path string, opts ReplayOptions) (ReplayStats, error) {
+	var stats ReplayStats
+	r, err := Open(path)
+	if err != nil {
+		return stats, err
+	}
+	defer r.Close()
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	var last time.Time
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, fmt.Errorf("error reading capture file: %v", err)
+		}
+		if opts.Realtime && !last.IsZero() {
+			select {
+			case <-time.After(rec.Time.Sub(last)):
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			}
+		}
+		last = rec.Time
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		target := opts.Target
+		if rec.Protocol == ProtocolOTLP {
+			target = opts.OTLPTarget
+		}
+		if target == "" {
+			stats.Skipped++
+			continue
+		}
+		if err := send(ctx, client, target, rec); err != nil {
+			log.Debugf("Error replaying %s payload: %v", rec.Path, err)
+			stats.Failed++
+			continue
+		}
+		stats.Sent++
+	}
+}
+
+// send sends the record rec to the target base URL.
+func send(ctx context.Context, client *http.Client, target string, rec *Record) error {
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(target, "/")+rec.Path, bytes.NewReader(rec.Body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, vs := range rec.Header {
+		switch http.CanonicalHeaderKey(k) {
+		case "Content-Length", "Host", "Connection":
+			// set by the client
+			continue
+		}
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// StartRemote asks the trace-agent listening at the given receiver base URL to capture
+// the payloads it receives for the duration d into a file with the given name, created next
+// to the agent's log file. When name is empty, the agent chooses one. It returns the path of
+// the capture file.
+func StartRemote(receiver string, d time.Duration, name string) (string, error) {
+	q := url.Values{"duration": []string{d.String()}}
+	if name != "" {
+		q.Set("name", name)
+	}
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Post(strings.TrimSuffix(receiver, "/")+"/debug/capture?"+q.Encode(), "", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var out StatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("invalid response (%s): %v", resp.Status, err)
+	}
+	if out.Error != "" {
+		return "", fmt.Errorf("%s", out.Error)
+	}
+	return out.Path, nil
+}
+
+// StatusResponse is the response of the trace-agent's capture debug endpoint.
+type StatusResponse struct {
+	// Active reports whether a capture is in progress.
+	Active bool `json:"active"`
+	// Path specifies the path of the capture file, if a capture is in progress.
+	Path string `json:"path,omitempty"`
+	// Error holds the error which occurred when starting the capture, if any.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package capture
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	w, err := Create(path)
+	require.NoError(t, err)
+	now := time.Now()
+	for _, rec := range []*Record{
+		{Time: now, Protocol: ProtocolDatadog, Path: "/v0.4/traces", Header: http.Header{"Content-Type": []string{"application/msgpack"}, "Content-Length": []string{"1"}}, Body: []byte{0x90}},
+		{Time: now, Protocol: ProtocolOTLP, Path: "/v1/traces", Body: []byte("{}")},
+		{Time: now, Protocol: ProtocolDatadog, Path: "/v0.5/traces", Body: []byte("fail")},
+	} {
+		require.NoError(t, w.Write(rec))
+	}
+	require.NoError(t, w.Close())
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		slurp, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		received = append(received, req.URL.Path+" "+req.Header.Get("Content-Type")+" "+string(slurp))
+		mu.Unlock()
+		if string(slurp) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("datadog", func(t *testing.T) {
+		received = nil
+		stats, err := Replay(context.Background(), path, ReplayOptions{Target: srv.URL})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStats{Sent: 1, Skipped: 1, Failed: 1}, stats)
+		assert.Equal(t, []string{"/v0.4/traces application/msgpack \x90", "/v0.5/traces  fail"}, received)
+	})
+
+	t.Run("otlp", func(t *testing.T) {
+		received = nil
+		stats, err := Replay(context.Background(), path, ReplayOptions{OTLPTarget: srv.URL + "/"})
+		require.NoError(t, err)
+		assert.Equal(t, ReplayStats{Sent: 1, Skipped: 2}, stats)
+		assert.Equal(t, []string{"/v1/traces  {}"}, received)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Replay(ctx, path, ReplayOptions{Target: srv.URL})
+		assert.Equal(t, context.Canceled, err)
+	})
+}
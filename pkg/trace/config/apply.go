@@ -72,6 +72,49 @@ type ObfuscationConfig struct {
 	// Memcached holds the configuration for obfuscating the "memcached.command" tag
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
+
+	// CQL holds the configuration for obfuscating Cassandra CQL statements found in
+	// spans of type "cassandra". When disabled, these are passed through the SQL obfuscator.
+	CQL Enablable `mapstructure:"cassandra"`
+
+	// GraphQL holds the obfuscation configuration for GraphQL queries and variables.
+	GraphQL GraphQLObfuscationConfig `mapstructure:"graphql"`
+
+	// GRPC holds the obfuscation configuration for gRPC metadata tags.
+	GRPC GRPCObfuscationConfig `mapstructure:"grpc"`
+
+	// AWS holds the obfuscation configuration for AWS SDK request parameters
+	// of DynamoDB and S3 calls.
+	AWS AWSObfuscationConfig `mapstructure:"aws"`
+}
+
+// GraphQLObfuscationConfig holds the configuration settings for GraphQL obfuscation.
+type GraphQLObfuscationConfig struct {
+	// Enabled specifies whether GraphQL queries and variables should be obfuscated.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepValues specifies a set of variable names for which values will not be obfuscated.
+	KeepValues []string `mapstructure:"keep_values"`
+}
+
+// GRPCObfuscationConfig holds the configuration settings for gRPC metadata obfuscation.
+type GRPCObfuscationConfig struct {
+	// Enabled specifies whether gRPC metadata tags should be obfuscated.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Metadata specifies the metadata keys for which values will be obfuscated. When
+	// empty, a default set of authentication related keys is used.
+	Metadata []string `mapstructure:"metadata"`
+}
+
+// AWSObfuscationConfig holds the configuration settings for AWS SDK parameters obfuscation.
+type AWSObfuscationConfig struct {
+	// Enabled specifies whether AWS request parameters should be obfuscated.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepValues specifies a set of parameter names for which values will not be
+	// obfuscated, in addition to the default ones (e.g. "TableName" or "Bucket").
+	KeepValues []string `mapstructure:"keep_values"`
 }
 
 // HTTPObfuscationConfig holds the configuration settings for HTTP obfuscation.
@@ -309,6 +352,14 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if config.Datadog.IsSet("apm_config.prometheus_metrics.enabled") {
+		c.PrometheusEnabled = config.Datadog.GetBool("apm_config.prometheus_metrics.enabled")
+	}
+
+	if config.Datadog.IsSet("apm_config.debug_capture.enabled") {
+		c.CaptureEnabled = config.Datadog.GetBool("apm_config.debug_capture.enabled")
+	}
+
 	// undocumented
 	if config.Datadog.IsSet("apm_config.max_cpu_percent") {
 		c.MaxCPU = config.Datadog.GetFloat64("apm_config.max_cpu_percent") / 100
@@ -89,6 +89,14 @@ type AgentConfig struct {
 	StatsdHost string
 	StatsdPort int
 
+	// PrometheusEnabled reports whether internal metrics should also be exposed in the
+	// OpenMetrics format on the receiver's /metrics endpoint.
+	PrometheusEnabled bool
+
+	// CaptureEnabled reports whether payload captures can be started using the receiver's
+	// /debug/capture endpoint. It is disabled by default, as captures write files to disk.
+	CaptureEnabled bool
+
 	// logging
 	LogLevel      string
 	LogFilePath   string
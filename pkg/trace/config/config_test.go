@@ -306,6 +306,13 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(o.RemoveStackTraces)
 	assert.True(c.Obfuscation.Redis.Enabled)
 	assert.True(c.Obfuscation.Memcached.Enabled)
+	assert.True(o.CQL.Enabled)
+	assert.True(o.GraphQL.Enabled)
+	assert.EqualValues([]string{"first"}, o.GraphQL.KeepValues)
+	assert.True(o.GRPC.Enabled)
+	assert.EqualValues([]string{"authorization", "x-tenant"}, o.GRPC.Metadata)
+	assert.True(o.AWS.Enabled)
+	assert.EqualValues([]string{"ContentType"}, o.AWS.KeepValues)
 }
 
 func TestUndocumentedYamlConfig(t *testing.T) {
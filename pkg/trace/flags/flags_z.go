@@ -5,7 +5,10 @@
 
 package flags
 
-import "flag"
+import (
+	"flag"
+	"time"
+)
 
 var (
 	// ConfigPath specifies the path to the configuration file.
@@ -30,6 +33,21 @@ var (
 	// MemProfile specifies the path to output memory profiling information to.
 	// When empty, memory profiling is disabled.
 	MemProfile string
+
+	// Capture specifies the duration during which a running agent should record the
+	// payloads it receives. When zero, no capture is requested.
+	Capture time.Duration
+
+	// Replay specifies the path to a capture file which should be replayed into a
+	// running agent. When empty, nothing is replayed.
+	Replay string
+
+	// ReplayTarget specifies the base URL of the trace agent receiving the replayed
+	// payloads. When empty, the local trace agent is used.
+	ReplayTarget string
+
+	// ReplayRealtime will cause payloads to be replayed using their original timing.
+	ReplayRealtime bool
 )
 
 // Win holds a set of flags which will be populated only during the Windows build.
@@ -47,6 +65,12 @@ func init() {
 	flag.BoolVar(&Version, "version", false, "Show version information and exit")
 	flag.BoolVar(&Info, "info", false, "Show info about running trace agent process and exit")
 
+	// capture & replay
+	flag.DurationVar(&Capture, "capture", 0, "Record the payloads received by the running trace agent for the given `duration` (e.g. 1m) and exit")
+	flag.StringVar(&Replay, "replay", "", "Replay the payloads found in the given capture `file` and exit")
+	flag.StringVar(&ReplayTarget, "replay-target", "", "Base `URL` of the trace agent receiving replayed payloads (defaults to the local trace agent)")
+	flag.BoolVar(&ReplayRealtime, "replay-realtime", false, "Replay payloads using the delays with which they were originally received")
+
 	// profiling
 	flag.StringVar(&CPUProfile, "cpuprofile", "", "Write cpu profile to file")
 	flag.StringVar(&MemProfile, "memprofile", "", "Write memory profile to `file`")
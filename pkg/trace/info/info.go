@@ -77,6 +77,9 @@ const (
   {{if lt .Status.RateLimiter.TargetRate 1.0}}
   WARNING: Rate-limiter keep percentage: {{percent .Status.RateLimiter.TargetRate}} %
   {{end}}
+  {{if .Status.Watchdog.Shedding.Stage}}
+  WARNING: Load shedding stage: {{.Status.Watchdog.Shedding.Stage}} (resource usage at {{percent .Status.Watchdog.Shedding.Pressure}} % of limits)
+  {{end}}
 
   --- Writer stats (1 min) ---
 
@@ -0,0 +1,316 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxSeriesPerMetric specifies the maximum number of distinct tag sets kept for a single
+// metric by the Prometheus sink. Additional tag sets are dropped.
+const maxSeriesPerMetric = 1000
+
+var (
+	promMu      sync.RWMutex
+	promSink    *promCollector // nil if disabled
+	promHandler http.Handler
+)
+
+// EnablePrometheus wraps the global Client so that all the metrics it receives are also
+// aggregated in memory, to be exposed in the Prometheus and OpenMetrics text formats by
+// PrometheusHandler. Counts become counters, gauges stay gauges and histograms and timings
+// become summaries without quantiles. It should be called after Configure.
+func EnablePrometheus() {
+	promMu.Lock()
+	defer promMu.Unlock()
+	if promSink != nil {
+		return
+	}
+	promSink = newPromCollector()
+	Client = &promClient{StatsClient: Client, sink: promSink}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(promSink)
+	reg.MustRegister(prometheus.NewGoCollector())
+	reg.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	promHandler = promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+		// expose the valid metrics even if some can not be, rather than failing the whole scrape
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// PrometheusHandler returns the http.Handler exposing the metrics aggregated since
+// EnablePrometheus was called, or nil if it was not.
+func PrometheusHandler() http.Handler {
+	promMu.RLock()
+	defer promMu.RUnlock()
+	return promHandler
+}
+
+// PrometheusEnabled reports whether the Prometheus sink was enabled using EnablePrometheus.
+func PrometheusEnabled() bool {
+	promMu.RLock()
+	defer promMu.RUnlock()
+	return promSink != nil
+}
+
+// CountLocal adds value to the given counter in the Prometheus sink only, without forwarding
+// it to dogstatsd. It is a no-op when the sink is disabled. It should be used for metrics which
+// would be too costly to send to dogstatsd, such as per-service ones.
+func CountLocal(name string, value int64, tags []string) {
+	promMu.RLock()
+	defer promMu.RUnlock()
+	if promSink != nil {
+		promSink.add(prometheus.CounterValue, name, float64(value), tags)
+	}
+}
+
+// GaugeLocal sets the given gauge in the Prometheus sink only, without forwarding it to
+// dogstatsd. It is a no-op when the sink is disabled.
+func GaugeLocal(name string, value float64, tags []string) {
+	promMu.RLock()
+	defer promMu.RUnlock()
+	if promSink != nil {
+		promSink.set(name, value, tags)
+	}
+}
+
+// promClient is a StatsClient which forwards all metrics to the wrapped client, while also
+// recording them into a Prometheus sink.
+type promClient struct {
+	StatsClient
+	sink *promCollector
+}
+
+// Gauge implements StatsClient.
+func (c *promClient) Gauge(name string, value float64, tags []string, rate float64) error {
+	c.sink.set(name, value, tags)
+	return c.forward(func(cl StatsClient) error { return cl.Gauge(name, value, tags, rate) })
+}
+
+// Count implements StatsClient.
+func (c *promClient) Count(name string, value int64, tags []string, rate float64) error {
+	c.sink.add(prometheus.CounterValue, name, float64(value), tags)
+	return c.forward(func(cl StatsClient) error { return cl.Count(name, value, tags, rate) })
+}
+
+// Histogram implements StatsClient.
+func (c *promClient) Histogram(name string, value float64, tags []string, rate float64) error {
+	c.sink.observe(name, value, tags)
+	return c.forward(func(cl StatsClient) error { return cl.Histogram(name, value, tags, rate) })
+}
+
+// Timing implements StatsClient. Timings are exposed in seconds.
+func (c *promClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	c.sink.observe(name, value.Seconds(), tags)
+	return c.forward(func(cl StatsClient) error { return cl.Timing(name, value, tags, rate) })
+}
+
+// Flush implements StatsClient.
+func (c *promClient) Flush() error {
+	return c.forward(func(cl StatsClient) error { return cl.Flush() })
+}
+
+// forward calls fn with the wrapped client, if one is set.
+func (c *promClient) forward(fn func(StatsClient) error) error {
+	if c.StatsClient == nil {
+		return nil
+	}
+	return fn(c.StatsClient)
+}
+
+// promSeries holds the aggregated value of a metric for a given tag set.
+type promSeries struct {
+	labels map[string]string
+	value  float64 // counter or gauge value, or summary sum
+	count  uint64  // summary count
+}
+
+// promMetric holds all the series of a metric.
+type promMetric struct {
+	name   string               // dogstatsd name of the metric
+	typ    prometheus.ValueType // prometheus.UntypedValue for summaries
+	series map[string]*promSeries
+}
+
+// promCollector is a prometheus.Collector aggregating dogstatsd-like metrics. Since the tags
+// sent for a given metric may vary from one call to another, label names are only computed
+// at collection time, as the union of the label names of all the series of a metric.
+type promCollector struct {
+	mu      sync.Mutex
+	metrics map[string]*promMetric // by Prometheus name, as several dogstatsd names may map to it
+	dropped map[string]bool        // metrics for which series were dropped, for logging once
+}
+
+func newPromCollector() *promCollector {
+	return &promCollector{
+		metrics: make(map[string]*promMetric),
+		dropped: make(map[string]bool),
+	}
+}
+
+// series returns the series of the metric name with the given type and tags, creating it if
+// needed. It returns nil if the series can not be created. It must be called with c.mu held.
+func (c *promCollector) series(typ prometheus.ValueType, name string, tags []string) *promSeries {
+	m, ok := c.metrics[promName(name)]
+	if !ok {
+		m = &promMetric{name: name, typ: typ, series: make(map[string]*promSeries)}
+		c.metrics[promName(name)] = m
+	}
+	if m.typ != typ {
+		// the same name is used with different metric types; keep the first one
+		return nil
+	}
+	// series are identified by their labels rather than by their tags, so that tags sent in a
+	// different order, or converted into the same labels, are aggregated into the same series
+	labels := tagsToLabels(tags)
+	key := labelsKey(labels)
+	s, ok := m.series[key]
+	if !ok {
+		if len(m.series) >= maxSeriesPerMetric {
+			if !c.dropped[name] {
+				c.dropped[name] = true
+				log.Warnf("Too many tag combinations for metric %q, some will not be exposed on /metrics", name)
+			}
+			return nil
+		}
+		s = &promSeries{labels: labels}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (c *promCollector) add(typ prometheus.ValueType, name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series(typ, name, tags); s != nil {
+		s.value += value
+	}
+}
+
+func (c *promCollector) set(name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series(prometheus.GaugeValue, name, tags); s != nil {
+		s.value = value
+	}
+}
+
+func (c *promCollector) observe(name string, value float64, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s := c.series(prometheus.UntypedValue, name, tags); s != nil {
+		s.value += value
+		s.count++
+	}
+}
+
+// Describe implements prometheus.Collector. The collector is unchecked, as the metrics it
+// exposes are not known in advance.
+func (c *promCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *promCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for fqName, m := range c.metrics {
+		if m.typ == prometheus.CounterValue {
+			fqName += "_total"
+		}
+		var names []string
+		seen := make(map[string]bool)
+		for _, s := range m.series {
+			for k := range s.labels {
+				if !seen[k] {
+					seen[k] = true
+					names = append(names, k)
+				}
+			}
+		}
+		sort.Strings(names)
+		desc := prometheus.NewDesc(fqName, "Trace agent metric "+m.name+".", names, nil)
+		for _, s := range m.series {
+			values := make([]string, len(names))
+			for i, k := range names {
+				values[i] = s.labels[k]
+			}
+			var (
+				metric prometheus.Metric
+				err    error
+			)
+			if m.typ == prometheus.UntypedValue {
+				metric, err = prometheus.NewConstSummary(desc, s.count, s.value, nil, values...)
+			} else {
+				metric, err = prometheus.NewConstMetric(desc, m.typ, s.value, values...)
+			}
+			if err != nil {
+				log.Debugf("Error exposing metric %q: %v", m.name, err)
+				continue
+			}
+			ch <- metric
+		}
+	}
+}
+
+// promName converts a dogstatsd metric or tag name into a valid Prometheus name.
+func promName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// tagsToLabels converts "key:value" tags into Prometheus labels. Tags without a value are
+// converted into labels with the value "true". When several tags are converted into the same
+// label, the value of the greatest tag is kept, regardless of the order of the tags.
+func tagsToLabels(tags []string) map[string]string {
+	sorted := make([]string, len(tags))
+	copy(sorted, tags)
+	sort.Strings(sorted)
+	labels := make(map[string]string, len(tags))
+	for _, tag := range sorted {
+		parts := strings.SplitN(tag, ":", 2)
+		k := promName(parts[0])
+		if k == "" || (k[0] >= '0' && k[0] <= '9') || strings.HasPrefix(k, "__") {
+			k = "tag_" + k
+		}
+		if len(parts) == 2 {
+			labels[k] = parts[1]
+		} else {
+			labels[k] = "true"
+		}
+	}
+	return labels
+}
+
+// labelsKey returns a key uniquely identifying the given set of labels.
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, k := range names {
+		b.WriteString(k)
+		b.WriteByte(0xff) // not valid UTF-8, so it can't be part of a label
+		b.WriteString(labels[k])
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
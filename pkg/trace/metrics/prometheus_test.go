@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type nopClient struct{ counts int }
+
+func (*nopClient) Gauge(string, float64, []string, float64) error     { return nil }
+func (c *nopClient) Count(string, int64, []string, float64) error     { c.counts++; return nil }
+func (*nopClient) Histogram(string, float64, []string, float64) error { return nil }
+func (*nopClient) Timing(string, time.Duration, []string, float64) error {
+	return nil
+}
+func (*nopClient) Flush() error { return nil }
+
+func TestPromCollector(t *testing.T) {
+	next := &nopClient{}
+	sink := newPromCollector()
+	c := &promClient{StatsClient: next, sink: sink}
+
+	c.Count("datadog.trace_agent.receiver.traces_received", 3, []string{"lang:go"}, 1)
+	c.Count("datadog.trace_agent.receiver.traces_received", 2, []string{"lang:go"}, 1)
+	c.Count("datadog.trace_agent.receiver.traces_received", 1, []string{"lang:python", "tracer_version:1.0"}, 1)
+	c.Gauge("datadog.trace_agent.heartbeat", 1, nil, 1)
+	c.Timing("datadog.trace_agent.trace_writer.flush_duration", 500*time.Millisecond, nil, 1)
+	c.Timing("datadog.trace_agent.trace_writer.flush_duration", 1500*time.Millisecond, nil, 1)
+	c.Gauge("datadog.trace_agent.receiver.traces_received", 1, nil, 1) // conflicting type, ignored
+
+	assert.Equal(t, 3, next.counts)
+	err := testutil.CollectAndCompare(sink, strings.NewReader(`
+# HELP datadog_trace_agent_heartbeat Trace agent metric datadog.trace_agent.heartbeat.
+# TYPE datadog_trace_agent_heartbeat gauge
+datadog_trace_agent_heartbeat 1
+# HELP datadog_trace_agent_receiver_traces_received_total Trace agent metric datadog.trace_agent.receiver.traces_received.
+# TYPE datadog_trace_agent_receiver_traces_received_total counter
+datadog_trace_agent_receiver_traces_received_total{lang="go",tracer_version=""} 5
+datadog_trace_agent_receiver_traces_received_total{lang="python",tracer_version="1.0"} 1
+# HELP datadog_trace_agent_trace_writer_flush_duration Trace agent metric datadog.trace_agent.trace_writer.flush_duration.
+# TYPE datadog_trace_agent_trace_writer_flush_duration summary
+datadog_trace_agent_trace_writer_flush_duration_sum 2
+datadog_trace_agent_trace_writer_flush_duration_count 2
+`))
+	assert.NoError(t, err)
+}
+
+func TestPromCollectorTagSets(t *testing.T) {
+	sink := newPromCollector()
+	sink.add(prometheus.CounterValue, "m", 1, []string{"env:prod", "service:a"})
+	sink.add(prometheus.CounterValue, "m", 1, []string{"service:a", "env:prod"})             // reordered
+	sink.add(prometheus.CounterValue, "m", 1, []string{"service:a", "env:prod", "env:prod"}) // duplicated
+	sink.add(prometheus.CounterValue, "m", 1, []string{"service:b", "canary"})               // no value
+	sink.add(prometheus.CounterValue, "m", 1, []string{"canary:true", "service:b"})          // same label
+	sink.add(prometheus.CounterValue, "m", 1, []string{"peer.service:c", "peer_service:c"})  // colliding names
+	sink.add(prometheus.CounterValue, "m", 1, []string{"peer_service:c", "peer.service:c"})  // colliding names, reordered
+	sink.add(prometheus.CounterValue, "m", 1, []string{"peer.service:d", "peer_service:e"})  // colliding names, different values
+	sink.add(prometheus.CounterValue, "m", 1, []string{"peer_service:e", "peer.service:d"})  // kept regardless of the order
+	sink.add(prometheus.CounterValue, "m.total", 1, []string{"service:a"})                   // colliding metric name
+	sink.add(prometheus.GaugeValue, "m_total", 1, []string{"service:a"})                     // colliding metric name and type
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(sink)
+	_, err := reg.Gather()
+	assert.NoError(t, err)
+
+	err = testutil.CollectAndCompare(sink, strings.NewReader(`
+# HELP m_total Trace agent metric m.
+# TYPE m_total counter
+m_total{canary="",env="prod",peer_service="",service="a"} 3
+m_total{canary="true",env="",peer_service="",service="b"} 2
+m_total{canary="",env="",peer_service="c",service=""} 2
+m_total{canary="",env="",peer_service="e",service=""} 2
+# HELP m_total_total Trace agent metric m.total.
+# TYPE m_total_total counter
+m_total_total{service="a"} 1
+`))
+	assert.NoError(t, err)
+}
+
+func TestPromCollectorMaxSeries(t *testing.T) {
+	sink := newPromCollector()
+	for i := 0; i < maxSeriesPerMetric+10; i++ {
+		sink.add(0, "m", 1, []string{"service:" + strings.Repeat("a", i)})
+	}
+	assert.Len(t, sink.metrics["m"].series, maxSeriesPerMetric)
+}
+
+func TestTagsToLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"service":      "my-svc",
+		"env":          "",
+		"tag_0day":     "x:y",
+		"peer_service": "true",
+		"tag___name":   "a",
+	}, tagsToLabels([]string{"service:my-svc", "env:", "0day:x:y", "peer.service", "__name:a"}))
+}
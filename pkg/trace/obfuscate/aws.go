@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// awsParamsPrefix is the prefix of the tags holding the flattened request parameters of
+// an AWS SDK call (e.g. "params.Key.id.S").
+const awsParamsPrefix = "params."
+
+// awsServiceTags holds the tags which may contain the name of the called AWS service.
+var awsServiceTags = []string{"aws.service", "aws_service"}
+
+// awsObfuscatedServices holds the (lowercased) AWS services which parameters are obfuscated.
+var awsObfuscatedServices = map[string]bool{
+	"dynamodb": true,
+	"s3":       true,
+}
+
+// defaultAWSKeepValues holds the parameters which values are never obfuscated because they
+// describe the request rather than the data being accessed.
+var defaultAWSKeepValues = []string{
+	"TableName",
+	"IndexName",
+	"Select",
+	"Limit",
+	"ConsistentRead",
+	"ReturnValues",
+	"ReturnConsumedCapacity",
+	"Bucket",
+	"Prefix",
+	"Delimiter",
+	"MaxKeys",
+}
+
+// newAWSKeepSet returns the set of parameters which values are kept, including the defaults.
+func newAWSKeepSet(keep []string) map[string]bool {
+	set := make(map[string]bool, len(defaultAWSKeepValues)+len(keep))
+	for _, k := range defaultAWSKeepValues {
+		set[k] = true
+	}
+	for _, k := range keep {
+		set[k] = true
+	}
+	return set
+}
+
+// obfuscateAWS obfuscates the request parameters of DynamoDB and S3 calls found in the
+// "params.*" tags, unless the top level parameter name is one of the kept ones.
+func (o *Obfuscator) obfuscateAWS(span *pb.Span) {
+	if o.awsKeepValues == nil || span.Meta == nil {
+		return
+	}
+	var service string
+	for _, tag := range awsServiceTags {
+		if v, ok := span.Meta[tag]; ok {
+			service = strings.ToLower(v)
+			break
+		}
+	}
+	if !awsObfuscatedServices[service] {
+		return
+	}
+	for k := range span.Meta {
+		if !strings.HasPrefix(k, awsParamsPrefix) {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(k, awsParamsPrefix), ".", 2)[0]
+		if !o.awsKeepValues[name] {
+			span.Meta[k] = "?"
+		}
+	}
+}
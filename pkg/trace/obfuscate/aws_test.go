@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateAWS(t *testing.T) {
+	for name, tt := range map[string]struct {
+		cfg      config.AWSObfuscationConfig
+		typ      string
+		in, want map[string]string
+	}{
+		"dynamodb": {
+			cfg: config.AWSObfuscationConfig{Enabled: true},
+			typ: "aws",
+			in: map[string]string{
+				"aws.service":                            "DynamoDB",
+				"params.TableName":                       "users",
+				"params.Key.id.S":                        "1234",
+				"params.ExpressionAttributeValues.:v1.S": "bob",
+				"params.Limit":                           "10",
+			},
+			want: map[string]string{
+				"aws.service":                            "DynamoDB",
+				"params.TableName":                       "users",
+				"params.Key.id.S":                        "?",
+				"params.ExpressionAttributeValues.:v1.S": "?",
+				"params.Limit":                           "10",
+			},
+		},
+		"s3-http": {
+			cfg: config.AWSObfuscationConfig{Enabled: true, KeepValues: []string{"ContentType"}},
+			typ: "http",
+			in: map[string]string{
+				"aws_service":        "s3",
+				"params.Bucket":      "my-bucket",
+				"params.Key":         "users/1234/avatar.png",
+				"params.ContentType": "image/png",
+			},
+			want: map[string]string{
+				"aws_service":        "s3",
+				"params.Bucket":      "my-bucket",
+				"params.Key":         "?",
+				"params.ContentType": "image/png",
+			},
+		},
+		"other-service": {
+			cfg: config.AWSObfuscationConfig{Enabled: true},
+			typ: "aws",
+			in: map[string]string{
+				"aws.service":     "sqs",
+				"params.QueueUrl": "https://sqs/123",
+			},
+			want: map[string]string{
+				"aws.service":     "sqs",
+				"params.QueueUrl": "https://sqs/123",
+			},
+		},
+		"disabled": {
+			typ: "aws",
+			in: map[string]string{
+				"aws.service":     "dynamodb",
+				"params.Key.id.S": "1234",
+			},
+			want: map[string]string{
+				"aws.service":     "dynamodb",
+				"params.Key.id.S": "1234",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			span := pb.Span{Type: tt.typ, Meta: tt.in}
+			NewObfuscator(&config.ObfuscationConfig{AWS: tt.cfg}).Obfuscate(&span)
+			assert.Equal(t, tt.want, span.Meta)
+		})
+	}
+}
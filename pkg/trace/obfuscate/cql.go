@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// cqlQueryTag is the tag some tracers use to store the raw CQL statement.
+const cqlQueryTag = "cassandra.query"
+
+// cqlUUID matches an unquoted UUID literal at the beginning of a string.
+var cqlUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// obfuscateCQL obfuscates the resource and the "cassandra.query" tag of the given span.
+func (o *Obfuscator) obfuscateCQL(span *pb.Span) {
+	if span.Resource != "" {
+		span.Resource = o.ObfuscateCQLString(span.Resource)
+	}
+	if span.Meta != nil && span.Meta[cqlQueryTag] != "" {
+		traceutil.SetMeta(span, cqlQueryTag, o.ObfuscateCQLString(span.Meta[cqlQueryTag]))
+	}
+}
+
+// ObfuscateCQLString obfuscates the given Cassandra CQL statement. String, numeric, blob,
+// UUID and boolean literals are replaced by "?", collection literals are collapsed into
+// a single "?" and parenthesized lists of literals (e.g. IN or VALUES lists) into "(?)".
+// Comments are removed and whitespace is compacted.
+func (*Obfuscator) ObfuscateCQLString(in string) string {
+	s := cqlScanner{src: in}
+	out, _, _ := s.scanGroup(0)
+	return out
+}
+
+// cqlScanner holds the state of a CQL statement being obfuscated.
+type cqlScanner struct {
+	src string
+	pos int
+}
+
+// cqlClosers maps group openers to their closers.
+var cqlClosers = map[byte]byte{'(': ')', '[': ']', '{': '}'}
+
+// scanGroup scans the statement until the given closer is found (or until the end if closer
+// is 0). It returns the obfuscated contents, whether they consisted only of literals and
+// whether the closer was found.
+func (s *cqlScanner) scanGroup(closer byte) (out string, literalsOnly bool, closed bool) {
+	var (
+		buf        strings.Builder
+		hasLiteral bool
+		last       byte // last significant byte written
+	)
+	literalsOnly = true
+	space := func() {
+		if buf.Len() > 0 && last != ' ' {
+			buf.WriteByte(' ')
+			last = ' '
+		}
+	}
+	literal := func() {
+		buf.WriteByte('?')
+		last = '?'
+		hasLiteral = true
+	}
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		next := s.peek(1)
+		switch {
+		case closer != 0 && c == closer:
+			s.pos++
+			return strings.TrimRight(buf.String(), " "), literalsOnly && hasLiteral, true
+		case isSpace(c):
+			s.pos++
+			space()
+		case (c == '-' && next == '-') || (c == '/' && next == '/'):
+			s.skipUntil("\n")
+			space()
+		case c == '/' && next == '*':
+			s.skipUntil("*/")
+			space()
+		case c == '\'':
+			s.skipString()
+			literal()
+		case c == '$' && next == '$':
+			s.pos += 2
+			s.skipUntil("$$")
+			literal()
+		case c == '"':
+			start := s.pos
+			s.skipQuotedIdentifier()
+			buf.WriteString(s.src[start:s.pos])
+			last = '"'
+			literalsOnly = false
+		case c == '(' || c == '[' || c == '{':
+			s.pos++
+			inner, lits, ok := s.scanGroup(cqlClosers[c])
+			switch {
+			case lits && ok && c == '(':
+				buf.WriteString("(?)")
+				last = ')'
+				literalsOnly = false
+			case lits && ok:
+				literal()
+			default:
+				buf.WriteByte(c)
+				buf.WriteString(inner)
+				if ok {
+					buf.WriteByte(cqlClosers[c])
+				}
+				last = c
+				literalsOnly = false
+			}
+		case isDigit(rune(c)) || (c == '-' && isDigit(rune(next)) && strings.IndexByte("(,=<>[{: ", last) != -1):
+			s.pos++
+			s.skipNumber()
+			literal()
+		case isLeadingLetter(rune(c)):
+			if m := cqlUUID.FindString(s.src[s.pos:]); m != "" {
+				s.pos += len(m)
+				literal()
+				break
+			}
+			start := s.pos
+			for s.pos < len(s.src) && (isLetter(rune(s.src[s.pos])) || isDigit(rune(s.src[s.pos]))) {
+				s.pos++
+			}
+			word := s.src[start:s.pos]
+			switch strings.ToLower(word) {
+			case "true", "false", "nan", "infinity":
+				literal()
+			default:
+				buf.WriteString(word)
+				last = 'a'
+				literalsOnly = false
+			}
+		case c == ',' || c == ':':
+			s.pos++
+			buf.WriteByte(c)
+			last = c
+		default:
+			s.pos++
+			buf.WriteByte(c)
+			last = c
+			literalsOnly = false
+		}
+	}
+	return strings.TrimRight(buf.String(), " "), literalsOnly && hasLiteral, false
+}
+
+// peek returns the byte found n positions ahead of the current one, or 0.
+func (s *cqlScanner) peek(n int) byte {
+	if s.pos+n < len(s.src) {
+		return s.src[s.pos+n]
+	}
+	return 0
+}
+
+// skipUntil advances the scanner past the next occurrence of end, or to the end of the input.
+func (s *cqlScanner) skipUntil(end string) {
+	if i := strings.Index(s.src[s.pos:], end); i != -1 {
+		s.pos += i + len(end)
+		return
+	}
+	s.pos = len(s.src)
+}
+
+// skipString advances the scanner past a single-quoted string, where quotes are
+// escaped by doubling them.
+func (s *cqlScanner) skipString() {
+	s.pos++
+	for s.pos < len(s.src) {
+		if s.src[s.pos] == '\'' {
+			if s.peek(1) == '\'' {
+				s.pos += 2
+				continue
+			}
+			s.pos++
+			return
+		}
+		s.pos++
+	}
+}
+
+// skipQuotedIdentifier advances the scanner past a double-quoted identifier.
+func (s *cqlScanner) skipQuotedIdentifier() {
+	s.pos++
+	for s.pos < len(s.src) {
+		if s.src[s.pos] == '"' {
+			if s.peek(1) == '"' {
+				s.pos += 2
+				continue
+			}
+			s.pos++
+			return
+		}
+		s.pos++
+	}
+}
+
+// skipNumber advances the scanner past a numeric, blob (0x...) or UUID literal.
+func (s *cqlScanner) skipNumber() {
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		if isDigit(rune(c)) || isLetter(rune(c)) || c == '.' {
+			s.pos++
+			continue
+		}
+		if (c == '-' || c == '+') && s.pos+1 < len(s.src) && (isDigit(rune(s.src[s.pos+1])) || isLetter(rune(s.src[s.pos+1]))) {
+			// exponents (1e-3) or UUID separators
+			s.pos++
+			continue
+		}
+		return
+	}
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+// cqlTestFile contains all the tests for CQL obfuscation
+const cqlTestFile = "./testdata/cql_tests.xml"
+
+func TestObfuscateCQL(t *testing.T) {
+	f, err := os.Open(cqlTestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var suite xmlObfuscateTests
+	if err := xml.NewDecoder(f).Decode(&suite); err != nil {
+		t.Fatal(err)
+	}
+	o := NewObfuscator(nil)
+	for _, tt := range suite.Tests {
+		t.Run(tt.Tag, func(t *testing.T) {
+			assert.Equal(t, tt.Out, o.ObfuscateCQLString(strings.TrimSpace(tt.In)))
+		})
+	}
+}
+
+func TestObfuscateCQLSpan(t *testing.T) {
+	const query = "SELECT * FROM users WHERE token(id) = token('bob') AND bucket IN (1, 2)"
+
+	t.Run("enabled", func(t *testing.T) {
+		span := pb.Span{
+			Type:     "cassandra",
+			Resource: query,
+			Meta:     map[string]string{cqlQueryTag: query},
+		}
+		NewObfuscator(&config.ObfuscationConfig{CQL: config.Enablable{Enabled: true}}).Obfuscate(&span)
+		assert.Equal(t, "SELECT * FROM users WHERE token(id) = token(?) AND bucket IN (?)", span.Resource)
+		assert.Equal(t, span.Resource, span.Meta[cqlQueryTag])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		span := pb.Span{Type: "cassandra", Resource: query}
+		NewObfuscator(nil).Obfuscate(&span)
+		assert.Equal(t, "SELECT * FROM users WHERE token ( id ) = token ( ? ) AND bucket IN ( ? )", span.Resource)
+		assert.Equal(t, span.Resource, span.Meta[sqlQueryTag])
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		b := pb.ClientGroupedStats{Type: "cassandra", Resource: query}
+		NewObfuscator(&config.ObfuscationConfig{CQL: config.Enablable{Enabled: true}}).ObfuscateStatsGroup(&b)
+		assert.Equal(t, "SELECT * FROM users WHERE token(id) = token(?) AND bucket IN (?)", b.Resource)
+	})
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+const (
+	// graphQLQueryTag is the tag holding the raw GraphQL query.
+	graphQLQueryTag = "graphql.query"
+	// graphQLVariablesTag is the tag holding the JSON encoded GraphQL variables.
+	graphQLVariablesTag = "graphql.variables"
+)
+
+// graphQLObfuscator obfuscates GraphQL queries and their variables.
+type graphQLObfuscator struct {
+	keepValues map[string]bool // names of the variables which values are kept
+	variables  *jsonObfuscator // obfuscator for JSON encoded variables
+}
+
+func newGraphQLObfuscator(cfg *config.GraphQLObfuscationConfig, o *Obfuscator) *graphQLObfuscator {
+	keep := make(map[string]bool, len(cfg.KeepValues))
+	for _, v := range cfg.KeepValues {
+		keep[v] = true
+	}
+	return &graphQLObfuscator{
+		keepValues: keep,
+		variables: newJSONObfuscator(&config.JSONObfuscationConfig{
+			Enabled:    true,
+			KeepValues: cfg.KeepValues,
+		}, o),
+	}
+}
+
+// obfuscateGraphQL obfuscates the query found in the resource and in the "graphql.query" tag, along
+// with the variables found either in the "graphql.variables" tag as JSON or in individual
+// "graphql.variables.<name>" tags.
+func (o *Obfuscator) obfuscateGraphQL(span *pb.Span) {
+	if o.graphql == nil {
+		return
+	}
+	if span.Resource != "" {
+		span.Resource = o.ObfuscateGraphQLString(span.Resource)
+	}
+	if span.Meta == nil {
+		return
+	}
+	for k, v := range span.Meta {
+		switch {
+		case k == graphQLQueryTag:
+			span.Meta[k] = o.ObfuscateGraphQLString(v)
+		case k == graphQLVariablesTag:
+			span.Meta[k], _ = o.graphql.variables.obfuscate([]byte(v))
+			// see (*Obfuscator).obfuscateJSON on why partial results are accepted
+		case strings.HasPrefix(k, graphQLVariablesTag+"."):
+			if !o.graphql.keepValues[strings.TrimPrefix(k, graphQLVariablesTag+".")] {
+				span.Meta[k] = "?"
+			}
+		}
+	}
+}
+
+// ObfuscateGraphQLString obfuscates the given GraphQL query by replacing all string, block string
+// and numeric literals with "?". Variables, names, enum values and the structure of the query are
+// kept. Comments are removed and whitespace is compacted.
+func (*Obfuscator) ObfuscateGraphQLString(in string) string {
+	var (
+		out   strings.Builder
+		space bool // pending whitespace
+	)
+	out.Grow(len(in))
+	for i := 0; i < len(in); {
+		c := in[i]
+		switch {
+		case isSpace(c) || c == ',':
+			// commas are insignificant in GraphQL, but we keep them for readability
+			if c == ',' {
+				out.WriteByte(',')
+			}
+			space = true
+			i++
+			continue
+		case c == '#':
+			for i < len(in) && in[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+		}
+		if space && out.Len() > 0 {
+			out.WriteByte(' ')
+		}
+		space = false
+		switch {
+		case strings.HasPrefix(in[i:], `"""`):
+			end := strings.Index(in[i+3:], `"""`)
+			if end == -1 {
+				i = len(in)
+			} else {
+				i += end + 6
+			}
+			out.WriteByte('?')
+		case c == '"':
+			i++
+			for i < len(in) && in[i] != '"' {
+				if in[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			out.WriteByte('?')
+		case isDigit(rune(c)) || (c == '-' && i+1 < len(in) && isDigit(rune(in[i+1]))):
+			i++
+			for i < len(in) && (isDigit(rune(in[i])) || in[i] == '.' || in[i] == 'e' || in[i] == 'E' ||
+				((in[i] == '-' || in[i] == '+') && (in[i-1] == 'e' || in[i-1] == 'E'))) {
+				i++
+			}
+			out.WriteByte('?')
+		case c == '$' || c == '_' || isLeadingLetter(rune(c)):
+			// variables, names and keywords
+			start := i
+			i++
+			for i < len(in) && (in[i] == '_' || isLeadingLetter(rune(in[i])) || isDigit(rune(in[i]))) {
+				i++
+			}
+			out.WriteString(in[start:i])
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimRight(out.String(), " ,")
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQLString(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`{ user(id: 4) { name } }`,
+			`{ user(id: ?) { name } }`,
+		},
+		{
+			`query GetUser($id: ID!) { user(id: $id) { name, friends(first: 10) { name } } }`,
+			`query GetUser($id: ID!) { user(id: $id) { name, friends(first: ?) { name } } }`,
+		},
+		{
+			`mutation { createUser(name: "bob \"the\" builder", age: -42.5e3, role: ADMIN, active: true) { id } }`,
+			`mutation { createUser(name: ?, age: ?, role: ADMIN, active: true) { id } }`,
+		},
+		{
+			"query {\n  # the current user\n  me {\n    bio(format: \"\"\"multi\nline\"\"\")\n  }\n}",
+			`query { me { bio(format: ?) } }`,
+		},
+		{
+			`{ search(filter: {tags: ["a", "b"], min: 3}) { id } }`,
+			`{ search(filter: {tags: [?, ?], min: ?}) { id } }`,
+		},
+		{
+			`{ field_1 }`,
+			`{ field_1 }`,
+		},
+	} {
+		assert.Equal(t, tt.out, NewObfuscator(nil).ObfuscateGraphQLString(tt.in))
+	}
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	cfg := &config.ObfuscationConfig{
+		GraphQL: config.GraphQLObfuscationConfig{
+			Enabled:    true,
+			KeepValues: []string{"first"},
+		},
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		span := pb.Span{
+			Type:     "graphql",
+			Resource: `query { user(id: 4) { name } }`,
+			Meta: map[string]string{
+				graphQLQueryTag:           `query($id: ID!, $first: Int) { user(id: $id) { friends(first: $first, after: "x") } }`,
+				graphQLVariablesTag:       `{"id": "1234", "first": 10}`,
+				"graphql.variables.id":    "1234",
+				"graphql.variables.first": "10",
+				"graphql.operation.name":  "GetUser",
+			},
+		}
+		NewObfuscator(cfg).Obfuscate(&span)
+		assert.Equal(t, `query { user(id: ?) { name } }`, span.Resource)
+		assert.Equal(t, map[string]string{
+			graphQLQueryTag:           `query($id: ID!, $first: Int) { user(id: $id) { friends(first: $first, after: ?) } }`,
+			graphQLVariablesTag:       `{"id":"?","first":10}`,
+			"graphql.variables.id":    "?",
+			"graphql.variables.first": "10",
+			"graphql.operation.name":  "GetUser",
+		}, span.Meta)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		span := pb.Span{
+			Type:     "graphql",
+			Resource: `query { user(id: 4) { name } }`,
+		}
+		NewObfuscator(nil).Obfuscate(&span)
+		assert.Equal(t, `query { user(id: 4) { name } }`, span.Resource)
+	})
+}
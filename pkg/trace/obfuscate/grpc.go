@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// grpcMetadataPrefix is the prefix of the tags holding gRPC request metadata.
+const grpcMetadataPrefix = "grpc.metadata."
+
+// defaultGRPCMetadata holds the metadata keys which are obfuscated when none are configured.
+var defaultGRPCMetadata = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"x-auth-token",
+}
+
+// newGRPCMetadataSet returns the set of (lowercased) metadata keys to obfuscate.
+func newGRPCMetadataSet(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		keys = defaultGRPCMetadata
+	}
+	set := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
+
+// obfuscateGRPC obfuscates the values of the "grpc.metadata.<key>" tags for which the
+// key is one of the configured metadata keys. gRPC metadata keys are case insensitive.
+func (o *Obfuscator) obfuscateGRPC(span *pb.Span) {
+	if o.grpcMetadata == nil || span.Meta == nil {
+		return
+	}
+	for k := range span.Meta {
+		if !strings.HasPrefix(k, grpcMetadataPrefix) {
+			continue
+		}
+		if o.grpcMetadata[strings.ToLower(strings.TrimPrefix(k, grpcMetadataPrefix))] {
+			span.Meta[k] = "?"
+		}
+	}
+}
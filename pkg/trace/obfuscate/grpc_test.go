@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGRPC(t *testing.T) {
+	meta := func() map[string]string {
+		return map[string]string{
+			"grpc.metadata.authorization": "Bearer abc",
+			"grpc.metadata.Cookie":        "session=1",
+			"grpc.metadata.x-tenant":      "acme",
+			"grpc.method.name":            "GetUser",
+		}
+	}
+	for name, tt := range map[string]struct {
+		cfg  config.GRPCObfuscationConfig
+		typ  string
+		want map[string]string
+	}{
+		"disabled": {
+			cfg:  config.GRPCObfuscationConfig{},
+			typ:  "rpc",
+			want: meta(),
+		},
+		"defaults": {
+			cfg: config.GRPCObfuscationConfig{Enabled: true},
+			typ: "rpc",
+			want: map[string]string{
+				"grpc.metadata.authorization": "?",
+				"grpc.metadata.Cookie":        "?",
+				"grpc.metadata.x-tenant":      "acme",
+				"grpc.method.name":            "GetUser",
+			},
+		},
+		"custom": {
+			cfg: config.GRPCObfuscationConfig{Enabled: true, Metadata: []string{"X-Tenant"}},
+			typ: "grpc",
+			want: map[string]string{
+				"grpc.metadata.authorization": "Bearer abc",
+				"grpc.metadata.Cookie":        "session=1",
+				"grpc.metadata.x-tenant":      "?",
+				"grpc.method.name":            "GetUser",
+			},
+		},
+		"other-type": {
+			cfg:  config.GRPCObfuscationConfig{Enabled: true},
+			typ:  "custom",
+			want: meta(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			span := pb.Span{Type: tt.typ, Meta: meta()}
+			NewObfuscator(&config.ObfuscationConfig{GRPC: tt.cfg}).Obfuscate(&span)
+			assert.Equal(t, tt.want, span.Meta)
+		})
+	}
+}
@@ -22,10 +22,13 @@ import (
 // concurrent use.
 type Obfuscator struct {
 	opts                 *config.ObfuscationConfig
-	es                   *jsonObfuscator // nil if disabled
-	mongo                *jsonObfuscator // nil if disabled
-	sqlExecPlan          *jsonObfuscator // nil if disabled
-	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
+	es                   *jsonObfuscator    // nil if disabled
+	mongo                *jsonObfuscator    // nil if disabled
+	sqlExecPlan          *jsonObfuscator    // nil if disabled
+	sqlExecPlanNormalize *jsonObfuscator    // nil if disabled
+	graphql              *graphQLObfuscator // nil if disabled
+	grpcMetadata         map[string]bool    // nil if disabled
+	awsKeepValues        map[string]bool    // nil if disabled
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// A non-zero value means 'yes'. Different SQL engines behave in different ways and the tokenizer needs
 	// to be generic.
@@ -77,6 +80,15 @@ func NewObfuscator(cfg *config.ObfuscationConfig) *Obfuscator {
 	if cfg.SQLExecPlanNormalize.Enabled {
 		o.sqlExecPlanNormalize = newJSONObfuscator(&cfg.SQLExecPlanNormalize, &o)
 	}
+	if cfg.GraphQL.Enabled {
+		o.graphql = newGraphQLObfuscator(&cfg.GraphQL, &o)
+	}
+	if cfg.GRPC.Enabled {
+		o.grpcMetadata = newGRPCMetadataSet(cfg.GRPC.Metadata)
+	}
+	if cfg.AWS.Enabled {
+		o.awsKeepValues = newAWSKeepSet(cfg.AWS.KeepValues)
+	}
 	return &o
 }
 
@@ -87,8 +99,14 @@ func (o *Obfuscator) Stop() { o.queryCache.Close() }
 // configuration.
 func (o *Obfuscator) Obfuscate(span *pb.Span) {
 	switch span.Type {
-	case "sql", "cassandra":
+	case "sql":
 		o.obfuscateSQL(span)
+	case "cassandra":
+		if o.opts.CQL.Enabled {
+			o.obfuscateCQL(span)
+		} else {
+			o.obfuscateSQL(span)
+		}
 	case "redis":
 		o.quantizeRedis(span)
 		if o.opts.Redis.Enabled {
@@ -100,6 +118,13 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 		}
 	case "web", "http":
 		o.obfuscateHTTP(span)
+		o.obfuscateAWS(span)
+	case "aws":
+		o.obfuscateAWS(span)
+	case "graphql":
+		o.obfuscateGraphQL(span)
+	case "rpc", "grpc":
+		o.obfuscateGRPC(span)
 	case "mongodb":
 		o.obfuscateJSON(span, "mongodb.query", o.mongo)
 	case "elasticsearch":
@@ -110,7 +135,13 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 // ObfuscateStatsGroup obfuscates the given stats bucket group.
 func (o *Obfuscator) ObfuscateStatsGroup(b *pb.ClientGroupedStats) {
 	switch b.Type {
-	case "sql", "cassandra":
+	case "cassandra":
+		if o.opts.CQL.Enabled {
+			b.Resource = o.ObfuscateCQLString(b.Resource)
+			break
+		}
+		fallthrough
+	case "sql":
 		oq, err := o.ObfuscateSQLString(b.Resource)
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
@@ -120,6 +151,10 @@ func (o *Obfuscator) ObfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if o.graphql != nil {
+			b.Resource = o.ObfuscateGraphQLString(b.Resource)
+		}
 	}
 }
 
@@ -44,6 +44,8 @@ type Info struct {
 	CPU CPUInfo
 	// Mem contains basic Mem info
 	Mem MemInfo
+	// Shedding contains the load shedding state
+	Shedding ShedInfo
 }
 
 // CurrentInfo is used to query CPU and Mem info, it keeps data from
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package watchdog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Stage is a load shedding stage. Stages are ordered by severity and each one also
+// implies the measures of the previous ones.
+type Stage int32
+
+const (
+	// StageNormal is the stage during which resource usage is within limits.
+	StageNormal Stage = iota
+	// StageReduceSampling lowers the sampling rates sent back to tracers, so that
+	// they send less traces.
+	StageReduceSampling
+	// StageRejectPayloads rejects all incoming payloads with 429 Too Many Requests
+	// and a Retry-After header.
+	StageRejectPayloads
+	// StageShrinkBuffers shrinks internal buffers and queues and returns as much memory
+	// as possible to the operating system.
+	StageShrinkBuffers
+)
+
+var stageNames = [...]string{
+	StageNormal:         "normal",
+	StageReduceSampling: "reduce_sampling",
+	StageRejectPayloads: "reject_payloads",
+	StageShrinkBuffers:  "shrink_buffers",
+}
+
+// stageThresholds specifies the pressure (i.e. the ratio between resource usage and
+// its limit) at which each stage is entered. Between 1 and the threshold of
+// StageRejectPayloads, the receiver's rate limiter drops a share of the payloads.
+var stageThresholds = [...]float64{
+	StageNormal:         0,
+	StageReduceSampling: 0.8,
+	StageRejectPayloads: 1.2,
+	StageShrinkBuffers:  1.4,
+}
+
+// stageHysteresis specifies the factor applied to the threshold of a stage to obtain
+// the pressure below which the stage is left. It avoids flapping between stages.
+const stageHysteresis = 0.9
+
+// String implements fmt.Stringer.
+func (s Stage) String() string {
+	if s < 0 || int(s) >= len(stageNames) {
+		return fmt.Sprintf("stage(%d)", int32(s))
+	}
+	return stageNames[s]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Stage) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Stage) UnmarshalText(text []byte) error {
+	for i, name := range stageNames {
+		if name == string(text) {
+			*s = Stage(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown load shedding stage %q", text)
+}
+
+// ShedInfo describes the load shedding state of the trace-agent.
+type ShedInfo struct {
+	// Stage specifies the current load shedding stage.
+	Stage Stage
+	// Pressure specifies the highest ratio between the usage of a resource (CPU or
+	// memory) and its configured limit, as of the last check.
+	Pressure float64
+	// Since specifies the time at which the current stage was entered.
+	Since time.Time
+}
+
+// currentStage holds the load shedding stage of the process, as last computed by a LoadShedder.
+var currentStage int32
+
+// CurrentStage returns the current load shedding stage of the process. Components owning
+// buffers or queues use it to adapt their behaviour.
+func CurrentStage() Stage { return Stage(atomic.LoadInt32(&currentStage)) }
+
+// LoadShedder computes the load shedding stage based on the pressure measured by the
+// watchdog. Stages are entered as soon as their threshold is reached, but they are left
+// one at a time, once the pressure has dropped sufficiently below their threshold.
+// It is not thread safe.
+type LoadShedder struct {
+	info ShedInfo
+}
+
+// Update updates the load shedding stage using the given pressure, measured at now. It
+// returns the new state and reports whether the stage changed.
+func (l *LoadShedder) Update(pressure float64, now time.Time) (info ShedInfo, changed bool) {
+	if l.info.Since.IsZero() {
+		l.info.Since = now
+	}
+	stage := l.info.Stage
+	for stage < StageShrinkBuffers && pressure >= stageThresholds[stage+1] {
+		stage++
+	}
+	if stage == l.info.Stage && stage > StageNormal && pressure < stageThresholds[stage]*stageHysteresis {
+		stage--
+	}
+	l.info.Pressure = pressure
+	if stage != l.info.Stage {
+		l.info.Stage = stage
+		l.info.Since = now
+		changed = true
+	}
+	atomic.StoreInt32(&currentStage, int32(stage))
+	return l.info, changed
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package watchdog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedder(t *testing.T) {
+	var l LoadShedder
+	defer l.Update(0, time.Now()) // reset the current stage
+	start := time.Now()
+
+	for i, tt := range []struct {
+		pressure float64
+		stage    Stage
+		changed  bool
+	}{
+		{0.5, StageNormal, false},
+		{0.85, StageReduceSampling, true},
+		{0.75, StageReduceSampling, false}, // hysteresis
+		{0.7, StageNormal, true},
+		{1.5, StageShrinkBuffers, true}, // stages can be skipped when escalating
+		{1.3, StageShrinkBuffers, false},
+		{1.2, StageRejectPayloads, true}, // but are left one at a time
+		{0.5, StageReduceSampling, true},
+		{0.5, StageNormal, true},
+	} {
+		now := start.Add(time.Duration(i) * time.Second)
+		info, changed := l.Update(tt.pressure, now)
+		assert.Equal(t, tt.stage, info.Stage, "step %d", i)
+		assert.Equal(t, tt.changed, changed, "step %d", i)
+		assert.Equal(t, tt.pressure, info.Pressure, "step %d", i)
+		assert.Equal(t, tt.stage, CurrentStage(), "step %d", i)
+		if changed {
+			assert.Equal(t, now, info.Since, "step %d", i)
+		}
+	}
+}
+
+func TestStageText(t *testing.T) {
+	out, err := json.Marshal(ShedInfo{Stage: StageRejectPayloads})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"Stage":"reject_payloads"`)
+
+	var info ShedInfo
+	assert.NoError(t, json.Unmarshal(out, &info))
+	assert.Equal(t, StageRejectPayloads, info.Stage)
+
+	var s Stage
+	assert.Error(t, s.UnmarshalText([]byte("panic")))
+	assert.Equal(t, "stage(42)", Stage(42).String())
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -206,26 +207,40 @@ outer:
 // Push pushes p onto the sender's queue, to be written to the destination.
 func (s *sender) Push(p *payload) {
 	for {
-		select {
-		case s.queue <- p:
-			// ok
-			atomic.AddInt32(&s.inflight, 1)
-			return
-		default:
-			// drop the oldest item in the queue to make room
+		if len(s.queue) < s.queueLimit() {
 			select {
-			case p := <-s.queue:
-				s.releasePayload(p, eventTypeDropped, &eventData{
-					bytes: p.body.Len(),
-					count: 1,
-				})
+			case s.queue <- p:
+				// ok
+				atomic.AddInt32(&s.inflight, 1)
+				return
 			default:
-				// the queue got drained; not very likely to happen, but
-				// we shouldn't risk a deadlock
-				continue
 			}
 		}
+		// drop the oldest item in the queue to make room
+		select {
+		case p := <-s.queue:
+			s.releasePayload(p, eventTypeDropped, &eventData{
+				bytes: p.body.Len(),
+				count: 1,
+			})
+		default:
+			// the queue got drained; not very likely to happen, but
+			// we shouldn't risk a deadlock
+			continue
+		}
+	}
+}
+
+// queueLimit returns the maximum number of payloads allowed in the queue. It is reduced
+// to a quarter of the queue's capacity while the trace-agent is shrinking its buffers.
+func (s *sender) queueLimit() int {
+	if watchdog.CurrentStage() < watchdog.StageShrinkBuffers {
+		return cap(s.queue)
+	}
+	if n := cap(s.queue) / 4; n > 0 {
+		return n
 	}
+	return 1
 }
 
 // sendPayload sends the payload p to the destination URL.
@@ -329,6 +329,7 @@ func (w *StatsWriter) recordEvent(t eventType, data *eventData) {
 	if data != nil {
 		metrics.Histogram("datadog.trace_agent.stats_writer.connection_fill", data.connectionFill, nil, 1)
 		metrics.Histogram("datadog.trace_agent.stats_writer.queue_fill", data.queueFill, nil, 1)
+		metrics.GaugeLocal("datadog.trace_agent.stats_writer.queue_fill_ratio", data.queueFill, nil)
 	}
 	switch t {
 	case eventTypeRetry:
@@ -242,6 +242,13 @@ func getDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error
 	return dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
 }
 
+// GetDDInformerFactory returns a new informer factory for the datadoghq custom
+// resources, for components that need them without the external metrics provider.
+// The caller is responsible for starting it.
+func GetDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+	return getDDInformerFactory()
+}
+
 func getInformerFactory() (informers.SharedInformerFactory, error) {
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
 	client, err := getKubeClient(0) // No timeout for the Informers, to allow long watch.
@@ -16,6 +16,9 @@ var tmplVarRegex = regexp.MustCompile(`%%.+?%%`)
 // TemplateVar is the info for a parsed template variable.
 type TemplateVar struct {
 	Raw, Name, Key []byte
+	// Default is the value to use when the variable can't be resolved, as set with
+	// the %%name_key|default%% syntax. It is nil when no default is set.
+	Default []byte
 }
 
 // ParseString returns parsed template variables found in the input string.
@@ -28,12 +31,25 @@ func Parse(b []byte) []TemplateVar {
 	var parsed []TemplateVar
 	vars := tmplVarRegex.FindAll(b, -1)
 	for _, v := range vars {
-		name, key := parseTemplateVar(v)
-		parsed = append(parsed, TemplateVar{v, name, key})
+		expr, def := splitDefault(v)
+		name, key := parseTemplateVar(expr)
+		parsed = append(parsed, TemplateVar{Raw: v, Name: name, Key: key, Default: def})
 	}
 	return parsed
 }
 
+// splitDefault splits a template variable on its first pipe, and returns the variable
+// expression and its default value, with surrounding whitespace trimmed. The default
+// value is nil if the variable has none.
+func splitDefault(v []byte) (expr, def []byte) {
+	inner := bytes.TrimSuffix(bytes.TrimPrefix(v, []byte("%%")), []byte("%%"))
+	i := bytes.IndexByte(inner, '|')
+	if i < 0 {
+		return v, nil
+	}
+	return inner[:i], append([]byte{}, bytes.TrimSpace(inner[i+1:])...)
+}
+
 // parseTemplateVar extracts the name of the var and the key (or index if it can be
 // cast to an int)
 func parseTemplateVar(v []byte) (name, key []byte) {
@@ -51,3 +51,28 @@ func TestParseTemplateVar(t *testing.T) {
 		})
 	}
 }
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		tmpl      string
+		name, key string
+		def       []byte
+	}{
+		{"%%env_FOO%%", "env", "FOO", nil},
+		{"%%env_FOO|bar%%", "env", "FOO", []byte("bar")},
+		{"%%env_FOO|%%", "env", "FOO", []byte{}},
+		{"%% port_http | 8080 %%", "port", "http", []byte("8080")},
+		{"%%label_app|my app|v2%%", "label", "app", []byte("my app|v2")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.tmpl, func(t *testing.T) {
+			vars := ParseString("url: http://" + testCase.tmpl + "/")
+			assert.Len(t, vars, 1)
+			assert.Equal(t, testCase.tmpl, string(vars[0].Raw))
+			assert.Equal(t, testCase.name, string(vars[0].Name))
+			assert.Equal(t, testCase.key, string(vars[0].Key))
+			assert.Equal(t, testCase.def, vars[0].Default)
+		})
+	}
+}